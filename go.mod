@@ -3,6 +3,7 @@ module golang.org/x/tools
 go 1.18 // tagx:compat 1.16
 
 require (
+	github.com/goplus/gogen v1.15.3-0.20240424153048-0d40138c65a5
 	github.com/goplus/gop v1.2.0-pre.1.0.20240506041011-133b8a33c31b
 	github.com/goplus/mod v0.13.10
 	github.com/yuin/goldmark v1.7.4
@@ -12,7 +13,4 @@ require (
 	golang.org/x/sys v0.20.0
 )
 
-require (
-	github.com/goplus/gogen v1.15.3-0.20240424153048-0d40138c65a5 // indirect
-	github.com/qiniu/x v1.13.10 // indirect
-)
+require github.com/qiniu/x v1.13.10
@@ -581,6 +581,7 @@ func (state *golistState) createDriverResponse(words ...string) (*driverResponse
 			IgnoredFiles:    absJoin(p.Dir, p.IgnoredGoFiles, p.IgnoredOtherFiles),
 			forTest:         p.ForTest,
 			depsErrors:      p.DepsErrors,
+			dir:             p.Dir, // goxls: package directory
 			Module:          p.Module,
 		}
 
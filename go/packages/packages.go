@@ -380,6 +380,9 @@ type Package struct {
 	// depsErrors is the DepsErrors field from the go list response, if any.
 	depsErrors []*packagesinternal.PackageError
 
+	// goxls: dir is the Dir field from the go list response, if any.
+	dir string
+
 	// module is the module information for the package if it exists.
 	Module *Module
 }
@@ -410,6 +413,9 @@ func init() {
 	packagesinternal.GetDepsErrors = func(p interface{}) []*packagesinternal.PackageError {
 		return p.(*Package).depsErrors
 	}
+	packagesinternal.GetDir = func(p interface{}) string { // goxls: package directory
+		return p.(*Package).dir
+	}
 	packagesinternal.GetGoCmdRunner = func(config interface{}) *gocommand.Runner {
 		return config.(*Config).gocmdRunner
 	}
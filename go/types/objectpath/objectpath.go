@@ -26,7 +26,6 @@ package objectpath
 import (
 	"fmt"
 	"go/types"
-	"sort"
 	"strconv"
 	"strings"
 	_ "unsafe"
@@ -261,6 +260,11 @@ func (enc *Encoder) For(obj types.Object) (Path, error) {
 			return "", fmt.Errorf("func is not a method: %v", obj)
 		}
 
+		// goxls: Go+ extended methods
+		if path, ok := gopMethod(obj); ok {
+			return path, nil
+		}
+
 		if path, ok := enc.concreteMethod(obj); ok {
 			// Fast path for concrete methods that avoids looping over scope.
 			return path, nil
@@ -654,6 +658,16 @@ func object(pkg *types.Package, p Path, skipMethodSorting bool) (types.Object, e
 			index = int(i)
 		case opObj:
 			// no operand
+		case opGopMethod: // goxls: Go+ extended methods are denoted by name
+			if t == nil {
+				return nil, fmt.Errorf("invalid path: code %q in object context", code)
+			}
+			m, rest, ok := gopMethodByName(t, suffix)
+			if !ok {
+				return nil, fmt.Errorf("cannot apply %q to %s (got %T, want named with Go+ method %q)", code, t, t, suffix)
+			}
+			obj, t, suffix = m, nil, rest
+			continue
 		default:
 			// The suffix must end with a type->object operation.
 			if suffix == "" {
@@ -802,9 +816,7 @@ func namedMethods(named *types.Named) []*types.Func {
 	for i := range methods {
 		methods[i] = named.Method(i)
 	}
-	sort.Slice(methods, func(i, j int) bool {
-		return methods[i].Id() < methods[j].Id()
-	})
+	sortMethods(methods) // goxls: Go+ extended methods come last
 	return methods
 }
 
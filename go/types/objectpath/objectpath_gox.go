@@ -0,0 +1,119 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package objectpath
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/gop/xtypes"
+)
+
+// Go+ adds extended methods, such as overloaded and static methods, to the
+// named types of a Go+ package once it is loaded, in an order that depends
+// on the loader: their indices are not stable, so they are denoted by name.
+//
+//	opGopMethod = 'N' // .Method(name) (Named; Go+ extended methods only)
+//
+// The name of the method extends to the next '.' or the end of the path.
+// The Go+ extended methods also come after the other methods in Id order,
+// so that the indices of the latter do not depend on the former.
+const opGopMethod = 'N'
+
+// gopOverloadArgs is the name of the parameter of the Go+ extended methods.
+const gopOverloadArgs = "__gop_overload_args__"
+
+// isGopMethod reports whether m is a Go+ extended method, whose signature
+// is func(__gop_overload_args__ interface{_(T)}) with T a Go+ extended type.
+// Export data, such as that of the analysis cache of gopls, drops T: the
+// method is then recognized by the name of its parameter, so that the
+// paths decoded from export data agree with the encoded ones.
+func isGopMethod(m *types.Func) bool {
+	sig, ok := m.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil || sig.Params().Len() != 1 {
+		return false
+	}
+	param := sig.Params().At(0)
+	iface, ok := param.Type().(*types.Interface)
+	if !ok || iface.NumExplicitMethods() != 1 {
+		return false
+	}
+	recv := iface.ExplicitMethod(0).Type().(*types.Signature).Recv()
+	if recv == nil {
+		return param.Name() == gopOverloadArgs
+	}
+	switch recv.Type().(type) {
+	case xtypes.OverloadType, xtypes.SubstType:
+		return true
+	}
+	return param.Name() == gopOverloadArgs
+}
+
+// gopMethod returns the path of meth, if it is a Go+ extended method of a
+// package-level named type.
+func gopMethod(meth *types.Func) (Path, bool) {
+	if !isGopMethod(meth) {
+		return "", false
+	}
+	recvT := meth.Type().(*types.Signature).Recv().Type()
+	if ptr, ok := recvT.(*types.Pointer); ok {
+		recvT = ptr.Elem()
+	}
+	named, ok := recvT.(*types.Named)
+	if !ok {
+		return "", false
+	}
+	tname := named.Obj()
+	if pkg := tname.Pkg(); pkg == nil || pkg.Scope().Lookup(tname.Name()) != tname {
+		return "", false
+	}
+	path := make([]byte, 0, len(tname.Name())+len(meth.Name())+2)
+	path = append(path, tname.Name()...)
+	path = append(path, opType, opGopMethod)
+	path = append(path, meth.Name()...)
+	return Path(path), true
+}
+
+// gopMethodByName decodes the operand of opGopMethod in suffix, returning
+// the method of t it denotes and the rest of suffix.
+func gopMethodByName(t types.Type, suffix string) (types.Object, string, bool) {
+	name := suffix
+	if i := strings.IndexByte(suffix, opType); i >= 0 {
+		name, suffix = suffix[:i], suffix[i:]
+	} else {
+		suffix = ""
+	}
+	if named, ok := t.(*types.Named); ok {
+		for i := 0; i < named.NumMethods(); i++ {
+			if m := named.Method(i); m.Name() == name && isGopMethod(m) {
+				return m, suffix, true
+			}
+		}
+	}
+	return nil, suffix, false
+}
+
+// sortMethods sorts methods in Id order, the Go+ extended methods coming
+// last. Whether a method is a Go+ extended method is computed once.
+func sortMethods(methods []*types.Func) {
+	type method struct {
+		fn  *types.Func
+		gop bool
+	}
+	ms := make([]method, len(methods))
+	for i, m := range methods {
+		ms[i] = method{m, isGopMethod(m)}
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		if ms[i].gop != ms[j].gop {
+			return ms[j].gop
+		}
+		return ms[i].fn.Id() < ms[j].fn.Id()
+	})
+	for i, m := range ms {
+		methods[i] = m.fn
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package objectpath_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/goplus/gogen"
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/internal/gcimporter"
+)
+
+// TestGopMethods checks the paths of Go+ overloaded methods, which are
+// added to a named type after its other methods, and that they do not
+// change the paths of the latter.
+func TestGopMethods(t *testing.T) {
+	const src = `
+package p
+
+type T struct{}
+
+func (T) Bar__0(x int)    {}
+func (T) Bar__1(s string) {}
+func (T) Z()              {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named := pkg.Scope().Lookup("T").Type().(*types.Named)
+	method := func(name string) *types.Func {
+		obj, _, _ := types.LookupFieldOrMethod(named, false, pkg, name)
+		return obj.(*types.Func)
+	}
+	z := method("Z")
+	zpath, err := objectpath.For(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bar := gogen.NewOverloadMethod(named, token.NoPos, pkg, "Bar", method("Bar__0"), method("Bar__1"))
+	path, err := objectpath.For(bar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := objectpath.Path("T.NBar"); path != want {
+		t.Errorf("For(%v) = %q, want %q", bar, path, want)
+	}
+	if obj, err := objectpath.Object(pkg, path); err != nil || obj != bar {
+		t.Errorf("Object(%q) = %v, %v, want %v", path, obj, err, bar)
+	}
+
+	if path, err := objectpath.For(z); err != nil || path != zpath {
+		t.Errorf("For(%v) = %q, %v, want %q", z, path, err, zpath)
+	}
+	if obj, err := objectpath.Object(pkg, zpath); err != nil || obj != z {
+		t.Errorf("Object(%q) = %v, %v, want %v", zpath, obj, err, z)
+	}
+
+	if _, err := objectpath.Object(pkg, "T.NBaz"); err == nil {
+		t.Errorf("Object(%q) succeeded, want error", "T.NBaz")
+	}
+}
+
+// TestGopMethodsExportData checks that the paths of Go+ overloaded methods
+// encoded for a package are decoded for the package read from its export
+// data, as by the analysis driver of gopls.
+func TestGopMethodsExportData(t *testing.T) {
+	const src = `
+package p
+
+type T struct{}
+
+func (T) Bar__0(x int)    {}
+func (T) Bar__1(s string) {}
+func (T) Z()              {}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	named := pkg.Scope().Lookup("T").Type().(*types.Named)
+	method := func(named *types.Named, name string) *types.Func {
+		obj, _, _ := types.LookupFieldOrMethod(named, false, named.Obj().Pkg(), name)
+		return obj.(*types.Func)
+	}
+	bar := gogen.NewOverloadMethod(named, token.NoPos, pkg, "Bar", method(named, "Bar__0"), method(named, "Bar__1"))
+	z := method(named, "Z")
+
+	data, err := gcimporter.IExportShallow(fset, pkg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var imported *types.Package
+	getPackages := func(items []gcimporter.GetPackagesItem) error {
+		for i, item := range items {
+			items[i].Pkg = types.NewPackage(item.Path, item.Name)
+			if item.Path == "p" {
+				imported = items[i].Pkg
+			}
+		}
+		return nil
+	}
+	if _, err := gcimporter.IImportShallow(token.NewFileSet(), getPackages, data, "p", nil); err != nil {
+		t.Fatal(err)
+	}
+	importedT := imported.Scope().Lookup("T").Type().(*types.Named)
+
+	for _, test := range []struct {
+		obj, want *types.Func
+	}{
+		{bar, method(importedT, "Bar")},
+		{z, method(importedT, "Z")},
+	} {
+		path, err := objectpath.For(test.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj, err := objectpath.Object(imported, path); err != nil || obj != test.want {
+			t.Errorf("Object(%q) = %v, %v, want %v", path, obj, err, test.want)
+		}
+		if got, err := objectpath.For(test.want); err != nil || got != path {
+			t.Errorf("For(%v) = %q, %v, want %q", test.want, got, err, path)
+		}
+	}
+}
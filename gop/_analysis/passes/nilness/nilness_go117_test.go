@@ -1,20 +0,0 @@
-// Copyright 2021 The Go Authors. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-//go:build go1.17
-// +build go1.17
-
-package nilness_test
-
-import (
-	"testing"
-
-	"golang.org/x/tools/gop/analysis/analysistest"
-	"golang.org/x/tools/gop/analysis/passes/nilness"
-)
-
-func TestNilnessGo117(t *testing.T) {
-	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, nilness.Analyzer, "b")
-}
@@ -5,6 +5,7 @@
 package analysis
 
 import (
+	"encoding/gob"
 	"flag"
 	"fmt"
 	"reflect"
@@ -115,6 +116,19 @@ func SetResult(gopRet map[*Analyzer]any, goRet map[*GoAnalyzer]any, a IAnalyzer,
 	goRet[a.(*GoAnalyzer)] = v
 }
 
+// RegisterFact registers the type of fact with encoding/gob, under a
+// name qualified by the path of its package: the facts of a Go+
+// analyzer often have the same type names as those of the Go analyzer
+// it requires (such as *findcall.foundFact), which gob.Register would
+// not tell apart.
+func RegisterFact(fact Fact) {
+	if t := reflect.TypeOf(fact); t.Kind() == reflect.Ptr && t.Elem().Name() != "" {
+		gob.RegisterName("*"+t.Elem().PkgPath()+"."+t.Elem().Name(), fact)
+		return
+	}
+	gob.Register(fact)
+}
+
 // An Analyzer describes an analysis function and its options.
 type Analyzer struct {
 	// The Name of the analyzer must be a valid Go identifier
@@ -209,6 +223,13 @@ type Pass struct {
 	// goxls: Go+
 	GopFiles     []*ast.File     // the abstract syntax tree of each file
 	GopTypesInfo *typesutil.Info // type information about the syntax trees
+
+	// GenGoFiles lists the names of the files of the Go code generated
+	// for the Go+ files (gop_autogen*.go), which are not among the Files.
+	// ReadFile returns the contents of such a file as the driver sees it,
+	// e.g. from the overlays of an editor rather than from the disk.
+	GenGoFiles []string
+	ReadFile   func(filename string) ([]byte, error)
 }
 
 // PackageFact is a package together with an associated fact.
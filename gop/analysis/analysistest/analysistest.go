@@ -20,7 +20,6 @@ import (
 	"text/scanner"
 
 	"github.com/goplus/gop/ast"
-	"github.com/goplus/gop/format"
 	"github.com/goplus/gop/token"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/internal/checker"
@@ -75,6 +74,9 @@ type Testing interface {
 // It uses golden files placed alongside the source code under analysis:
 // suggested fixes for code in example.go will be compared against example.go.golden.
 //
+// goxls: The same goes for Go+ files: suggested fixes for code in the classfile
+// main.spx will be compared against main.spx.golden, formatted as a classfile.
+//
 // Golden files can be formatted in one of two ways: as plain Go source code, or as txtar archives.
 // In the first case, all suggested fixes will be applied to the original source, which will then be compared against the golden file.
 // In the second case, suggested fixes will be grouped by their messages, and each set of fixes will be applied and tested separately.
@@ -195,7 +197,7 @@ func RunWithSuggestedFixes(t Testing, dir string, a analysis.IAnalyzer, patterns
 							// between files in the archive. normalize
 							// this to a single newline.
 							want := string(bytes.TrimRight(vf.Data, "\n")) + "\n"
-							formatted, err := format.Source(out, true)
+							formatted, err := formatSource(out, file.Name()) // goxls: Go+ files
 							if err != nil {
 								t.Errorf("%s: error formatting edited source: %v\n%s", file.Name(), err, out)
 								continue
@@ -226,7 +228,7 @@ func RunWithSuggestedFixes(t Testing, dir string, a analysis.IAnalyzer, patterns
 				}
 				want := string(ar.Comment)
 
-				formatted, err := format.Source(out, true)
+				formatted, err := formatSource(out, file.Name()) // goxls: Go+ files
 				if err != nil {
 					t.Errorf("%s: error formatting resulting source: %v\n%s", file.Name(), err, out)
 					continue
@@ -254,6 +256,11 @@ func RunWithSuggestedFixes(t Testing, dir string, a analysis.IAnalyzer, patterns
 // Go module in which to work. Otherwise, Run treats it as the root of a
 // GOPATH-style tree, with package contained in the src subdirectory.
 //
+// goxls: The gop.mod file of a Go module registers the Go+ classfiles, such as
+// .spx, .gmx or _test.gox files, of its packages. If dir is a txtar archive,
+// whose name ends with ".txtar", Run treats its files, golden files included,
+// as those of the directory, so that a multi-file fixture is a single file.
+//
 // An expectation of a Diagnostic is specified by a string literal
 // containing a regular expression that must match the diagnostic
 // message. For example:
@@ -288,6 +295,13 @@ func Run(t Testing, dir string, a analysis.IAnalyzer, patterns ...string) []*Res
 		testenv.NeedsGoPackages(t)
 	}
 
+	// goxls: Go+ files
+	dir, err := gopTestData(t, dir)
+	if err != nil {
+		t.Errorf("preparing %s: %v", dir, err)
+		return nil
+	}
+
 	pkgs, err := loadPackages(a, dir, patterns...)
 	if err != nil {
 		t.Errorf("loading %s: %v", patterns, err)
@@ -334,14 +348,18 @@ func loadPackages(a analysis.IAnalyzer, dir string, patterns ...string) ([]*pack
 
 	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
 		packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo |
-		packages.NeedDeps | packages.NeedModule | packages.NeedNongen
+		packages.NeedDeps | packages.NeedModule | packages.NeedNongen | packages.NeedGopDeps
 	cfg := &packages.Config{
 		Mode:  mode,
 		Dir:   dir,
 		Tests: true,
 		Env:   append(os.Environ(), env...),
 	}
-	pkgs, err := packages.Load(cfg, patterns...)
+	// goxls: the Go code of the Go+ packages is generated in the environment
+	// of cfg; that of the packages with errors, only if the analyzer runs on
+	// them, is a stub and they are type-checked from their Go+ files
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess, GenGoStub: analysis.RunDespiteErrors(a)}
+	pkgs, err := packages.LoadEx(gop, cfg, patterns...)
 	if err != nil {
 		return nil, err
 	}
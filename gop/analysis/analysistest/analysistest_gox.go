@@ -0,0 +1,152 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysistest
+
+import (
+	goformat "go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/txtar"
+)
+
+// gopTestData prepares the Go+ packages of the project tree dir for
+// loading: if dir contains Go+ files, it is copied to a temporary
+// directory, in which loadPackages generates the Go code of each Go+
+// package, so that dir itself is left untouched. It returns the
+// directory to load the packages from.
+//
+// If dir is a txtar archive, whose name ends with ".txtar", the project
+// tree is that of the files of the archive, which is extracted to the
+// temporary directory instead.
+func gopTestData(t Testing, dir string) (string, error) {
+	if dir == "" {
+		return dir, nil // the packages of the current environment
+	}
+	var ar *txtar.Archive
+	if strings.HasSuffix(dir, ".txtar") {
+		var err error
+		if ar, err = txtar.ParseFile(dir); err != nil {
+			return "", err
+		}
+	} else if gopDirs, err := gopPackageDirs(dir); err != nil || len(gopDirs) == 0 {
+		return dir, err
+	}
+
+	tmp, err := os.MkdirTemp("", "analysistest")
+	if err != nil {
+		return "", err
+	}
+	if t, ok := t.(interface{ Cleanup(func()) }); ok {
+		t.Cleanup(func() { os.RemoveAll(tmp) })
+	}
+	if ar != nil {
+		err = extractTxtar(tmp, ar)
+	} else {
+		err = copyTree(tmp, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+	return tmp, nil
+}
+
+// gopPackageDirs returns the directories of the project tree dir, relative
+// to it, that contain Go+ files. The classfiles are those registered by the
+// gop.mod of the module of each directory.
+func gopPackageDirs(dir string) ([]string, error) {
+	var gopDirs []string
+	mods := make(map[string]*gopmod.Module)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// the module of a directory is that of its parent, unless it
+			// is the root of a module (or of the tree)
+			mod, ok := mods[filepath.Dir(path)]
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil || !ok {
+				mod = loadMod(path)
+			}
+			mods[path] = mod
+			return nil
+		}
+		if isGopFile(mods[filepath.Dir(path)], d.Name()) {
+			rel, _ := filepath.Rel(dir, filepath.Dir(path))
+			if n := len(gopDirs); n == 0 || gopDirs[n-1] != rel {
+				gopDirs = append(gopDirs, rel)
+			}
+		}
+		return nil
+	})
+	return gopDirs, err
+}
+
+// copyTree copies the project tree dir to the directory tmp.
+func copyTree(tmp, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		target := filepath.Join(tmp, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0777)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, 0666)
+	})
+}
+
+// extractTxtar writes the files of the archive ar to the directory tmp.
+func extractTxtar(tmp string, ar *txtar.Archive) error {
+	for _, f := range ar.Files {
+		target := filepath.Join(tmp, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0777); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, f.Data, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatSource formats the source src of the file filename, as Go+ or Go
+// code by its extension.
+func formatSource(src []byte, filename string) ([]byte, error) {
+	switch goputil.ModFileKind(loadMod(filepath.Dir(filename)), filepath.Base(filename)) {
+	case goputil.FileGopNormal:
+		return format.Source(src, false, filename)
+	case goputil.FileGopClass:
+		return format.Source(src, true, filename)
+	}
+	return goformat.Source(src)
+}
+
+// loadMod loads the Go+ module of dir, with the classfiles registered by
+// its gop.mod, or returns the default module if it fails.
+func loadMod(dir string) *gopmod.Module {
+	mod, err := gop.LoadMod(dir)
+	if err != nil || mod == nil {
+		return gopmod.Default
+	}
+	return mod
+}
+
+// isGopFile reports whether name is the name of a Go+ source file of the
+// module mod.
+func isGopFile(mod *gopmod.Module, name string) bool {
+	return goputil.ModFileKind(mod, name) != goputil.FileUnknown
+}
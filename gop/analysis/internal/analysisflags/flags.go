@@ -8,7 +8,6 @@ package analysisflags
 
 import (
 	"crypto/sha256"
-	"encoding/gob"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -136,7 +135,7 @@ func Parse(analyzers []analysis.IAnalyzer, multi bool) []analysis.IAnalyzer {
 	for a := range everything {
 		if !kept[a] {
 			for _, f := range analysis.FactTypes(a) {
-				gob.Register(f)
+				analysis.RegisterFact(f)
 			}
 		}
 	}
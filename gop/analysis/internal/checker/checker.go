@@ -30,6 +30,7 @@ import (
 
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/internal/analysisflags"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/diff"
 	"golang.org/x/tools/internal/robustio"
@@ -183,9 +184,9 @@ type typeParseError struct {
 func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 	mode := packages.LoadSyntax
 	if allSyntax {
-		mode = packages.LoadAllSyntax
+		mode = packages.LoadAllSyntax | packages.NeedGopDeps // goxls: Go+ facts of dependencies
 	}
-	mode |= packages.NeedModule
+	mode |= packages.NeedModule | packages.NeedNongen // goxls: Pass.Files
 	conf := packages.Config{
 		Mode:  mode,
 		Tests: IncludeTests,
@@ -801,6 +802,8 @@ func (act *action) execOnce() {
 		ResultOf:     inputs,
 		GopFiles:     act.pkg.GopSyntax,
 		GopTypesInfo: act.pkg.GopTypesInfo,
+		GenGoFiles:   goputil.GenGoFiles(act.pkg.CompiledGoFiles),
+		ReadFile:     os.ReadFile,
 	}
 	pass.SetAnalyzer(act.a)
 	act.pass = pass
@@ -1003,7 +1006,9 @@ func (act *action) exportObjectFact(obj types.Object, fact analysis.Fact) {
 func (act *action) allObjectFacts() []analysis.ObjectFact {
 	facts := make([]analysis.ObjectFact, 0, len(act.objectFacts))
 	for k := range act.objectFacts {
-		facts = append(facts, analysis.ObjectFact{Object: k.obj, Fact: act.objectFacts[k]})
+		if act.hasFactType(k.typ) { // goxls: facts are shared with Go+ analyzers
+			facts = append(facts, analysis.ObjectFact{Object: k.obj, Fact: act.objectFacts[k]})
+		}
 	}
 	return facts
 }
@@ -1049,9 +1054,22 @@ func factType(fact analysis.Fact) reflect.Type {
 func (act *action) allPackageFacts() []analysis.PackageFact {
 	facts := make([]analysis.PackageFact, 0, len(act.packageFacts))
 	for k := range act.packageFacts {
-		facts = append(facts, analysis.PackageFact{Package: k.pkg, Fact: act.packageFacts[k]})
+		if act.hasFactType(k.typ) { // goxls: facts are shared with Go+ analyzers
+			facts = append(facts, analysis.PackageFact{Package: k.pkg, Fact: act.packageFacts[k]})
+		}
 	}
 	return facts
 }
 
+// hasFactType reports whether t is one of the fact types of the analyzer
+// of act.
+func (act *action) hasFactType(t reflect.Type) bool {
+	for _, f := range analysis.FactTypes(act.a) {
+		if reflect.TypeOf(f) == t {
+			return true
+		}
+	}
+	return false
+}
+
 func dbg(b byte) bool { return strings.IndexByte(Debug, b) >= 0 }
@@ -21,7 +21,11 @@ import (
 	"golang.org/x/tools/gop/analysis/unitchecker"
 )
 
-func Main(analyzers ...*analysis.Analyzer) {
+// Main is the main function of an analysis driver for the analyzers.
+// It analyzes the Go and Go+ packages named on the command line, or the
+// compilation unit of a .cfg file when invoked by 'go vet -vettool=...',
+// and exits with a status reflecting the outcome.
+func Main(analyzers ...analysis.IAnalyzer) {
 	progname := filepath.Base(os.Args[0])
 	log.SetFlags(0)
 	log.SetPrefix(progname + ": ") // e.g. "vet: "
@@ -36,7 +40,7 @@ func Main(analyzers ...*analysis.Analyzer) {
 
 	args := flag.Args()
 	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go programs.
+		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go/Go+ programs.
 
 Usage: %[1]s [-flag] [package]
 
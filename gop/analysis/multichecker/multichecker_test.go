@@ -11,12 +11,16 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"testing"
 
+	gofindcall "golang.org/x/tools/go/analysis/passes/findcall"
 	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/multichecker"
 	"golang.org/x/tools/gop/analysis/passes/findcall"
+	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/testenv"
 )
 
@@ -31,7 +35,7 @@ func main() {
 			return nil, nil
 		},
 	}
-	multichecker.Main(findcall.Analyzer, fail)
+	multichecker.Main(gofindcall.Analyzer, findcall.Analyzer, fail)
 }
 
 // TestExitCode ensures that analysis failures are reported correctly.
@@ -54,6 +58,31 @@ func TestExitCode(t *testing.T) {
 
 	testenv.NeedsTool(t, "go")
 
+	// goxls: Go+ - a Go+ package, whose Go code is generated
+	testdata, cleanup, err := analysistest.WriteFiles(map[string]string{
+		"a/a.gop": `package a
+
+func F() {
+	println("hello")
+}
+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	gopPkg := "a"
+	env := append(os.Environ(), "GOPATH="+testdata, "GO111MODULE=off")
+
+	// The Go code is generated in-process, so that Go+ need not be
+	// installed for the checkers to load the package.
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	if _, err := packages.LoadEx(gop, &packages.Config{Mode: packages.NeedName, Dir: testdata, Env: env}, gopPkg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(testdata, "src/a/gop_autogen.go")); err != nil {
+		t.Fatal(err)
+	}
+
 	for _, test := range []struct {
 		args []string
 		want int
@@ -65,16 +94,20 @@ func TestExitCode(t *testing.T) {
 		{[]string{"-findcall=0", "io"}, 0},                  // no checkers
 		{[]string{"-findcall.name=nosuchfunc", "io"}, 0},    // no diagnostics
 		{[]string{"-findcall.name=panic", "sort", "io"}, 1}, // 'fail' failed on 'sort'
+		{[]string{"-gopFindcall.name=println", gopPkg}, 3},  // finds Go+ diagnostics
+		{[]string{"-gopFindcall=0", "-gopFindcall.name=println", gopPkg}, 0},
+		{[]string{"-gopFindcall.name=nosuchfunc", gopPkg}, 0},
 
 		// -json: exits zero even in face of diagnostics or package errors.
 		{[]string{"-findcall.name=panic", "-json", "io"}, 0},
 		{[]string{"-findcall.name=panic", "-json", "io"}, 0},
 		{[]string{"-findcall.name=panic", "-json", "sort", "io"}, 0},
+		{[]string{"-gopFindcall.name=println", "-json", gopPkg}, 0},
 	} {
 		args := []string{"-test.run=TestExitCode", "--"}
 		args = append(args, test.args...)
 		cmd := exec.Command(os.Args[0], args...)
-		cmd.Env = append(os.Environ(), "MULTICHECKER_CHILD=1")
+		cmd.Env = append(env, "MULTICHECKER_CHILD=1")
 		out, err := cmd.CombinedOutput()
 		if len(out) > 0 {
 			t.Logf("%s: out=<<%s>>", test.args, out)
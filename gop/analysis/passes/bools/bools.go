@@ -7,10 +7,10 @@
 package bools
 
 import (
-	"go/ast"
-	"go/token"
-	"go/types"
-
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/go/analysis/passes/bools"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -20,10 +20,10 @@ import (
 const Doc = "check for common mistakes involving boolean operators"
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "bools",
+	Name:     "gopBools",
 	Doc:      Doc,
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/bools",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{bools.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -51,7 +51,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 			return
 		}
 
-		comm := op.commutativeSets(pass.TypesInfo, e, seen)
+		comm := op.commutativeSets(pass.GopTypesInfo, e, seen)
 		for _, exprs := range comm {
 			op.checkRedundant(pass, exprs)
 			op.checkSuspect(pass, exprs)
@@ -76,7 +76,7 @@ var (
 // For example, given 'a || b || f() || c || d' with the or op,
 // commutativeSets returns {{b, a}, {d, c}}.
 // commutativeSets adds any expanded BinaryExprs to seen.
-func (op boolOp) commutativeSets(info *types.Info, e *ast.BinaryExpr, seen map[*ast.BinaryExpr]bool) [][]ast.Expr {
+func (op boolOp) commutativeSets(info *typesutil.Info, e *ast.BinaryExpr, seen map[*ast.BinaryExpr]bool) [][]ast.Expr {
 	exprs := op.split(e, seen)
 
 	// Partition the slice of expressions into commutative sets.
@@ -140,9 +140,9 @@ func (op boolOp) checkSuspect(pass *analysis.Pass, exprs []ast.Expr) {
 		// code is written.
 		var x ast.Expr
 		switch {
-		case pass.TypesInfo.Types[bin.Y].Value != nil:
+		case pass.GopTypesInfo.Types[bin.Y].Value != nil:
 			x = bin.X
-		case pass.TypesInfo.Types[bin.X].Value != nil:
+		case pass.GopTypesInfo.Types[bin.X].Value != nil:
 			x = bin.Y
 		default:
 			continue
@@ -163,12 +163,12 @@ func (op boolOp) checkSuspect(pass *analysis.Pass, exprs []ast.Expr) {
 }
 
 // hasSideEffects reports whether evaluation of e has side effects.
-func hasSideEffects(info *types.Info, e ast.Expr) bool {
+func hasSideEffects(info *typesutil.Info, e ast.Expr) bool {
 	safe := true
 	ast.Inspect(e, func(node ast.Node) bool {
 		switch n := node.(type) {
 		case *ast.CallExpr:
-			typVal := info.Types[n.Fun]
+			typVal := info.Types[unparen(n.Fun)] // goxls: Go+ does not record the types of parenthesized expressions
 			switch {
 			case typVal.IsType():
 				// Type conversion, which is safe.
@@ -7,6 +7,7 @@ package bools_test
 import (
 	"testing"
 
+	gobools "golang.org/x/tools/go/analysis/passes/bools"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/bools"
 	"golang.org/x/tools/internal/typeparams"
@@ -15,8 +16,9 @@ import (
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	tests := []string{"a"}
+	analysistest.Run(t, testdata, bools.Analyzer, tests...)
 	if typeparams.Enabled {
-		tests = append(tests, "typeparams")
+		// The Go files are checked by the Go analyzer that bools.Analyzer requires.
+		analysistest.Run(t, testdata, gobools.Analyzer, "typeparams")
 	}
-	analysistest.Run(t, testdata, bools.Analyzer, tests...)
 }
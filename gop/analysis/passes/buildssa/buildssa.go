@@ -0,0 +1,222 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package buildssa defines an Analyzer that constructs the SSA
+// representation of an error-free Go+ package and returns the set of all
+// functions declared in its Go+ files. It does not report any diagnostics
+// itself but may be used as an input to other analyzers.
+//
+// The SSA form of a Go+ package is built from the Go code generated for
+// it (its gop_autogen*.go files), whose //line directives map the
+// positions of functions and instructions to the Go+ files.
+package buildssa
+
+import (
+	"fmt"
+	goast "go/ast"
+	goparser "go/parser"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/gop/analysis"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:       "gopBuildssa",
+	Doc:        "build SSA-form IR for later passes",
+	URL:        "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/buildssa",
+	Run:        run,
+	ResultType: reflect.TypeOf(new(SSA)),
+}
+
+// SSA provides SSA-form intermediate representation for all the
+// non-blank source functions of the Go+ files of the current package.
+//
+// The types of Pkg are those of the generated Go code: they are not the
+// objects of Pass.GopTypesInfo, nor those of Pass.TypesInfo.
+type SSA struct {
+	Pkg      *ssa.Package
+	SrcFuncs []*ssa.Function
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.GopFiles) == 0 {
+		return &SSA{}, nil
+	}
+
+	// The generated files are not part of the syntax of the pass: parse
+	// them, with their //line directives resolved to the Go+ files, and
+	// type-check them along with the other Go files of the package.
+	gopFiles := make(map[string]string) // base name -> file name
+	for _, f := range pass.GopFiles {
+		name := pass.Fset.File(f.Pos()).Name()
+		gopFiles[filepath.Base(name)] = name
+	}
+	genFiles, err := parseGenerated(pass, gopFiles)
+	if err != nil {
+		return nil, err
+	}
+	files := append(genFiles, pass.Files...)
+
+	imports := make(map[string]*types.Package)
+	var addImports func(pkgs []*types.Package)
+	addImports = func(pkgs []*types.Package) {
+		for _, p := range pkgs {
+			if imports[p.Path()] == nil {
+				imports[p.Path()] = p
+				addImports(p.Imports())
+			}
+		}
+	}
+	addImports(pass.Pkg.Imports())
+
+	info := &types.Info{
+		Types:      make(map[goast.Expr]types.TypeAndValue),
+		Defs:       make(map[*goast.Ident]types.Object),
+		Uses:       make(map[*goast.Ident]types.Object),
+		Implicits:  make(map[goast.Node]types.Object),
+		Instances:  make(map[*goast.Ident]types.Instance),
+		Scopes:     make(map[goast.Node]*types.Scope),
+		Selections: make(map[*goast.SelectorExpr]*types.Selection),
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if p := imports[path]; p != nil {
+				return p, nil
+			}
+			return nil, os.ErrNotExist
+		}),
+		Sizes: pass.TypesSizes,
+	}
+	pkg, err := conf.Check(pass.Pkg.Path(), pass.Fset, files, info)
+	if err != nil {
+		return nil, err
+	}
+
+	// Plundered from ssautil.BuildPackage.
+
+	// We must create a new Program for each Package because the
+	// analysis API provides no place to hang a Program shared by
+	// all Packages. Consequently, SSA Packages and Functions do not
+	// have a canonical representation across an analysis session of
+	// multiple packages. This is unlikely to be a problem in
+	// practice because the analysis API essentially forces all
+	// packages to be analysed independently, so any given call to
+	// Analysis.Run on a package will see only SSA objects belonging
+	// to a single Program.
+
+	// Some Analyzers may need GlobalDebug, in which case we'll have
+	// to set it globally, but let's wait till we need it.
+	mode := ssa.BuilderMode(0)
+
+	prog := ssa.NewProgram(pass.Fset, mode)
+
+	// Create SSA packages for all imports.
+	// Order is not significant.
+	for _, p := range imports {
+		prog.CreatePackage(p, nil, nil, true)
+	}
+
+	// Create and build the primary package.
+	ssapkg := prog.CreatePackage(pkg, files, info, false)
+	ssapkg.Build()
+
+	// Compute list of source functions of the Go+ files, including
+	// literals, in source order.
+	var funcs []*ssa.Function
+	for _, f := range genFiles {
+		for _, decl := range f.Decls {
+			if fdecl, ok := decl.(*goast.FuncDecl); ok {
+
+				// SSA will not build a Function
+				// for a FuncDecl named blank.
+				if fdecl.Name.Name == "_" {
+					continue
+				}
+
+				// Skip the functions generated for
+				// classfiles, such as main.
+				posn := pass.Fset.Position(fdecl.Name.Pos())
+				if gopFiles[filepath.Base(posn.Filename)] != posn.Filename {
+					continue
+				}
+
+				fn, ok := info.Defs[fdecl.Name].(*types.Func)
+				if !ok {
+					return nil, fmt.Errorf("no function declared by %s at %s", fdecl.Name.Name, posn)
+				}
+
+				f := ssapkg.Prog.FuncValue(fn)
+				if f == nil {
+					return nil, fmt.Errorf("no SSA function for %s at %s", fn, posn)
+				}
+
+				var addAnons func(f *ssa.Function)
+				addAnons = func(f *ssa.Function) {
+					funcs = append(funcs, f)
+					for _, anon := range f.AnonFuncs {
+						addAnons(anon)
+					}
+				}
+				addAnons(f)
+			}
+		}
+	}
+
+	return &SSA{Pkg: ssapkg, SrcFuncs: funcs}, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// lineDirective matches the //line directives of generated code.
+var lineDirective = regexp.MustCompile(`(?m)^//line (.+):(\d+:\d+)$`)
+
+// parseGenerated parses the files generated for the Go+ files of the
+// package: those of Pass.GenGoFiles whose //line directives refer to
+// them, as read by Pass.ReadFile, so that the Go code is that the driver
+// sees.
+//
+// gop writes the file names of the directives relative to the root of
+// the module rather than to the generated file, so they are replaced
+// by the names of the Go+ files, which share their base names.
+func parseGenerated(pass *analysis.Pass, gopFiles map[string]string) ([]*goast.File, error) {
+	if pass.ReadFile == nil {
+		return nil, nil // the driver does not provide the generated code
+	}
+	var files []*goast.File
+	for _, name := range pass.GenGoFiles {
+		src, err := pass.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		used := false
+		src = lineDirective.ReplaceAllFunc(src, func(line []byte) []byte {
+			m := lineDirective.FindSubmatch(line)
+			gopFile, ok := gopFiles[filepath.Base(string(m[1]))]
+			if !ok {
+				return line
+			}
+			used = true
+			return []byte("//line " + gopFile + ":" + string(m[2]))
+		})
+		if !used {
+			continue // generated for other Go+ files, e.g. tests
+		}
+		f, err := goparser.ParseFile(pass.Fset, name, src, goparser.ParseComments|goparser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if f.Name.Name != pass.Pkg.Name() {
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package buildssa_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/gop/analysis/analysistest"
+	"golang.org/x/tools/gop/analysis/passes/buildssa"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	result := analysistest.Run(t, testdata, buildssa.Analyzer, "a")[0]
+
+	ssainfo := result.Result.(*buildssa.SSA)
+	got := fmt.Sprint(ssainfo.SrcFuncs)
+	want := `[a.Fib (a.T).fib a.Evens a.Evens$1]`
+	if got != want {
+		t.Errorf("SSA.SrcFuncs = %s, want %s", got, want)
+		for _, f := range ssainfo.SrcFuncs {
+			f.WriteTo(os.Stderr)
+		}
+	}
+
+	// The functions are positioned at their Go+ declarations.
+	lines := map[string]int{"Fib": 3, "fib": 12, "Evens": 18, "Evens$1": 19}
+	for _, f := range ssainfo.SrcFuncs {
+		posn := result.Pass.Fset.Position(f.Pos())
+		if filepath.Base(posn.Filename) != "a.gop" || posn.Line != lines[f.Name()] {
+			t.Errorf("position of %s = %s, want a.gop:%d", f, posn, lines[f.Name()])
+		}
+	}
+}
+
+// TestReadFile checks that the generated Go code is that read by the
+// driver, which may differ from that on disk, as in an editor.
+func TestReadFile(t *testing.T) {
+	testdata := analysistest.TestData()
+	result := analysistest.Run(t, testdata, buildssa.Analyzer, "a")[0]
+
+	pass := *result.Pass
+	if len(pass.GenGoFiles) != 1 {
+		t.Fatalf("GenGoFiles = %v, want gop_autogen.go", pass.GenGoFiles)
+	}
+	src, err := pass.ReadFile(pass.GenGoFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = append(src, "//line a/a.gop:22:1\nfunc Odds() {}\n"...)
+	gone := filepath.Join(t.TempDir(), "gone", "gop_autogen.go")
+	pass.GenGoFiles = []string{gone}
+	pass.ReadFile = func(filename string) ([]byte, error) {
+		if filename != gone {
+			return nil, os.ErrNotExist
+		}
+		return src, nil
+	}
+	res, err := buildssa.Analyzer.Run(&pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := fmt.Sprint(res.(*buildssa.SSA).SrcFuncs)
+	want := `[a.Fib (a.T).fib a.Evens a.Evens$1 a.Odds]`
+	if got != want {
+		t.Errorf("SSA.SrcFuncs = %s, want %s", got, want)
+	}
+}
+
+func TestGenericDecls(t *testing.T) {
+	testdata := analysistest.TestData()
+	result := analysistest.Run(t, testdata, buildssa.Analyzer, "b")[0].Result
+
+	ssainfo := result.(*buildssa.SSA)
+	got := fmt.Sprint(ssainfo.SrcFuncs)
+	want := `[b.LoadInt b.LoadIntPointer]`
+	if got != want {
+		t.Errorf("SSA.SrcFuncs = %s, want %s", got, want)
+		for _, f := range ssainfo.SrcFuncs {
+			f.WriteTo(os.Stderr)
+		}
+	}
+}
+
+func TestImporting(t *testing.T) {
+	testdata := analysistest.TestData()
+	result := analysistest.Run(t, testdata, buildssa.Analyzer, "c")[0].Result
+
+	ssainfo := result.(*buildssa.SSA)
+	got := fmt.Sprint(ssainfo.SrcFuncs)
+	want := `[c.A c.B]`
+	if got != want {
+		t.Errorf("SSA.SrcFuncs = %s, want %s", got, want)
+		for _, f := range ssainfo.SrcFuncs {
+			f.WriteTo(os.Stderr)
+		}
+	}
+}
+
+// TestGopPositions checks that the functions of lambdas and of the
+// methods of classfiles, and their instructions, are positioned in the
+// Go+ files.
+func TestGopPositions(t *testing.T) {
+	testdata := analysistest.TestData()
+	result := analysistest.Run(t, testdata, buildssa.Analyzer, "d")[0]
+
+	ssainfo := result.Result.(*buildssa.SSA)
+	got := fmt.Sprint(ssainfo.SrcFuncs)
+	want := `[(*d.Rect).Area (*d.Rect).Scale (*d.Rect).Scale$1 d.Apply d.Double d.Double$1]`
+	if got != want {
+		t.Errorf("SSA.SrcFuncs = %s, want %s", got, want)
+	}
+
+	lines := map[string]string{
+		"Area":     "Rect.gox:7",
+		"Scale":    "Rect.gox:11",
+		"Scale$1":  "Rect.gox:12",
+		"Apply":    "d.gop:4",
+		"Double":   "d.gop:8",
+		"Double$1": "d.gop:9",
+	}
+	fset := result.Pass.Fset
+	for _, f := range ssainfo.SrcFuncs {
+		posn := fset.Position(f.Pos())
+		if got := fmt.Sprintf("%s:%d", filepath.Base(posn.Filename), posn.Line); got != lines[f.Name()] {
+			t.Errorf("position of %s = %s, want %s", f, got, lines[f.Name()])
+		}
+		file := filepath.Base(posn.Filename)
+		for _, b := range f.Blocks {
+			for _, instr := range b.Instrs {
+				if pos := instr.Pos(); pos.IsValid() {
+					if got := filepath.Base(fset.Position(pos).Filename); got != file {
+						t.Errorf("instruction %s of %s is in %s, want %s", instr, f, got, file)
+					}
+				}
+			}
+		}
+	}
+}
@@ -1,4 +1,5 @@
-// Package b contains declarations of generic functions.
+// Package b contains declarations of generic functions, in Go, as Go+
+// does not declare generic functions, and their uses in Go+.
 package b
 
 import "unsafe"
@@ -15,6 +16,6 @@ func Load[T any](x *Pointer[T]) *T {
 	return x.Load()
 }
 
-func LoadPointer(addr *unsafe.Pointer) (val unsafe.Pointer)
+func LoadPointer(addr *unsafe.Pointer) (val unsafe.Pointer) { return *addr }
 
 var G Pointer[int]
@@ -8,10 +8,11 @@ package composite
 
 import (
 	"fmt"
-	"go/ast"
 	"go/types"
 	"strings"
 
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/go/analysis/passes/composite"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/ast/inspector"
@@ -35,10 +36,10 @@ should be replaced by:
 `
 
 var Analyzer = &analysis.Analyzer{
-	Name:             "composites",
+	Name:             "gopComposites",
 	Doc:              Doc,
 	URL:              "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/composite",
-	Requires:         []*analysis.Analyzer{inspect.Analyzer},
+	Requires:         []analysis.IAnalyzer{composite.Analyzer, inspect.Analyzer},
 	RunDespiteErrors: true,
 	Run:              run,
 }
@@ -60,7 +61,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 	inspect.Preorder(nodeFilter, func(n ast.Node) {
 		cl := n.(*ast.CompositeLit)
 
-		typ := pass.TypesInfo.Types[cl].Type
+		typ := pass.GopTypesInfo.Types[cl].Type
 		if typ == nil {
 			// cannot determine composite literals' type, skip it
 			return
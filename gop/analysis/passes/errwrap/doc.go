@@ -0,0 +1,29 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package errwrap defines an Analyzer that checks the use of the Go+
+// error-handling expressions.
+//
+// # Analyzer gopErrwrap
+//
+// gopErrwrap: check the use of Go+ error-handling expressions
+//
+// Go+ offers three forms of handling the error result of a call:
+// expr! panics if the error is not nil, expr? returns it to the caller
+// and expr?:defaultValue evaluates to defaultValue in place of the
+// failed call. This checker reports:
+//
+//   - expr! in a library package, that is a package other than main and
+//     outside of tests, where panicking prevents the callers from
+//     handling the error. If the enclosing function returns an error,
+//     the suggested fix propagates it with expr? instead.
+//
+//   - expr? in a function whose last result is not an error, to which
+//     the error cannot be returned. The suggested fix panics with expr!
+//     instead.
+//
+//   - expr?:defaultValue where defaultValue is not assignable to the
+//     value of expr. The suggested fixes replace the default with expr!,
+//     or with expr? if the enclosing function returns an error.
+package errwrap
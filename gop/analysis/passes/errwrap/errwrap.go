@@ -0,0 +1,202 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errwrap
+
+import (
+	_ "embed"
+	"go/constant"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "gopErrwrap",
+	Doc:              analysisutil.MustExtractDoc(doc, "gopErrwrap"),
+	URL:              "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/errwrap",
+	Run:              run,
+	RunDespiteErrors: true, // misused expr? and expr?:defaultValue are compile errors
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	info := pass.GopTypesInfo
+	library := pass.Pkg.Name() != "main"
+
+	// The inspector does not index the nodes specific to Go+, such as
+	// ErrWrapExpr: walk the files, keeping track of the enclosing nodes.
+	var stack []ast.Node
+	check := func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		expr, ok := n.(*ast.ErrWrapExpr)
+		if !ok {
+			return true
+		}
+		results, ok := info.TypeOf(expr.X).(*types.Tuple)
+		if !ok || results.Len() == 0 || !types.Identical(results.At(results.Len()-1).Type(), errorType) {
+			return true // not a call returning an error
+		}
+		// returnsError is valid if the enclosing function is known.
+		returnsError, known := enclosingFuncReturnsError(info, stack)
+
+		x := analysisutil.Format(pass.Fset, expr.X)
+		switch {
+		case expr.Tok == token.NOT:
+			if !library || isTestFile(pass.Fset, expr.Pos()) {
+				return true
+			}
+			diag := analysis.Diagnostic{
+				Pos:     expr.Pos(),
+				End:     expr.End(),
+				Message: x + "! panics on error in library package " + pass.Pkg.Name(),
+			}
+			if known && returnsError {
+				diag.SuggestedFixes = []analysis.SuggestedFix{
+					toQuestion(x, expr.TokPos, expr.End()),
+				}
+			}
+			pass.Report(diag)
+
+		case expr.Default == nil:
+			if !known || returnsError {
+				return true
+			}
+			pass.Report(analysis.Diagnostic{
+				Pos:     expr.Pos(),
+				End:     expr.End(),
+				Message: x + "? used in function whose last result is not an error",
+				SuggestedFixes: []analysis.SuggestedFix{
+					toNot(x, expr.TokPos, expr.End()),
+				},
+			})
+
+		default:
+			if results.Len() != 2 {
+				return true // the compiler reports the missing values
+			}
+			want := results.At(0).Type()
+			got, ok := info.Types[expr.Default]
+			if !ok || got.Type == nil || assignable(got, want) {
+				return true
+			}
+			diag := analysis.Diagnostic{
+				Pos:     expr.Default.Pos(),
+				End:     expr.Default.End(),
+				Message: "default value " + analysisutil.Format(pass.Fset, expr.Default) + " of type " + got.Type.String() + " is not assignable to " + want.String(),
+				SuggestedFixes: []analysis.SuggestedFix{
+					toNot(x, expr.TokPos, expr.End()),
+				},
+			}
+			if known && returnsError {
+				diag.SuggestedFixes = append(diag.SuggestedFixes, toQuestion(x, expr.TokPos, expr.End()))
+			}
+			pass.Report(diag)
+		}
+		return true
+	}
+	for _, f := range pass.GopFiles {
+		ast.Inspect(f, check)
+	}
+	return nil, nil
+}
+
+// assignable reports whether the default value x is assignable to the
+// type t. Unlike types.AssignableTo, which only checks the kind of an
+// untyped constant, it checks that the constant is representable by t, as
+// the compiler does: ?:1.0 is a valid default for an int, but not ?:1.5.
+func assignable(x types.TypeAndValue, t types.Type) bool {
+	b, ok := x.Type.(*types.Basic)
+	if !ok || b.Info()&types.IsUntyped == 0 || x.Value == nil {
+		return types.AssignableTo(x.Type, t)
+	}
+	u, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		// an interface, to which the constant is assigned with its
+		// default type
+		return types.AssignableTo(types.Default(x.Type), t)
+	}
+	switch info := u.Info(); {
+	case info&types.IsInteger != 0:
+		return constant.ToInt(x.Value).Kind() == constant.Int
+	case info&types.IsFloat != 0:
+		return constant.ToFloat(x.Value).Kind() != constant.Unknown
+	case info&types.IsComplex != 0:
+		return constant.ToComplex(x.Value).Kind() != constant.Unknown
+	case info&types.IsString != 0:
+		return x.Value.Kind() == constant.String
+	case info&types.IsBoolean != 0:
+		return x.Value.Kind() == constant.Bool
+	}
+	return false
+}
+
+// toNot returns a fix replacing the error handling of x, from pos to
+// end, with a panic.
+func toNot(x string, pos, end token.Pos) analysis.SuggestedFix {
+	return analysis.SuggestedFix{
+		Message:   "Change to " + x + "!",
+		TextEdits: []analysis.TextEdit{{Pos: pos, End: end, NewText: []byte("!")}},
+	}
+}
+
+// toQuestion returns a fix replacing the error handling of x, from pos
+// to end, with the propagation of the error.
+func toQuestion(x string, pos, end token.Pos) analysis.SuggestedFix {
+	return analysis.SuggestedFix{
+		Message:   "Change to " + x + "?",
+		TextEdits: []analysis.TextEdit{{Pos: pos, End: end, NewText: []byte("?")}},
+	}
+}
+
+// enclosingFuncReturnsError reports whether the innermost function of
+// stack has an error as last result. The second result is false if the
+// function is unknown, such as for a lambda, whose type is not recorded.
+func enclosingFuncReturnsError(info *typesutil.Info, stack []ast.Node) (returnsError, known bool) {
+	for i := len(stack) - 1; i >= 0; i-- {
+		var ftype *ast.FuncType
+		switch n := stack[i].(type) {
+		case *ast.FuncDecl:
+			ftype = n.Type
+		case *ast.FuncLit:
+			ftype = n.Type
+		case *ast.LambdaExpr, *ast.LambdaExpr2:
+			return false, false
+		default:
+			continue
+		}
+		if ftype.Results == nil || len(ftype.Results.List) == 0 {
+			return false, true
+		}
+		last := ftype.Results.List[len(ftype.Results.List)-1]
+		t := info.TypeOf(last.Type)
+		if t == nil {
+			return false, false
+		}
+		return types.Identical(t, errorType), true
+	}
+	return false, false
+}
+
+// isTestFile reports whether pos is in a Go+ test file: a _test file or
+// a test classfile.
+func isTestFile(fset *token.FileSet, pos token.Pos) bool {
+	fname := filepath.Base(fset.File(pos).Name())
+	fext := filepath.Ext(fname)
+	return strings.HasSuffix(fname[:len(fname)-len(fext)], "_test") || strings.HasSuffix(fname, "test.gox")
+}
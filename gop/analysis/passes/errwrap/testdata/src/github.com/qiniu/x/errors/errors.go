@@ -0,0 +1,22 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package errors is a stub of the package that the Go code generated for
+// the Go+ error-handling expressions depends on.
+package errors
+
+type Frame struct {
+	Err  error
+	Func string
+	Args []interface{}
+	Code string
+	File string
+	Line int
+}
+
+func NewFrame(err error, code, file string, line int, fn string, args ...interface{}) *Frame {
+	return &Frame{Err: err, Func: fn, Args: args, Code: code, File: file, Line: line}
+}
+
+func (p *Frame) Error() string { return p.Err.Error() }
@@ -30,7 +30,7 @@ func HasSideEffects(info *typesutil.Info, e ast.Expr) bool {
 	ast.Inspect(e, func(node ast.Node) bool {
 		switch n := node.(type) {
 		case *ast.CallExpr:
-			typVal := info.Types[n.Fun]
+			typVal := info.Types[Unparen(n.Fun)] // goxls: Go+ does not record the types of parenthesized expressions
 			switch {
 			case typVal.IsType():
 				// Type conversion, which is safe.
@@ -10,10 +10,11 @@ import (
 	"go/token"
 	"go/types"
 
+	gonilness "golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/buildssa"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
-	"golang.org/x/tools/gop/ssa"
 	"golang.org/x/tools/internal/typeparams"
 )
 
@@ -21,11 +22,11 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "nilness",
+	Name:     "gopNilness",
 	Doc:      analysisutil.MustExtractDoc(doc, "nilness"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/nilness",
 	Run:      run,
-	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	Requires: []analysis.IAnalyzer{gonilness.Analyzer, buildssa.Analyzer},
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
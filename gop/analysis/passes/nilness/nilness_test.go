@@ -9,7 +9,6 @@ import (
 
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/nilness"
-	"golang.org/x/tools/internal/typeparams"
 )
 
 func Test(t *testing.T) {
@@ -17,18 +16,17 @@ func Test(t *testing.T) {
 	analysistest.Run(t, testdata, nilness.Analyzer, "a")
 }
 
+func TestSliceToArray(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, nilness.Analyzer, "b")
+}
+
 func TestInstantiated(t *testing.T) {
-	if !typeparams.Enabled {
-		t.Skip("TestInstantiated requires type parameters")
-	}
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, nilness.Analyzer, "c")
 }
 
 func TestTypeSet(t *testing.T) {
-	if !typeparams.Enabled {
-		t.Skip("TestTypeSet requires type parameters")
-	}
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, nilness.Analyzer, "d")
 }
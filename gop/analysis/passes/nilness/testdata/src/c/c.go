@@ -0,0 +1,17 @@
+// The generic functions of the package are declared in Go, as Go+ does
+// not declare generic functions: they are checked by the Go nilness
+// analyzer, not by this one.
+package c
+
+func instantiated[X any](x *X) int {
+	if x == nil {
+		print(*x)
+	}
+	return 1
+}
+
+var g int
+
+func init() {
+	g = instantiated[int](&g)
+}
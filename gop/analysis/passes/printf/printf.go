@@ -8,9 +8,7 @@ import (
 	"bytes"
 	_ "embed"
 	"fmt"
-	"go/ast"
 	"go/constant"
-	"go/token"
 	"go/types"
 	"reflect"
 	"regexp"
@@ -19,6 +17,10 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/go/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -35,10 +37,10 @@ func init() {
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:       "printf",
+	Name:       "gopPrintf",
 	Doc:        analysisutil.MustExtractDoc(doc, "printf"),
 	URL:        "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/printf",
-	Requires:   []*analysis.Analyzer{inspect.Analyzer},
+	Requires:   []analysis.IAnalyzer{printf.Analyzer, inspect.Analyzer},
 	Run:        run,
 	ResultType: reflect.TypeOf((*Result)(nil)),
 	FactTypes:  []analysis.Fact{new(isWrapper)},
@@ -138,7 +140,7 @@ type printfCaller struct {
 // A function may be a Printf or Print wrapper if its last argument is ...interface{}.
 // If the next-to-last argument is a string, then this may be a Printf wrapper.
 // Otherwise it may be a Print wrapper.
-func maybePrintfWrapper(info *types.Info, decl ast.Decl) *printfWrapper {
+func maybePrintfWrapper(info *typesutil.Info, decl ast.Decl) *printfWrapper {
 	// Look for functions with final argument type ...interface{}.
 	fdecl, ok := decl.(*ast.FuncDecl)
 	if !ok || fdecl.Body == nil {
@@ -185,9 +187,9 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 	// Gather potential wrappers and call graph between them.
 	byObj := make(map[*types.Func]*printfWrapper)
 	var wrappers []*printfWrapper
-	for _, file := range pass.Files {
+	for _, file := range pass.GopFiles {
 		for _, decl := range file.Decls {
-			w := maybePrintfWrapper(pass.TypesInfo, decl)
+			w := maybePrintfWrapper(pass.GopTypesInfo, decl)
 			if w == nil {
 				continue
 			}
@@ -207,8 +209,8 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 			// TODO: Relax these checks; issue 26555.
 			if assign, ok := n.(*ast.AssignStmt); ok {
 				for _, lhs := range assign.Lhs {
-					if match(pass.TypesInfo, lhs, w.format) ||
-						match(pass.TypesInfo, lhs, w.args) {
+					if match(pass.GopTypesInfo, lhs, w.format) ||
+						match(pass.GopTypesInfo, lhs, w.args) {
 						// Modifies the format
 						// string or args in
 						// some way, so not a
@@ -219,8 +221,8 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 				}
 			}
 			if un, ok := n.(*ast.UnaryExpr); ok && un.Op == token.AND {
-				if match(pass.TypesInfo, un.X, w.format) ||
-					match(pass.TypesInfo, un.X, w.args) {
+				if match(pass.GopTypesInfo, un.X, w.format) ||
+					match(pass.GopTypesInfo, un.X, w.args) {
 					// Taking the address of the
 					// format string or args,
 					// so not a simple wrapper.
@@ -230,7 +232,7 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 			}
 
 			call, ok := n.(*ast.CallExpr)
-			if !ok || len(call.Args) == 0 || !match(pass.TypesInfo, call.Args[len(call.Args)-1], w.args) {
+			if !ok || len(call.Args) == 0 || !match(pass.GopTypesInfo, call.Args[len(call.Args)-1], w.args) {
 				return true
 			}
 
@@ -254,7 +256,7 @@ func findPrintfLike(pass *analysis.Pass, res *Result) (interface{}, error) {
 	return nil, nil
 }
 
-func match(info *types.Info, arg ast.Expr, param *types.Var) bool {
+func match(info *typesutil.Info, arg ast.Expr, param *types.Var) bool {
 	id, ok := arg.(*ast.Ident)
 	return ok && info.ObjectOf(id) == param
 }
@@ -263,13 +265,13 @@ func match(info *types.Info, arg ast.Expr, param *types.Var) bool {
 // It diagnoses writing fmt.Printf(format, args) instead of fmt.Printf(format, args...).
 func checkPrintfFwd(pass *analysis.Pass, w *printfWrapper, call *ast.CallExpr, kind Kind, res *Result) {
 	matched := kind == KindPrint ||
-		kind != KindNone && len(call.Args) >= 2 && match(pass.TypesInfo, call.Args[len(call.Args)-2], w.format)
+		kind != KindNone && len(call.Args) >= 2 && match(pass.GopTypesInfo, call.Args[len(call.Args)-2], w.format)
 	if !matched {
 		return
 	}
 
 	if !call.Ellipsis.IsValid() {
-		typ, ok := pass.TypesInfo.Types[call.Fun].Type.(*types.Signature)
+		typ, ok := pass.GopTypesInfo.Types[call.Fun].Type.(*types.Signature)
 		if !ok {
 			return
 		}
@@ -382,7 +384,7 @@ var isPrint = stringSet{
 //
 // If it cannot find any format string parameter, it returns ("", -1).
 func formatString(pass *analysis.Pass, call *ast.CallExpr) (format string, idx int) {
-	typ := pass.TypesInfo.Types[call.Fun].Type
+	typ := pass.GopTypesInfo.Types[call.Fun].Type
 	if typ != nil {
 		if sig, ok := typ.(*types.Signature); ok {
 			if !sig.Variadic() {
@@ -410,7 +412,7 @@ func formatString(pass *analysis.Pass, call *ast.CallExpr) (format string, idx i
 		if s, ok := stringConstantArg(pass, call, idx); ok {
 			return s, idx
 		}
-		if pass.TypesInfo.Types[call.Args[idx]].Type == types.Typ[types.String] {
+		if pass.GopTypesInfo.Types[call.Args[idx]].Type == types.Typ[types.String] {
 			// Skip checking a call with a non-constant format
 			// string argument, since its contents are unavailable
 			// for validation.
@@ -436,7 +438,7 @@ func stringConstantArg(pass *analysis.Pass, call *ast.CallExpr, idx int) (string
 // ("", false) is returned if expression isn't a string
 // constant.
 func stringConstantExpr(pass *analysis.Pass, expr ast.Expr) (string, bool) {
-	lit := pass.TypesInfo.Types[expr].Value
+	lit := pass.GopTypesInfo.Types[expr].Value
 	if lit != nil && lit.Kind() == constant.String {
 		return constant.StringVal(lit), true
 	}
@@ -462,7 +464,7 @@ func checkCall(pass *analysis.Pass) {
 }
 
 func printfNameAndKind(pass *analysis.Pass, call *ast.CallExpr) (fn *types.Func, kind Kind) {
-	fn, _ = typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	fn, _ = typeutil.Callee(pass.GopTypesInfo, call).(*types.Func)
 	if fn == nil {
 		return nil, 0
 	}
@@ -820,7 +822,7 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 	// Skip check for the %w verb, which requires an error.
 	formatter := false
 	if v.typ != argError && state.argNum < len(call.Args) {
-		if tv, ok := pass.TypesInfo.Types[call.Args[state.argNum]]; ok {
+		if tv, ok := pass.GopTypesInfo.Types[call.Args[state.argNum]]; ok {
 			formatter = isFormatter(tv.Type)
 		}
 	}
@@ -879,7 +881,7 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 	}
 	if reason, ok := matchArgType(pass, v.typ, arg); !ok {
 		typeString := ""
-		if typ := pass.TypesInfo.Types[arg].Type; typ != nil {
+		if typ := argType(pass, arg); typ != nil { // goxls: default type of constants
 			typeString = typ.String()
 		}
 		details := ""
@@ -905,7 +907,7 @@ func okPrintfArg(pass *analysis.Pass, call *ast.CallExpr, state *formatState) (o
 //	func (t  T) Error() string { printf("%s",  t) }
 //	func (t  T) String() string { printf("%s", &t) }
 func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
-	typ := pass.TypesInfo.Types[e].Type
+	typ := pass.GopTypesInfo.Types[e].Type
 
 	// It's unlikely to be a recursive stringer if it has a Format method.
 	if isFormatter(typ) {
@@ -923,7 +925,14 @@ func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
 
 	// inScope returns true if e is in the scope of f.
 	inScope := func(e ast.Expr, f *types.Func) bool {
-		return f.Scope() != nil && f.Scope().Contains(e.Pos())
+		if f.Scope() == nil { // goxls: Go+ functions have no scope: is e the receiver of f?
+			if u, ok := e.(*ast.UnaryExpr); ok && u.Op == token.AND {
+				e = u.X
+			}
+			id, ok := e.(*ast.Ident)
+			return ok && pass.GopTypesInfo.Uses[id] == f.Type().(*types.Signature).Recv()
+		}
+		return f.Scope().Contains(e.Pos())
 	}
 
 	// Is the expression e within the body of that String or Error method?
@@ -946,7 +955,7 @@ func recursiveStringer(pass *analysis.Pass, e ast.Expr) (string, bool) {
 		e = u.X // strip off & from &r
 	}
 	if id, ok := e.(*ast.Ident); ok {
-		if pass.TypesInfo.Uses[id] == sig.Recv() {
+		if pass.GopTypesInfo.Uses[id] == sig.Recv() {
 			return method.FullName(), true
 		}
 	}
@@ -963,7 +972,7 @@ func isStringer(sig *types.Signature) bool {
 // isFunctionValue reports whether the expression is a function as opposed to a function call.
 // It is almost always a mistake to print a function value.
 func isFunctionValue(pass *analysis.Pass, e ast.Expr) bool {
-	if typ := pass.TypesInfo.Types[e].Type; typ != nil {
+	if typ := pass.GopTypesInfo.Types[e].Type; typ != nil {
 		_, ok := typ.(*types.Signature)
 		return ok
 	}
@@ -1010,7 +1019,7 @@ const (
 // checkPrint checks a call to an unformatted print routine such as Println.
 func checkPrint(pass *analysis.Pass, call *ast.CallExpr, fn *types.Func) {
 	firstArg := 0
-	typ := pass.TypesInfo.Types[call.Fun].Type
+	typ := pass.GopTypesInfo.Types[call.Fun].Type
 	if typ == nil {
 		// Skip checking functions with unknown type.
 		return
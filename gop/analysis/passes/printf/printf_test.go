@@ -7,6 +7,7 @@ package printf_test
 import (
 	"testing"
 
+	goprintf "golang.org/x/tools/go/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/internal/typeparams"
@@ -15,10 +16,12 @@ import (
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	printf.Analyzer.Flags.Set("funcs", "Warn,Warnf")
+	goprintf.Analyzer.Flags.Set("funcs", "Warn,Warnf")
 
 	tests := []string{"a", "b", "nofmt"}
+	analysistest.Run(t, testdata, printf.Analyzer, tests...)
 	if typeparams.Enabled {
-		tests = append(tests, "typeparams")
+		// The Go files are checked by the Go analyzer that printf.Analyzer requires.
+		analysistest.Run(t, testdata, goprintf.Analyzer, "typeparams")
 	}
-	analysistest.Run(t, testdata, printf.Analyzer, tests...)
 }
@@ -8,13 +8,14 @@ package shift
 // Used for skipping shift checks on unreachable arch-specific code.
 
 import (
-	"go/ast"
 	"go/constant"
-	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/x/typesutil"
 )
 
 // updateDead puts unreachable "if" and "case" nodes into dead.
-func updateDead(info *types.Info, dead map[ast.Node]bool, node ast.Node) {
+func updateDead(info *typesutil.Info, dead map[ast.Node]bool, node ast.Node) {
 	if dead[node] {
 		// The node is already marked as dead.
 		return
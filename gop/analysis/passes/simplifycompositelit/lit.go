@@ -0,0 +1,292 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simplifycompositelit
+
+import (
+	"bytes"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/printer"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/analysis"
+)
+
+// checkBlock reports the slices and maps of block that are created empty
+// and filled by the statements that immediately follow, as they may be
+// created by a Go+ list or map literal instead.
+func checkBlock(pass *analysis.Pass, block *ast.BlockStmt) {
+	info := pass.GopTypesInfo
+	for i := 0; i < len(block.List); i++ {
+		obj, typ := emptyVar(info, block.List[i])
+		if obj == nil {
+			continue
+		}
+		var elts []string
+		var kind string
+		j := i + 1
+		switch typ := typ.(type) {
+		case *types.Slice:
+			kind = "list"
+			for ; j < len(block.List); j++ {
+				args := appended(info, block.List[j], obj, typ.Elem())
+				if args == nil {
+					break
+				}
+				for _, arg := range args {
+					elts = append(elts, format(pass.Fset, arg))
+				}
+			}
+		case *types.Map:
+			kind = "map"
+			// A map literal may not repeat a constant key.
+			keys := make(map[string]bool)
+			for ; j < len(block.List); j++ {
+				key, val := assigned(info, block.List[j], obj, typ)
+				if key == nil {
+					break
+				}
+				if tv := info.Types[key]; tv.Value != nil {
+					if keys[tv.Value.ExactString()] {
+						break
+					}
+					keys[tv.Value.ExactString()] = true
+				}
+				elts = append(elts, format(pass.Fset, key)+": "+format(pass.Fset, val))
+			}
+		}
+		if len(elts) == 0 {
+			continue
+		}
+		// The trailing comment of the declaration is kept, the statements
+		// filling the variable are deleted up to the end of the last one.
+		first, last := block.List[i], block.List[j-1]
+		from := lineEnd(pass.Fset, first.End())
+		if from > last.End() {
+			from = first.End()
+		}
+		if hasComments(pass, from, last.End()) {
+			continue // the fix would drop them
+		}
+		lit := "[" + strings.Join(elts, ", ") + "]"
+		if kind == "map" {
+			lit = "{" + strings.Join(elts, ", ") + "}"
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     first.Pos(),
+			End:     last.End(),
+			Message: obj.Name() + " can be created by a " + kind + " literal",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Replace with " + obj.Name() + " := " + lit,
+				TextEdits: []analysis.TextEdit{{
+					Pos:     first.Pos(),
+					End:     first.End(),
+					NewText: []byte(obj.Name() + " := " + lit),
+				}, {
+					Pos: from,
+					End: last.End(),
+				}},
+			}},
+		})
+		i = j - 1
+	}
+}
+
+// emptyVar returns the variable declared by stmt and its type, if stmt
+// declares an empty slice or map with one of the forms:
+//
+//	s := make([]T, 0)
+//	s := make([]T, 0, n)
+//	s := []T{}
+//	var s []T
+//	m := make(map[K]V)
+//	m := map[K]V{}
+func emptyVar(info *typesutil.Info, stmt ast.Stmt) (types.Object, types.Type) {
+	var name *ast.Ident
+	var value ast.Expr
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if stmt.Tok != token.DEFINE || len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 {
+			return nil, nil
+		}
+		name, _ = stmt.Lhs[0].(*ast.Ident)
+		value = stmt.Rhs[0]
+	case *ast.DeclStmt:
+		decl, ok := stmt.Decl.(*ast.GenDecl)
+		if !ok || decl.Tok != token.VAR || len(decl.Specs) != 1 {
+			return nil, nil
+		}
+		spec := decl.Specs[0].(*ast.ValueSpec)
+		if len(spec.Names) != 1 || len(spec.Values) != 0 {
+			return nil, nil
+		}
+		name = spec.Names[0]
+	}
+	if name == nil || name.Name == "_" {
+		return nil, nil
+	}
+	obj, ok := info.Defs[name].(*types.Var)
+	if !ok {
+		return nil, nil
+	}
+	// The type of a list or map literal is never a named type.
+	typ := obj.Type()
+	switch value := value.(type) {
+	case nil:
+		if _, ok := typ.(*types.Slice); !ok {
+			return nil, nil
+		}
+	case *ast.CompositeLit:
+		if len(value.Elts) != 0 || value.Type == nil {
+			return nil, nil
+		}
+	case *ast.CallExpr:
+		if !isBuiltin(info, value.Fun, "make") || value.Ellipsis.IsValid() {
+			return nil, nil
+		}
+		switch typ.(type) {
+		case *types.Slice:
+			if len(value.Args) < 2 || !isZero(info, value.Args[1]) {
+				return nil, nil
+			}
+		case *types.Map:
+			if len(value.Args) != 1 {
+				return nil, nil
+			}
+		}
+	default:
+		return nil, nil
+	}
+	switch typ.(type) {
+	case *types.Slice, *types.Map:
+		return obj, typ
+	}
+	return nil, nil
+}
+
+// appended returns the values that stmt appends to the slice s, if stmt
+// has the form
+//
+//	s = append(s, x, y, ...)
+//
+// and the values, which do not refer to s, are of type elem.
+func appended(info *typesutil.Info, stmt ast.Stmt, s types.Object, elem types.Type) []ast.Expr {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil
+	}
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || !isVar(info, assign.Lhs[0], s) || !isBuiltin(info, call.Fun, "append") ||
+		call.Ellipsis.IsValid() || len(call.Args) < 2 || !isVar(info, call.Args[0], s) {
+		return nil
+	}
+	args := call.Args[1:]
+	for _, arg := range args {
+		if !hasType(info, arg, elem) || refersTo(info, arg, s) {
+			return nil
+		}
+	}
+	return args
+}
+
+// assigned returns the key and the value that stmt assigns to the map m,
+// if stmt has the form
+//
+//	m[key] = value
+//
+// and the key and the value, which do not refer to m, are of the types
+// of the map.
+func assigned(info *typesutil.Info, stmt ast.Stmt, m types.Object, typ *types.Map) (key, value ast.Expr) {
+	assign, ok := stmt.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return nil, nil
+	}
+	index, ok := assign.Lhs[0].(*ast.IndexExpr)
+	if !ok || !isVar(info, index.X, m) {
+		return nil, nil
+	}
+	key, value = index.Index, assign.Rhs[0]
+	if !hasType(info, key, typ.Key()) || !hasType(info, value, typ.Elem()) ||
+		refersTo(info, key, m) || refersTo(info, value, m) {
+		return nil, nil
+	}
+	return key, value
+}
+
+// hasType reports whether the type of x, or its default type if x is
+// untyped, is identical to typ: that is the type of x in a literal.
+func hasType(info *typesutil.Info, x ast.Expr, typ types.Type) bool {
+	t := info.TypeOf(x)
+	return t != nil && types.Identical(types.Default(t), typ)
+}
+
+// refersTo reports whether x refers to obj.
+func refersTo(info *typesutil.Info, x ast.Expr, obj types.Object) bool {
+	found := false
+	ast.Inspect(x, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.ObjectOf(id) == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isVar(info *typesutil.Info, x ast.Expr, obj types.Object) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && info.ObjectOf(id) == obj
+}
+
+func isBuiltin(info *typesutil.Info, fun ast.Expr, name string) bool {
+	id, ok := fun.(*ast.Ident)
+	if !ok || id.Name != name {
+		return false
+	}
+	// Go+ records some builtins, such as append, as template functions of
+	// a package without path.
+	obj := info.ObjectOf(id)
+	if _, ok := obj.(*types.Builtin); ok {
+		return true
+	}
+	return obj != nil && obj.Pkg() != nil && obj.Pkg().Path() == ""
+}
+
+func isZero(info *typesutil.Info, x ast.Expr) bool {
+	tv, ok := info.Types[x]
+	return ok && tv.Value != nil && constant.Sign(tv.Value) == 0
+}
+
+// lineEnd returns the position of the end of the line of pos.
+func lineEnd(fset *token.FileSet, pos token.Pos) token.Pos {
+	f := fset.File(pos)
+	line := f.Line(pos)
+	if line == f.LineCount() {
+		return token.Pos(f.Base() + f.Size())
+	}
+	return f.LineStart(line+1) - 1
+}
+
+// hasComments reports whether there are comments between pos and end.
+func hasComments(pass *analysis.Pass, pos, end token.Pos) bool {
+	for _, f := range pass.GopFiles {
+		if f.Pos() <= pos && pos < f.End() {
+			for _, cg := range f.Comments {
+				if pos < cg.End() && cg.Pos() < end {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func format(fset *token.FileSet, x ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, x)
+	return b.String()
+}
@@ -0,0 +1,17 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simplifycompositelit_test
+
+import (
+	"testing"
+
+	"golang.org/x/tools/gop/analysis/analysistest"
+	"golang.org/x/tools/gop/analysis/passes/simplifycompositelit"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, simplifycompositelit.Analyzer, "a")
+}
@@ -0,0 +1,14 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The simplifylambda command applies the golang.org/x/tools/gop/analysis/passes/simplifylambda
+// analysis to the specified packages of Go/Go+ source code.
+package main
+
+import (
+	"golang.org/x/tools/gop/analysis/passes/simplifylambda"
+	"golang.org/x/tools/gop/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(simplifylambda.Analyzer) }
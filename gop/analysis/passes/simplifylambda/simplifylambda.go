@@ -0,0 +1,173 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simplifylambda defines an Analyzer that simplifies the function
+// literals passed as arguments in Go+ files to lambdas.
+package simplifylambda
+
+import (
+	"go/types"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+)
+
+const Doc = `check for function literals that can be lambdas
+
+A function literal passed as an argument of a function call:
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		...
+	})
+will be simplified to a Go+ lambda:
+	sort.Slice(s, (i, j) => s[i] < s[j])
+	http.HandleFunc("/", (w, r) => {
+		...
+	})
+as the types of the parameters and results of a lambda are those of the
+parameter of the function.
+
+Function literals with unnamed parameters or named results, passed to a
+variadic or overloaded function, or whose type is not that of the
+parameter are left as is.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "gopSimplifylambda",
+	Doc:      Doc,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifylambda",
+	Requires: []analysis.IAnalyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	info := pass.GopTypesInfo
+	nodeFilter := []ast.Node{(*ast.CallExpr)(nil)}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			if info.Overloads[fun] != nil {
+				return
+			}
+		case *ast.SelectorExpr:
+			if info.Overloads[fun.Sel] != nil {
+				return
+			}
+		}
+		sig, ok := info.TypeOf(call.Fun).(*types.Signature)
+		if !ok || call.Ellipsis.IsValid() {
+			return
+		}
+		for i, arg := range call.Args {
+			lit, ok := arg.(*ast.FuncLit)
+			if !ok || i >= sig.Params().Len() || sig.Variadic() && i >= sig.Params().Len()-1 {
+				continue
+			}
+			param := sig.Params().At(i).Type()
+			if _, ok := param.Underlying().(*types.Signature); !ok {
+				continue
+			}
+			if typ := info.TypeOf(lit); typ == nil || !types.Identical(typ, param.Underlying()) {
+				continue
+			}
+			checkFuncLit(pass, lit)
+		}
+	})
+	return nil, nil
+}
+
+// checkFuncLit reports lit if it can be written as a lambda: one whose
+// body is the results of lit, if its body is a single return statement, or
+// the body of lit otherwise.
+func checkFuncLit(pass *analysis.Pass, lit *ast.FuncLit) {
+	var names []string
+	for _, field := range lit.Type.Params.List {
+		if len(field.Names) == 0 {
+			return
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	results := lit.Type.Results
+	if results != nil {
+		for _, field := range results.List {
+			if len(field.Names) != 0 {
+				return
+			}
+		}
+	}
+
+	lhs := strings.Join(names, ", ")
+	if len(names) != 1 {
+		lhs = "(" + lhs + ")"
+	}
+	lhs += " => "
+	if len(names) == 0 {
+		lhs = "=> "
+	}
+
+	var edits []analysis.TextEdit
+	if ret, ok := singleReturn(lit); ok && !hasComments(pass, lit) {
+		// Multiple results are a tuple: (x, y) => (a, b).
+		first, last := ret.Results[0], ret.Results[len(ret.Results)-1]
+		var rparen string
+		if len(ret.Results) > 1 {
+			lhs, rparen = lhs+"(", ")"
+		}
+		edits = []analysis.TextEdit{{
+			Pos:     lit.Pos(),
+			End:     first.Pos(),
+			NewText: []byte(lhs),
+		}, {
+			Pos:     last.End(),
+			End:     lit.End(),
+			NewText: []byte(rparen),
+		}}
+	} else {
+		edits = []analysis.TextEdit{{
+			Pos:     lit.Pos(),
+			End:     lit.Body.Lbrace,
+			NewText: []byte(lhs),
+		}}
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:     lit.Pos(),
+		End:     lit.Body.Lbrace,
+		Message: "function literal can be simplified to a lambda",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Replace with a lambda",
+			TextEdits: edits,
+		}},
+	})
+}
+
+// singleReturn returns the return statement of lit, if it is the only
+// statement of its body and lists the results of lit.
+func singleReturn(lit *ast.FuncLit) (*ast.ReturnStmt, bool) {
+	if len(lit.Body.List) != 1 {
+		return nil, false
+	}
+	ret, ok := lit.Body.List[0].(*ast.ReturnStmt)
+	return ret, ok && len(ret.Results) > 0 && len(ret.Results) == lit.Type.Results.NumFields()
+}
+
+// hasComments reports whether there are comments in lit, which turning
+// it into a lambda expression would drop.
+func hasComments(pass *analysis.Pass, lit *ast.FuncLit) bool {
+	for _, f := range pass.GopFiles {
+		if f.Pos() <= lit.Pos() && lit.Pos() < f.End() {
+			for _, cg := range f.Comments {
+				if lit.Pos() < cg.End() && cg.Pos() < lit.End() {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,361 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package simplifyrange defines an Analyzer that simplifies the range
+// and for statements of Go+ files.
+// https://golang.org/cmd/gofmt/#hdr-The_simplify_command
+// https://github.com/golang/go/blob/master/src/cmd/gofmt/simplify.go
+package simplifyrange
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/printer"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+)
+
+const Doc = `check for range statement simplifications
+
+A range of the form:
+	for x, _ = range v {...}
+will be simplified to:
+	for x = range v {...}
+
+A range of the form:
+	for _ = range v {...}
+will be simplified to:
+	for range v {...}
+
+A for phrase of the form:
+	for _, x <- v {...}
+will be simplified to:
+	for x <- v {...}
+
+A loop of the form:
+	for i := 0; i < n; i++ {...}
+where n is a constant, or a local variable whose address is never taken
+and that no function literal refers to, and where neither i nor n is
+assigned by the loop body, will be simplified to:
+	for i <- 0:n {...}
+
+The first two are among the simplifications that "gofmt -s" applies,
+here to the Go+ files.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "gopSimplifyrange",
+	Doc:      Doc,
+	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifyrange",
+	Requires: []analysis.IAnalyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	escaped := escapedVars(pass.GopTypesInfo, pass.GopFiles)
+	nodeFilter := []ast.Node{
+		(*ast.RangeStmt)(nil),
+		(*ast.ForStmt)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch stmt := n.(type) {
+		case *ast.RangeStmt:
+			checkRange(pass, stmt)
+		case *ast.ForStmt:
+			checkFor(pass, stmt, escaped)
+		}
+	})
+
+	// The inspector does not index the nodes specific to Go+.
+	for _, f := range pass.GopFiles {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if stmt, ok := n.(*ast.ForPhraseStmt); ok {
+				checkForPhrase(pass, stmt)
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+func checkRange(pass *analysis.Pass, stmt *ast.RangeStmt) {
+	x := *stmt
+	copy := &x
+	end := newlineIndex(pass.Fset, copy)
+
+	// Range statements of the form: for i, _ := range x {}
+	var old ast.Expr
+	if isBlank(copy.Value) {
+		old = copy.Value
+		copy.Value = nil
+	}
+	// Range statements of the form: for _ := range x {}
+	if isBlank(copy.Key) && copy.Value == nil {
+		old = copy.Key
+		copy.Key = nil
+	}
+	// Return early if neither if condition is met.
+	if old == nil {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            old.Pos(),
+		End:            old.End(),
+		Message:        "simplify range expression",
+		SuggestedFixes: suggestedFixes(pass.Fset, copy, end),
+	})
+}
+
+// checkForPhrase reports the for phrases whose key is blank: unlike
+// for a range statement, the value of a for phrase is its only variable.
+func checkForPhrase(pass *analysis.Pass, stmt *ast.ForPhraseStmt) {
+	if !isBlank(stmt.Key) || stmt.Value == nil {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:     stmt.Key.Pos(),
+		End:     stmt.Key.End(),
+		Message: "simplify range expression",
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Remove empty key",
+			TextEdits: []analysis.TextEdit{{
+				Pos: stmt.Key.Pos(),
+				End: stmt.Value.Pos(),
+			}},
+		}},
+	})
+}
+
+// checkFor reports the loops of the form
+//
+//	for i := start; i < end; i++ {...}
+//
+// that range over start:end, that is whose body changes neither i nor the
+// value of end, for which end is a constant, a local variable or the length
+// of a local slice or string. The variables may not be escaped, as their
+// changes would then not be seen in the body.
+func checkFor(pass *analysis.Pass, stmt *ast.ForStmt, escaped map[types.Object]bool) {
+	info := pass.GopTypesInfo
+	init, ok := stmt.Init.(*ast.AssignStmt)
+	if !ok || init.Tok != token.DEFINE || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+		return
+	}
+	key, ok := init.Lhs[0].(*ast.Ident)
+	if !ok || key.Name == "_" {
+		return
+	}
+	obj := info.ObjectOf(key)
+	if obj == nil || !types.Identical(obj.Type(), types.Typ[types.Int]) {
+		return
+	}
+	cond, ok := stmt.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.LSS || !isVar(info, cond.X, obj) {
+		return
+	}
+	post, ok := stmt.Post.(*ast.IncDecStmt)
+	if !ok || post.Tok != token.INC || !isVar(info, post.X, obj) {
+		return
+	}
+
+	// The end of the range is evaluated once, unlike the condition.
+	invariant := []types.Object{obj}
+	if tv, ok := info.Types[cond.Y]; !ok || tv.Value == nil {
+		v := boundVar(info, cond.Y)
+		if v == nil {
+			return
+		}
+		invariant = append(invariant, v)
+	}
+	for _, v := range invariant {
+		if !isLocal(v) || escaped[v] {
+			return
+		}
+	}
+	if changes(info, stmt.Body, invariant) {
+		return
+	}
+
+	phrase := fmt.Sprintf("for %s <- %s:%s", key.Name, format(pass.Fset, init.Rhs[0]), format(pass.Fset, cond.Y))
+	pass.Report(analysis.Diagnostic{
+		Pos:     stmt.For,
+		End:     stmt.Body.Lbrace,
+		Message: "for loop can be simplified to " + phrase,
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: fmt.Sprintf("Replace with '%s'", phrase),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     stmt.For,
+				End:     stmt.Body.Lbrace,
+				NewText: []byte(phrase + " "),
+			}},
+		}},
+	})
+}
+
+// isVar reports whether x is an identifier denoting obj.
+func isVar(info *typesutil.Info, x ast.Expr, obj types.Object) bool {
+	id, ok := x.(*ast.Ident)
+	return ok && info.ObjectOf(id) == obj
+}
+
+// boundVar returns the variable on which the end x of a range depends, if
+// x is a variable or a call of the predefined len() on a variable of a
+// slice or string type, whose length only changes when it is assigned.
+func boundVar(info *typesutil.Info, x ast.Expr) *types.Var {
+	if call, ok := x.(*ast.CallExpr); ok {
+		fun, ok := call.Fun.(*ast.Ident)
+		if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() {
+			return nil
+		}
+		if _, ok := info.ObjectOf(fun).(*types.Builtin); !ok || fun.Name != "len" {
+			return nil
+		}
+		x = call.Args[0]
+		switch t := info.TypeOf(x).Underlying().(type) {
+		case *types.Slice:
+		case *types.Basic:
+			if t.Info()&types.IsString == 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, _ := info.ObjectOf(id).(*types.Var)
+	return v
+}
+
+// isLocal reports whether v is a local variable or parameter, not a
+// package-level variable or a field, such as one of a classfile.
+func isLocal(v types.Object) bool {
+	return v.Parent() != nil && v.Pkg() != nil && v.Parent() != v.Pkg().Scope()
+}
+
+// escapedVars returns the variables of files whose address is taken, by
+// the & operator or by calling one of their pointer methods, or that are
+// referred to by a function literal or a lambda that does not declare them:
+// they may be changed by any call.
+func escapedVars(info *typesutil.Info, files []*ast.File) map[types.Object]bool {
+	escaped := make(map[types.Object]bool)
+	captured := func(fn ast.Node) {
+		ast.Inspect(fn, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if v, ok := info.Uses[id].(*types.Var); ok && (v.Pos() < fn.Pos() || v.Pos() >= fn.End()) {
+					escaped[v] = true
+				}
+			}
+			return true
+		})
+	}
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.UnaryExpr:
+				if id, ok := n.X.(*ast.Ident); ok && n.Op == token.AND {
+					if v, ok := info.ObjectOf(id).(*types.Var); ok {
+						escaped[v] = true
+					}
+				}
+			case *ast.SelectorExpr:
+				sel, ok := info.Selections[n]
+				if !ok || sel.Kind() != types.MethodVal {
+					break
+				}
+				recv := sel.Obj().Type().(*types.Signature).Recv()
+				if _, ok := recv.Type().(*types.Pointer); !ok {
+					break
+				}
+				if id, ok := n.X.(*ast.Ident); ok {
+					if v, ok := info.ObjectOf(id).(*types.Var); ok {
+						escaped[v] = true
+					}
+				}
+			case *ast.FuncLit, *ast.LambdaExpr, *ast.LambdaExpr2:
+				captured(n)
+			}
+			return true
+		})
+	}
+	return escaped
+}
+
+// changes reports whether body may change one of the variables objs, by
+// assigning it or incrementing or decrementing it.
+func changes(info *typesutil.Info, body *ast.BlockStmt, objs []types.Object) bool {
+	isObj := func(x ast.Expr) bool {
+		for _, obj := range objs {
+			if isVar(info, x, obj) {
+				return true
+			}
+		}
+		return false
+	}
+	changed := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if isObj(lhs) {
+					changed = true
+				}
+			}
+		case *ast.IncDecStmt:
+			if isObj(n.X) {
+				changed = true
+			}
+		}
+		return !changed
+	})
+	return changed
+}
+
+func format(fset *token.FileSet, x ast.Expr) string {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, x)
+	return b.String()
+}
+
+func suggestedFixes(fset *token.FileSet, rng *ast.RangeStmt, end token.Pos) []analysis.SuggestedFix {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, rng)
+	stmt := b.Bytes()
+	index := bytes.Index(stmt, []byte("\n"))
+	// If there is a new line character, then don't replace the body.
+	if index != -1 {
+		stmt = stmt[:index]
+	}
+	return []analysis.SuggestedFix{{
+		Message: "Remove empty value",
+		TextEdits: []analysis.TextEdit{{
+			Pos:     rng.Pos(),
+			End:     end,
+			NewText: stmt,
+		}},
+	}}
+}
+
+func newlineIndex(fset *token.FileSet, rng *ast.RangeStmt) token.Pos {
+	var b bytes.Buffer
+	printer.Fprint(&b, fset, rng)
+	contents := b.Bytes()
+	index := bytes.Index(contents, []byte("\n"))
+	if index == -1 {
+		return rng.End()
+	}
+	return rng.Pos() + token.Pos(index)
+}
+
+func isBlank(x ast.Expr) bool {
+	ident, ok := x.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
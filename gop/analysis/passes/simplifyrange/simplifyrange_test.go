@@ -0,0 +1,28 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package simplifyrange_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/gop/analysis/analysistest"
+	"golang.org/x/tools/gop/analysis/passes/simplifyrange"
+)
+
+func Test(t *testing.T) {
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, simplifyrange.Analyzer, "a")
+}
+
+func TestClassfile(t *testing.T) {
+	testdata := filepath.Join(analysistest.TestData(), "classfile.txtar")
+	analysistest.RunWithSuggestedFixes(t, testdata, simplifyrange.Analyzer, "example.com/cf/a")
+}
+
+func TestClassfileExt(t *testing.T) {
+	testdata := filepath.Join(analysistest.TestData(), "classext.txtar")
+	analysistest.RunWithSuggestedFixes(t, testdata, simplifyrange.Analyzer, "example.com/cx/a")
+}
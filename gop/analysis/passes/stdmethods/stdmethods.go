@@ -6,10 +6,11 @@ package stdmethods
 
 import (
 	_ "embed"
-	"go/ast"
 	"go/types"
 	"strings"
 
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -20,10 +21,10 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "stdmethods",
+	Name:     "gopStdmethods",
 	Doc:      analysisutil.MustExtractDoc(doc, "stdmethods"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/stdmethods",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{stdmethods.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -98,7 +99,7 @@ func canonicalMethod(pass *analysis.Pass, id *ast.Ident) {
 	}
 
 	// Actual input/output
-	sign := pass.TypesInfo.Defs[id].Type().(*types.Signature)
+	sign := pass.GopTypesInfo.Defs[id].Type().(*types.Signature)
 	args := sign.Params()
 	results := sign.Results()
 
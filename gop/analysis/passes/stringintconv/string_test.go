@@ -7,6 +7,7 @@ package stringintconv_test
 import (
 	"testing"
 
+	gostringintconv "golang.org/x/tools/go/analysis/passes/stringintconv"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/stringintconv"
 	"golang.org/x/tools/internal/typeparams"
@@ -15,8 +16,9 @@ import (
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	pkgs := []string{"a"}
+	analysistest.RunWithSuggestedFixes(t, testdata, stringintconv.Analyzer, pkgs...)
 	if typeparams.Enabled {
-		pkgs = append(pkgs, "typeparams")
+		// The Go files are checked by the Go analyzer that stringintconv.Analyzer requires.
+		analysistest.RunWithSuggestedFixes(t, testdata, gostringintconv.Analyzer, "typeparams")
 	}
-	analysistest.RunWithSuggestedFixes(t, testdata, stringintconv.Analyzer, pkgs...)
 }
@@ -3,7 +3,7 @@
 // license that can be found in the LICENSE file.
 
 // The unusedresult command applies the golang.org/x/tools/gop/analysis/passes/unusedresult
-// analysis to the specified packages of Go source code.
+// analysis to the specified packages of Go/Go+ source code.
 package main
 
 import (
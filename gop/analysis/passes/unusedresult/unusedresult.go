@@ -15,12 +15,13 @@ package unusedresult
 
 import (
 	_ "embed"
-	"go/ast"
-	"go/token"
 	"go/types"
 	"sort"
 	"strings"
 
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/inspect"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
@@ -32,10 +33,10 @@ import (
 var doc string
 
 var Analyzer = &analysis.Analyzer{
-	Name:     "unusedresult",
+	Name:     "gopUnusedresult",
 	Doc:      analysisutil.MustExtractDoc(doc, "unusedresult"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/unusedresult",
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Requires: []analysis.IAnalyzer{unusedresult.Analyzer, inspect.Analyzer},
 	Run:      run,
 }
 
@@ -88,7 +89,7 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 
 		// Call to function or method?
-		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		fn, ok := typeutil.Callee(pass.GopTypesInfo, call).(*types.Func)
 		if !ok {
 			return // e.g. var or builtin
 		}
@@ -7,6 +7,7 @@ package unusedresult_test
 import (
 	"testing"
 
+	gounusedresult "golang.org/x/tools/go/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gop/analysis/passes/unusedresult"
 	"golang.org/x/tools/internal/typeparams"
@@ -16,9 +17,11 @@ func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	funcs := "typeparams/userdefs.MustUse,errors.New,fmt.Errorf,fmt.Sprintf,fmt.Sprint"
 	unusedresult.Analyzer.Flags.Set("funcs", funcs)
+	gounusedresult.Analyzer.Flags.Set("funcs", funcs)
 	tests := []string{"a"}
+	analysistest.Run(t, testdata, unusedresult.Analyzer, tests...)
 	if typeparams.Enabled {
-		tests = append(tests, "typeparams")
+		// The Go files are checked by the Go analyzer that unusedresult.Analyzer requires.
+		analysistest.Run(t, testdata, gounusedresult.Analyzer, "typeparams")
 	}
-	analysistest.Run(t, testdata, unusedresult.Analyzer, tests...)
 }
@@ -9,10 +9,11 @@ import (
 	"fmt"
 	"go/types"
 
+	gounusedwrite "golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/passes/buildssa"
 	"golang.org/x/tools/gop/analysis/passes/internal/analysisutil"
-	"golang.org/x/tools/gop/ssa"
 )
 
 //go:embed doc.go
@@ -21,10 +22,10 @@ var doc string
 // Analyzer reports instances of writes to struct fields and arrays
 // that are never read.
 var Analyzer = &analysis.Analyzer{
-	Name:     "unusedwrite",
+	Name:     "gopUnusedwrite",
 	Doc:      analysisutil.MustExtractDoc(doc, "unusedwrite"),
 	URL:      "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/unusedwrite",
-	Requires: []*analysis.Analyzer{buildssa.Analyzer},
+	Requires: []analysis.IAnalyzer{gounusedwrite.Analyzer, buildssa.Analyzer},
 	Run:      run,
 }
 
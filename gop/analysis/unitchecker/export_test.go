@@ -4,7 +4,6 @@
 
 package unitchecker
 
-/*
 import (
 	"go/token"
 	"go/types"
@@ -25,4 +24,3 @@ func SetTypeImportExport(
 	makeTypesImporter = MakeTypesImporter
 	exportTypes = ExportTypes
 }
-*/
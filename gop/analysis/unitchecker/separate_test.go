@@ -7,7 +7,7 @@
 package unitchecker_test
 
 // This file illustrates separate analysis with an example.
-/*
+
 import (
 	"bytes"
 	"encoding/json"
@@ -21,9 +21,9 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"golang.org/x/tools/go/gcexportdata"
 	"golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/unitchecker"
-	"golang.org/x/tools/gop/gcexportdata"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/testenv"
 	"golang.org/x/tools/txtar"
@@ -57,22 +57,19 @@ import (
 func TestExampleSeparateAnalysis(t *testing.T) {
 	testenv.NeedsGoPackages(t)
 
-	// src is an archive containing a module with a printf mistake.
+	// src is an archive containing a module with a printf mistake,
+	// in Go+ files.
 	const src = `
 -- go.mod --
 module separate
 go 1.18
 
--- main/main.go --
-package main
-
+-- main/main.gop --
 import "separate/lib"
 
-func main() {
-	lib.MyPrintf("%s", 123)
-}
+lib.MyPrintf("%s", 123)
 
--- lib/lib.go --
+-- lib/lib.gop --
 package lib
 
 import "fmt"
@@ -81,7 +78,6 @@ func MyPrintf(format string, args ...any) {
 	fmt.Printf(format, args...)
 }
 `
-
 	// Expand archive into tmp tree.
 	tmpdir := t.TempDir()
 	if err := extractTxtar(txtar.Parse([]byte(src)), tmpdir); err != nil {
@@ -98,7 +94,10 @@ func MyPrintf(format string, args ...any) {
 		),
 		Logf: t.Logf,
 	}
-	pkgs, err := packages.Load(cfg, "separate/main")
+	// The Go code of the Go+ packages is what the worker type-checks,
+	// along with their Go+ files, as under "go vet".
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	pkgs, err := packages.LoadEx(gop, cfg, "separate/main")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -153,6 +152,7 @@ func MyPrintf(format string, args ...any) {
 		}
 		cfg := unitchecker.Config{
 			ID:           pkg.ID,
+			Dir:          filepath.Dir(pkg.CompiledGoFiles[0]),
 			ImportPath:   pkg.PkgPath,
 			GoFiles:      pkg.CompiledGoFiles,
 			NonGoFiles:   pkg.OtherFiles,
@@ -219,7 +219,7 @@ func MyPrintf(format string, args ...any) {
 	// Observe that the example produces a fact-based diagnostic
 	// from separate analysis of "main", "lib", and "fmt":
 
-	const want = `/main/main.go:6:2: [printf] separate/lib.MyPrintf format %s has arg 123 of wrong type int`
+	const want = `/main/main.gop:3:1: [gopPrintf] separate/lib.MyPrintf format %s has arg 123 of wrong type int`
 	if got := strings.Join(allDiagnostics, "\n"); got != want {
 		t.Errorf("Got: %s\nWant: %s", got, want)
 	}
@@ -309,4 +309,3 @@ func extractTxtar(ar *txtar.Archive, dir string) error {
 	}
 	return nil
 }
-*/
@@ -14,6 +14,9 @@
 //	-flags          describe flags                    (to the build tool)
 //	foo.cfg         description of compilation unit (from the build tool)
 //
+// The Go+ files of a package whose Go code was generated by gop are
+// analyzed in place of the generated files (gop_autogen*.go).
+//
 // This package does not depend on go/packages.
 // If you need a standalone tool, use multichecker,
 // which supports this mode but can also load packages
@@ -27,7 +30,6 @@ package unitchecker
 //   printf checker.
 
 import (
-	"encoding/gob"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -50,6 +52,7 @@ import (
 
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/analysis/internal/analysisflags"
+	"golang.org/x/tools/gop/goputil"
 	"golang.org/x/tools/internal/facts"
 	"golang.org/x/tools/internal/typeparams"
 )
@@ -84,7 +87,7 @@ type Config struct {
 //	-V=full         describe executable for build caching
 //	foo.cfg         perform separate modular analyze on the single
 //	                unit described by a JSON config file foo.cfg.
-func Main(analyzers ...*analysis.Analyzer) {
+func Main(analyzers ...analysis.IAnalyzer) {
 	progname := filepath.Base(os.Args[0])
 	log.SetFlags(0)
 	log.SetPrefix(progname + ": ")
@@ -94,7 +97,7 @@ func Main(analyzers ...*analysis.Analyzer) {
 	}
 
 	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go programs.
+		fmt.Fprintf(os.Stderr, `%[1]s is a tool for static analysis of Go/Go+ programs.
 
 Usage of %[1]s:
 	%.16[1]s unit.cfg	# execute analysis specified by config file
@@ -123,7 +126,7 @@ Usage of %[1]s:
 // Run reads the *.cfg file, runs the analysis,
 // and calls os.Exit with an appropriate error code.
 // It assumes flags have already been set.
-func Run(configFile string, analyzers []*analysis.Analyzer) {
+func Run(configFile string, analyzers []analysis.IAnalyzer) {
 	cfg, err := readConfig(configFile)
 	if err != nil {
 		log.Fatal(err)
@@ -141,7 +144,7 @@ func Run(configFile string, analyzers []*analysis.Analyzer) {
 			// JSON output
 			tree := make(analysisflags.JSONTree)
 			for _, res := range results {
-				tree.Add(fset, cfg.ID, res.a.Name, res.diagnostics, res.err)
+				tree.Add(fset, cfg.ID, analysis.Name(res.a), res.diagnostics, res.err)
 			}
 			tree.Print()
 		} else {
@@ -184,7 +187,38 @@ func readConfig(filename string) (*Config, error) {
 	return cfg, nil
 }
 
-func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]result, error) {
+// goxls: the importer of the types and the export of those of the package
+// are hooks of the tests, as in later versions of unitchecker.
+var (
+	makeTypesImporter = func(cfg *Config, fset *token.FileSet) types.Importer {
+		compilerImporter := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
+			// path is a resolved package path, not an import path.
+			file, ok := cfg.PackageFile[path]
+			if !ok {
+				if cfg.Compiler == "gccgo" && cfg.Standard[path] {
+					return nil, nil // fall back to default gccgo lookup
+				}
+				return nil, fmt.Errorf("no package file for %q", path)
+			}
+			return os.Open(file)
+		})
+		return importerFunc(func(importPath string) (*types.Package, error) {
+			path, ok := cfg.ImportMap[importPath] // resolve vendoring, etc
+			if !ok {
+				return nil, fmt.Errorf("can't resolve import %q", path)
+			}
+			return compilerImporter.Import(path)
+		})
+	}
+
+	exportTypes = func(*Config, *token.FileSet, *types.Package) error {
+		// By default this is a no-op, because "go vet"
+		// makes the compiler produce type information.
+		return nil
+	}
+)
+
+func run(fset *token.FileSet, cfg *Config, analyzers []analysis.IAnalyzer) ([]result, error) {
 	// Load, parse, typecheck.
 	var files []*ast.File
 	for _, name := range cfg.GoFiles {
@@ -199,26 +233,8 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		}
 		files = append(files, f)
 	}
-	compilerImporter := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
-		// path is a resolved package path, not an import path.
-		file, ok := cfg.PackageFile[path]
-		if !ok {
-			if cfg.Compiler == "gccgo" && cfg.Standard[path] {
-				return nil, nil // fall back to default gccgo lookup
-			}
-			return nil, fmt.Errorf("no package file for %q", path)
-		}
-		return os.Open(file)
-	})
-	importer := importerFunc(func(importPath string) (*types.Package, error) {
-		path, ok := cfg.ImportMap[importPath] // resolve vendoring, etc
-		if !ok {
-			return nil, fmt.Errorf("can't resolve import %q", path)
-		}
-		return compilerImporter.Import(path)
-	})
 	tc := &types.Config{
-		Importer:  importer,
+		Importer:  makeTypesImporter(cfg, fset),
 		Sizes:     types.SizesFor("gc", build.Default.GOARCH), // assume gccgo ≡ gc?
 		GoVersion: cfg.GoVersion,
 	}
@@ -242,6 +258,26 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		return nil, err
 	}
 
+	// goxls: Go+ - recheck the Go+ files in place of the generated ones
+	files, autogen, gopFiles, mod, err := parseGop(fset, cfg, pkg.Name(), files)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			// Silently succeed; let the compiler
+			// report parse errors.
+			err = nil
+		}
+		return nil, err
+	}
+	gopInfo, err := checkGop(fset, mod, pkg, autogen, gopFiles, tc, info)
+	if err != nil {
+		if cfg.SucceedOnTypecheckFailure {
+			// Silently succeed; let the compiler
+			// report type errors.
+			err = nil
+		}
+		return nil, err
+	}
+
 	// Register fact types with gob.
 	// In VetxOnly mode, analyzers are only for their facts,
 	// so we can skip any analysis that neither produces facts
@@ -258,18 +294,18 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		usesFacts   bool // (transitively uses)
 		diagnostics []analysis.Diagnostic
 	}
-	actions := make(map[*analysis.Analyzer]*action)
-	var registerFacts func(a *analysis.Analyzer) bool
-	registerFacts = func(a *analysis.Analyzer) bool {
+	actions := make(map[analysis.IAnalyzer]*action)
+	var registerFacts func(a analysis.IAnalyzer) bool
+	registerFacts = func(a analysis.IAnalyzer) bool {
 		act, ok := actions[a]
 		if !ok {
 			act = new(action)
 			var usesFacts bool
-			for _, f := range a.FactTypes {
+			for _, f := range analysis.FactTypes(a) {
 				usesFacts = true
-				gob.Register(f)
+				analysis.RegisterFact(f)
 			}
-			for _, req := range a.Requires {
+			for _, req := range analysis.Requires(a) {
 				if registerFacts(req) {
 					usesFacts = true
 				}
@@ -279,7 +315,7 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		}
 		return act.usesFacts
 	}
-	var filtered []*analysis.Analyzer
+	var filtered []analysis.IAnalyzer
 	for _, a := range analyzers {
 		if registerFacts(a) || !cfg.VetxOnly {
 			filtered = append(filtered, a)
@@ -300,24 +336,26 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 	}
 
 	// In parallel, execute the DAG of analyzers.
-	var exec func(a *analysis.Analyzer) *action
-	var execAll func(analyzers []*analysis.Analyzer)
-	exec = func(a *analysis.Analyzer) *action {
+	var exec func(a analysis.IAnalyzer) *action
+	var execAll func(analyzers []analysis.IAnalyzer)
+	exec = func(a analysis.IAnalyzer) *action {
 		act := actions[a]
 		act.once.Do(func() {
-			execAll(a.Requires) // prefetch dependencies in parallel
+			requires := analysis.Requires(a)
+			execAll(requires) // prefetch dependencies in parallel
 
 			// The inputs to this analysis are the
 			// results of its prerequisites.
 			inputs := make(map[*analysis.Analyzer]interface{})
+			goInputs := make(map[*analysis.GoAnalyzer]interface{})
 			var failed []string
-			for _, req := range a.Requires {
+			for _, req := range requires {
 				reqact := exec(req)
 				if reqact.err != nil {
 					failed = append(failed, req.String())
 					continue
 				}
-				inputs[req] = reqact.result
+				analysis.SetResult(inputs, goInputs, req, reqact.result)
 			}
 
 			// Report an error if any dependency failed.
@@ -328,32 +366,39 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 			}
 
 			factFilter := make(map[reflect.Type]bool)
-			for _, f := range a.FactTypes {
+			for _, f := range analysis.FactTypes(a) {
 				factFilter[reflect.TypeOf(f)] = true
 			}
 
 			pass := &analysis.Pass{
-				Analyzer:          a,
-				Fset:              fset,
-				Files:             files,
-				OtherFiles:        cfg.NonGoFiles,
-				IgnoredFiles:      cfg.IgnoredFiles,
-				Pkg:               pkg,
-				TypesInfo:         info,
-				TypesSizes:        tc.Sizes,
-				TypeErrors:        nil, // unitchecker doesn't RunDespiteErrors
-				ResultOf:          inputs,
-				Report:            func(d analysis.Diagnostic) { act.diagnostics = append(act.diagnostics, d) },
-				ImportObjectFact:  facts.ImportObjectFact,
-				ExportObjectFact:  facts.ExportObjectFact,
-				AllObjectFacts:    func() []analysis.ObjectFact { return facts.AllObjectFacts(factFilter) },
-				ImportPackageFact: facts.ImportPackageFact,
-				ExportPackageFact: facts.ExportPackageFact,
-				AllPackageFacts:   func() []analysis.PackageFact { return facts.AllPackageFacts(factFilter) },
+				GoPass: analysis.GoPass{
+					Fset:              fset,
+					Files:             files,
+					OtherFiles:        cfg.NonGoFiles,
+					IgnoredFiles:      cfg.IgnoredFiles,
+					Pkg:               pkg,
+					TypesInfo:         info,
+					TypesSizes:        tc.Sizes,
+					TypeErrors:        nil, // unitchecker doesn't RunDespiteErrors
+					ResultOf:          goInputs,
+					Report:            func(d analysis.Diagnostic) { act.diagnostics = append(act.diagnostics, d) },
+					ImportObjectFact:  facts.ImportObjectFact,
+					ExportObjectFact:  facts.ExportObjectFact,
+					AllObjectFacts:    func() []analysis.ObjectFact { return facts.AllObjectFacts(factFilter) },
+					ImportPackageFact: facts.ImportPackageFact,
+					ExportPackageFact: facts.ExportPackageFact,
+					AllPackageFacts:   func() []analysis.PackageFact { return facts.AllPackageFacts(factFilter) },
+				},
+				ResultOf:     inputs,
+				GopFiles:     gopFiles,
+				GopTypesInfo: gopInfo,
+				GenGoFiles:   goputil.GenGoFiles(cfg.GoFiles),
+				ReadFile:     os.ReadFile,
 			}
+			pass.SetAnalyzer(a)
 
 			t0 := time.Now()
-			act.result, act.err = a.Run(pass)
+			act.result, act.err = pass.Run()
 
 			if act.err == nil { // resolve URLs on diagnostics.
 				for i := range act.diagnostics {
@@ -370,11 +415,11 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		})
 		return act
 	}
-	execAll = func(analyzers []*analysis.Analyzer) {
+	execAll = func(analyzers []analysis.IAnalyzer) {
 		var wg sync.WaitGroup
 		for _, a := range analyzers {
 			wg.Add(1)
-			go func(a *analysis.Analyzer) {
+			go func(a analysis.IAnalyzer) {
 				_ = exec(a)
 				wg.Done()
 			}(a)
@@ -391,6 +436,16 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		results[i].a = a
 		results[i].err = act.err
 		results[i].diagnostics = act.diagnostics
+
+		// goxls: the diagnostics of a Go+ analyzer include those of
+		// the Go analyzers it requires, which check the Go files.
+		if _, ok := a.(*analysis.Analyzer); ok {
+			for _, req := range analysis.Requires(a) {
+				if _, ok := req.(*analysis.GoAnalyzer); ok {
+					results[i].diagnostics = append(results[i].diagnostics, actions[req].diagnostics...)
+				}
+			}
+		}
 	}
 
 	data := facts.Encode(false)
@@ -398,11 +453,16 @@ func run(fset *token.FileSet, cfg *Config, analyzers []*analysis.Analyzer) ([]re
 		return nil, fmt.Errorf("failed to write analysis facts: %v", err)
 	}
 
+	// Write the package's type information.
+	if err := exportTypes(cfg, fset, pkg); err != nil {
+		return nil, fmt.Errorf("failed to write type information: %v", err)
+	}
+
 	return results, nil
 }
 
 type result struct {
-	a           *analysis.Analyzer
+	a           analysis.IAnalyzer
 	diagnostics []analysis.Diagnostic
 	err         error
 }
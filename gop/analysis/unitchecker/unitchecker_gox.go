@@ -0,0 +1,110 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unitchecker
+
+import (
+	goast "go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/x/typesutil"
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gop/packages"
+)
+
+// parseGop parses the Go+ files of the compilation unit, if it has Go
+// code generated by gop. It returns the Go files that were not generated
+// and those that were, along with the syntax trees of the Go+ files of
+// package pkgName and the Go+ module of the unit.
+func parseGop(fset *token.FileSet, cfg *Config, pkgName string, files []*goast.File) (nongen, autogen []*goast.File, gopFiles []*ast.File, mod *gopmod.Module, err error) {
+	test := false
+	for _, f := range files {
+		fname := filepath.Base(fset.File(f.Pos()).Name())
+		isTest := strings.HasSuffix(fname, "_test.go")
+		test = test || isTest
+		if strings.HasPrefix(fname, "gop_autogen") {
+			autogen = append(autogen, f)
+		} else {
+			nongen = append(nongen, f)
+		}
+	}
+	if len(autogen) == 0 {
+		return files, nil, nil, nil, nil
+	}
+
+	mod, err = gop.LoadMod(cfg.Dir)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	// The Go+ files are selected as by go/packages, in the environment of
+	// go vet, which does not pass on its build flags but those of GOFLAGS.
+	conf := &packages.Config{Env: os.Environ(), BuildFlags: strings.Fields(os.Getenv("GOFLAGS"))}
+	for _, file := range packages.GopFiles(conf, cfg.Dir, pkgName, test) {
+		f, err := parser.ParseEntry(fset, file, nil, parser.Config{
+			Mode:      parser.ParseComments,
+			ClassKind: mod.ClassKind,
+		})
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		gopFiles = append(gopFiles, f)
+	}
+	return nongen, autogen, gopFiles, mod, nil
+}
+
+// checkGop type-checks the Go+ files of the compilation unit into pkg in
+// place of the generated files autogen, if any, and returns their type
+// information. As tc.Check does, it returns the first type error.
+//
+// As go/packages does, the objects declared by the generated files are
+// deleted from pkg and the uses of info that refer to them are updated.
+func checkGop(fset *token.FileSet, mod *gopmod.Module, pkg *types.Package, autogen []*goast.File, gopFiles []*ast.File, tc *types.Config, info *types.Info) (*typesutil.Info, error) {
+	if len(autogen) == 0 {
+		return nil, nil
+	}
+	gopInfo := &typesutil.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Overloads:  make(map[*ast.Ident]types.Object),
+	}
+	var firstErr error
+	conf := &types.Config{
+		Importer:  tc.Importer,
+		Sizes:     tc.Sizes,
+		GoVersion: tc.GoVersion,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	opts := &typesutil.Config{
+		Types: pkg,
+		Fset:  fset,
+		Mod:   mod,
+	}
+	scope := pkg.Scope()
+	objMap := typesutil.DeleteObjects(scope, autogen)
+	err := typesutil.NewChecker(conf, opts, nil, gopInfo).Files(nil, gopFiles)
+	typesutil.CorrectTypesInfo(scope, objMap, info.Uses)
+	if firstErr != nil {
+		err = firstErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return gopInfo, nil
+}
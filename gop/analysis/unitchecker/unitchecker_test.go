@@ -8,15 +8,18 @@ import (
 	"flag"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/goplus/mod/modfile"
 	"golang.org/x/tools/gop/analysis/passes/assign"
 	"golang.org/x/tools/gop/analysis/passes/findcall"
 	"golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/unitchecker"
+	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/gop/packages/packagestest"
 )
 
@@ -29,6 +32,9 @@ func TestMain(m *testing.M) {
 	case "minivet":
 		minivet()
 		panic("unreachable")
+	case "worker":
+		worker() // see ExampleSeparateAnalysis
+		panic("unreachable")
 	}
 
 	// test process
@@ -48,16 +54,15 @@ func minivet() {
 // This is a very basic integration test of modular
 // analysis with facts using unitchecker under "go vet".
 // It fork/execs the main function above.
-func TestIntegration(t *testing.T) { packagestest.TestAll(t, testIntegration) }
-func testIntegration(t *testing.T, exporter packagestest.Exporter) {
+func TestIntegration(t *testing.T) {
 	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
 		t.Skipf("skipping fork/exec test on this platform")
 	}
 
-	exported := packagestest.Export(t, exporter, []packagestest.Module{{
+	exported := packagestest.Export(t, packagestest.Modules, []packagestest.Module{{
 		Name: "golang.org/fake",
 		Files: map[string]interface{}{
-			"a/a.go": `package a
+			"a/a.gop": `package a
 
 func _() {
 	MyFunc123()
@@ -65,7 +70,7 @@ func _() {
 
 func MyFunc123() {}
 `,
-			"b/b.go": `package b
+			"b/b.gop": `package b
 
 import "golang.org/fake/a"
 
@@ -76,39 +81,80 @@ func _() {
 
 func MyFunc123() {}
 `,
-			"c/c.go": `package c
+			"c/c.gop": `package c
 
 func _() {
     i := 5
     i = i
 }
 `,
-		}}})
+			// not built, and not type-checkable
+			"c/c_ignore.gop": `//go:build ignore
+
+package c
+
+var _ int = "s"
+`,
+			// a classfile registered by the gop.mod of the module only
+			"d/main.tgmx": `MyFunc123()
+`,
+			"game/game.go": `package game
+
+type Game struct{}
+
+func (p *Game) Main() {}
+
+func (p *Game) MyFunc123() {}
+`,
+		},
+		Projects: []*modfile.Project{{
+			Ext:      ".tgmx",
+			Class:    "Game",
+			PkgPaths: []string{"golang.org/fake/game"},
+		}},
+	}})
 	defer exported.Cleanup()
 
+	// The Go code of the Go+ packages is what "go vet" builds. It is
+	// generated in-process, so that Go+ need not be installed.
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	cfg := *exported.Config
+	cfg.Mode = packages.NeedName
+	if _, err := packages.LoadEx(gop, &cfg, "./..."); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"a", "b", "c", "d"} {
+		if _, err := os.Stat(filepath.Join(exported.Config.Dir, dir, "gop_autogen.go")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
 	const wantA = `# golang.org/fake/a
-([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.go:4:11: call of MyFunc123\(...\)
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop:4:11: call of MyFunc123\(...\)
 `
 	const wantB = `# golang.org/fake/b
-([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?b/b.go:6:13: call of MyFunc123\(...\)
-([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?b/b.go:7:11: call of MyFunc123\(...\)
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?b/b.gop:6:13: call of MyFunc123\(...\)
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?b/b.gop:7:11: call of MyFunc123\(...\)
 `
 	const wantC = `# golang.org/fake/c
-([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?c/c.go:5:5: self-assignment of i to i
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?c/c.gop:5:5: self-assignment of i to i
+`
+	const wantD = `# \[golang.org/fake/d\]
+([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?d/main.tgmx:1:10: call of MyFunc123\(...\)
 `
 	const wantAJSON = `# golang.org/fake/a
 \{
 	"golang.org/fake/a": \{
-		"findcall": \[
+		"gopFindcall": \[
 			\{
-				"posn": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.go:4:11",
+				"posn": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop:4:11",
 				"message": "call of MyFunc123\(...\)",
 				"suggested_fixes": \[
 					\{
 						"message": "Add '_TEST_'",
 						"edits": \[
 							\{
-								"filename": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.go",
+								"filename": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?a/a.gop",
 								"start": 32,
 								"end": 32,
 								"new": "_TEST_"
@@ -120,31 +166,6 @@ func _() {
 		\]
 	\}
 \}
-`
-	const wantCJSON = `# golang.org/fake/c
-\{
-	"golang.org/fake/c": \{
-		"assign": \[
-			\{
-				"posn": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?c/c.go:5:5",
-				"message": "self-assignment of i to i",
-				"suggested_fixes": \[
-					\{
-						"message": "Remove",
-						"edits": \[
-							\{
-								"filename": "([/._\-a-zA-Z0-9]+[\\/]fake[\\/])?c/c.go",
-								"start": 37,
-								"end": 42,
-								"new": ""
-							\}
-						\]
-					\}
-				\]
-			\}
-		\]
-	\}
-\}
 `
 	for _, test := range []struct {
 		args          string
@@ -154,12 +175,12 @@ func _() {
 		{args: "golang.org/fake/a", wantOut: wantA, wantExitError: true},
 		{args: "golang.org/fake/b", wantOut: wantB, wantExitError: true},
 		{args: "golang.org/fake/c", wantOut: wantC, wantExitError: true},
+		{args: "golang.org/fake/d", wantOut: wantD, wantExitError: true},
 		{args: "golang.org/fake/a golang.org/fake/b", wantOut: wantA + wantB, wantExitError: true},
 		{args: "-json golang.org/fake/a", wantOut: wantAJSON, wantExitError: false},
-		{args: "-json golang.org/fake/c", wantOut: wantCJSON, wantExitError: false},
 		{args: "-c=0 golang.org/fake/a", wantOut: wantA + "4		MyFunc123\\(\\)\n", wantExitError: true},
 	} {
-		cmd := exec.Command("go", "vet", "-vettool="+os.Args[0], "-findcall.name=MyFunc123")
+		cmd := exec.Command("go", "vet", "-vettool="+os.Args[0], "-gopFindcall.name=MyFunc123")
 		cmd.Args = append(cmd.Args, strings.Fields(test.args)...)
 		cmd.Env = append(exported.Config.Env, "ENTRYPOINT=minivet")
 		cmd.Dir = exported.Config.Dir
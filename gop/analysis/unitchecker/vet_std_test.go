@@ -11,68 +11,37 @@ import (
 	"strings"
 	"testing"
 
-	"golang.org/x/tools/gop/analysis/passes/asmdecl"
 	"golang.org/x/tools/gop/analysis/passes/assign"
-	"golang.org/x/tools/gop/analysis/passes/atomic"
 	"golang.org/x/tools/gop/analysis/passes/bools"
-	"golang.org/x/tools/gop/analysis/passes/buildtag"
-	"golang.org/x/tools/gop/analysis/passes/cgocall"
 	"golang.org/x/tools/gop/analysis/passes/composite"
-	"golang.org/x/tools/gop/analysis/passes/copylock"
-	"golang.org/x/tools/gop/analysis/passes/directive"
-	"golang.org/x/tools/gop/analysis/passes/errorsas"
-	"golang.org/x/tools/gop/analysis/passes/framepointer"
-	"golang.org/x/tools/gop/analysis/passes/httpresponse"
-	"golang.org/x/tools/gop/analysis/passes/ifaceassert"
-	"golang.org/x/tools/gop/analysis/passes/loopclosure"
-	"golang.org/x/tools/gop/analysis/passes/lostcancel"
-	"golang.org/x/tools/gop/analysis/passes/nilfunc"
 	"golang.org/x/tools/gop/analysis/passes/printf"
 	"golang.org/x/tools/gop/analysis/passes/shift"
-	"golang.org/x/tools/gop/analysis/passes/sigchanyzer"
 	"golang.org/x/tools/gop/analysis/passes/stdmethods"
 	"golang.org/x/tools/gop/analysis/passes/stringintconv"
 	"golang.org/x/tools/gop/analysis/passes/structtag"
-	"golang.org/x/tools/gop/analysis/passes/testinggoroutine"
-	"golang.org/x/tools/gop/analysis/passes/tests"
 	"golang.org/x/tools/gop/analysis/passes/timeformat"
-	"golang.org/x/tools/gop/analysis/passes/unmarshal"
-	"golang.org/x/tools/gop/analysis/passes/unreachable"
 	"golang.org/x/tools/gop/analysis/passes/unusedresult"
 	"golang.org/x/tools/gop/analysis/unitchecker"
 )
 
 // vet is the entrypoint of this executable when ENTRYPOINT=vet.
 // Keep consistent with the actual vet in GOROOT/src/cmd/vet/main.go.
+//
+// goxls: only the analyzers of vet ported to Go+ are run; asmdecl,
+// atomic, buildtag, cgocall, copylock, directive, errorsas, framepointer,
+// httpresponse, ifaceassert, loopclosure, lostcancel, nilfunc,
+// sigchanyzer, tests, testinggoroutine, unmarshal and unreachable are not.
 func vet() {
 	unitchecker.Main(
-		asmdecl.Analyzer,
 		assign.Analyzer,
-		atomic.Analyzer,
 		bools.Analyzer,
-		buildtag.Analyzer,
-		cgocall.Analyzer,
 		composite.Analyzer,
-		copylock.Analyzer,
-		directive.Analyzer,
-		errorsas.Analyzer,
-		framepointer.Analyzer,
-		httpresponse.Analyzer,
-		ifaceassert.Analyzer,
-		loopclosure.Analyzer,
-		lostcancel.Analyzer,
-		nilfunc.Analyzer,
 		printf.Analyzer,
 		shift.Analyzer,
-		sigchanyzer.Analyzer,
 		stdmethods.Analyzer,
 		stringintconv.Analyzer,
 		structtag.Analyzer,
-		tests.Analyzer,
-		testinggoroutine.Analyzer,
 		timeformat.Analyzer,
-		unmarshal.Analyzer,
-		unreachable.Analyzer,
 		// unsafeptr.Analyzer, // currently reports findings in runtime
 		unusedresult.Analyzer,
 	)
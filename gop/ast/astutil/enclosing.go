@@ -467,6 +467,61 @@ func childrenOf(n ast.Node) []ast.Node {
 
 	case *ast.BadDecl, *ast.BadExpr, *ast.BadStmt:
 		// nop
+
+	// goxls: Go+ extended expr and stmt
+	case *ast.SliceLit:
+		children = append(children,
+			tok(n.Lbrack, len("[")),
+			tok(n.Rbrack, len("]")))
+
+	case *ast.LambdaExpr:
+		children = append(children,
+			tok(n.Rarrow, len("=>")))
+
+	case *ast.LambdaExpr2:
+		children = append(children,
+			tok(n.Rarrow, len("=>")))
+
+	case *ast.ForPhrase:
+		children = append(children,
+			tok(n.For, len("for")),
+			tok(n.TokPos, len("<-")))
+
+	case *ast.ComprehensionExpr:
+		children = append(children,
+			tok(n.Lpos, len("[")), // or len("{")
+			tok(n.Rpos, len("]"))) // or len("}")
+
+	case *ast.ForPhraseStmt:
+		// nop
+
+	case *ast.RangeExpr:
+		children = append(children,
+			tok(n.To, len(":")))
+		if n.Colon2 != 0 {
+			children = append(children,
+				tok(n.Colon2, len(":")))
+		}
+
+	case *ast.ErrWrapExpr:
+		children = append(children,
+			tok(n.TokPos, len(n.Tok.String())))
+
+	case *ast.OverloadFuncDecl:
+		children = append(children,
+			tok(n.Func, len("func")),
+			tok(n.Assign, len("=")),
+			tok(n.Lparen, len("(")),
+			tok(n.Rparen, len(")")))
+
+	case *ast.EnvExpr:
+		children = append(children,
+			tok(n.TokPos, len("$")))
+		if n.Lbrace != 0 {
+			children = append(children,
+				tok(n.Lbrace, len("{")),
+				tok(n.Rbrace, len("}")))
+		}
 	}
 
 	// TODO(adonovan): opt: merge the logic of ast.Inspect() into
@@ -641,6 +696,31 @@ func NodeDescription(n ast.Node) string {
 	case *ast.ValueSpec:
 		return "value specification"
 
+	// goxls: Go+ extended expr and stmt
+	case *ast.SliceLit:
+		return "slice literal"
+	case *ast.LambdaExpr, *ast.LambdaExpr2:
+		return "lambda expression"
+	case *ast.ForPhrase:
+		return "for phrase"
+	case *ast.ComprehensionExpr:
+		if n.Tok == token.LBRACE {
+			return "map comprehension"
+		}
+		return "list comprehension"
+	case *ast.ForPhraseStmt:
+		return "for phrase statement"
+	case *ast.RangeExpr:
+		return "range expression"
+	case *ast.ErrWrapExpr:
+		if n.Default != nil {
+			return "error wrap ?: expression"
+		}
+		return fmt.Sprintf("error wrap %s expression", n.Tok)
+	case *ast.OverloadFuncDecl:
+		return "overload function declaration"
+	case *ast.EnvExpr:
+		return "environment variable"
 	}
 	panic(fmt.Sprintf("unexpected node type: %T", n))
 }
@@ -12,14 +12,13 @@ package astutil_test
 import (
 	"bytes"
 	"fmt"
-	"go/ast"
-	"go/parser"
-	"go/token"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
 	"strings"
 	"testing"
 
 	"golang.org/x/tools/gop/ast/astutil"
-	"golang.org/x/tools/internal/typeparams"
 )
 
 // pathToString returns a string containing the concrete types of the
@@ -73,17 +72,7 @@ func main() {
 }
 `
 
-	if typeparams.Enabled {
-		src += `
-func g[A any, P interface{ctype1| ~ctype2}](a1 A, p1 P) {}
-
-type PT[T constraint] struct{ t T }
-
-var v GT[targ1]
-
-var h = g[ targ2, targ3]
-`
-	}
+	// goxls: the Go+ parser doesn't support type parameters yet.
 	return src
 }
 
@@ -130,31 +119,6 @@ func TestPathEnclosingInterval_Exact(t *testing.T) {
 		{" f",
 			"f"},
 	}
-	if typeparams.Enabled {
-		tests = append(tests, []testCase{
-			dup("[A any, P interface{ctype1| ~ctype2}]"),
-			{"[", "[A any, P interface{ctype1| ~ctype2}]"},
-			dup("A"),
-			{" any", "any"},
-			dup("ctype1"),
-			{"|", "ctype1| ~ctype2"},
-			dup("ctype2"),
-			{"~", "~ctype2"},
-			dup("~ctype2"),
-			{" ~ctype2", "~ctype2"},
-			{"]", "[A any, P interface{ctype1| ~ctype2}]"},
-			dup("a1"),
-			dup("a1 A"),
-			dup("(a1 A, p1 P)"),
-			dup("type PT[T constraint] struct{ t T }"),
-			dup("PT"),
-			dup("[T constraint]"),
-			dup("constraint"),
-			dup("targ1"),
-			{" targ2", "targ2"},
-			dup("g[ targ2, targ3]"),
-		}...)
-	}
 	for _, test := range tests {
 		f, start, end := findInterval(t, new(token.FileSet), input, test.substr)
 		if f == nil {
@@ -219,18 +183,6 @@ func TestPathEnclosingInterval_Paths(t *testing.T) {
 		{"f() // NB",
 			"[CallExpr ExprStmt BlockStmt FuncDecl File],true"},
 	}
-	if typeparams.Enabled {
-		tests = append(tests, []testCase{
-			{" any", "[Ident Field FieldList FuncDecl File],true"},
-			{"|", "[BinaryExpr Field FieldList InterfaceType Field FieldList FuncDecl File],true"},
-			{"ctype2",
-				"[Ident UnaryExpr BinaryExpr Field FieldList InterfaceType Field FieldList FuncDecl File],true"},
-			{"a1", "[Ident Field FieldList FuncDecl File],true"},
-			{"PT[T constraint]", "[TypeSpec GenDecl File],false"},
-			{"[T constraint]", "[FieldList TypeSpec GenDecl File],true"},
-			{"targ2", "[Ident IndexListExpr ValueSpec GenDecl File],true"},
-		}...)
-	}
 	for _, test := range tests {
 		f, start, end := findInterval(t, new(token.FileSet), input, test.substr)
 		if f == nil {
@@ -245,3 +197,95 @@ func TestPathEnclosingInterval_Paths(t *testing.T) {
 		}
 	}
 }
+
+// goxls: Go+ extended expr and stmt
+
+var gopInput = `package main
+
+func add = (
+	func(a, b int) int { return a + b }
+	func(a, b string) string { return a + b }
+)
+
+func main() {
+	xs := [1, 2, 3]
+	sq := [x * x for x <- xs if x > 1]
+	for i <- 0:10:2 {
+		echo i
+	}
+	apply(x => x + 1)
+	n := parse("1")?:0
+	home := ${HOME}
+}
+`
+
+func TestPathEnclosingInterval_Gop(t *testing.T) {
+	type testCase struct {
+		substr string // first occurrence of this string indicates interval
+		path   string // the pathToString(),exact of the expected path
+		desc   string // NodeDescription of the innermost node
+	}
+	tests := []testCase{
+		{"=",
+			"[OverloadFuncDecl File],true", "overload function declaration"},
+		{"= (",
+			"[OverloadFuncDecl File],false", "overload function declaration"},
+		{"[1, 2, 3]",
+			"[SliceLit AssignStmt BlockStmt FuncDecl File],true", "slice literal"},
+		{"<-",
+			"[ForPhrase ComprehensionExpr AssignStmt BlockStmt FuncDecl File],true", "for phrase"},
+		{"[x * x for x <- xs if x > 1]",
+			"[ComprehensionExpr AssignStmt BlockStmt FuncDecl File],true", "list comprehension"},
+		{"[x * x",
+			"[ComprehensionExpr AssignStmt BlockStmt FuncDecl File],false", "list comprehension"},
+		{"0:10:2",
+			"[RangeExpr ForPhrase ForPhraseStmt BlockStmt FuncDecl File],true", "range expression"},
+		{":2",
+			"[RangeExpr ForPhrase ForPhraseStmt BlockStmt FuncDecl File],false", "range expression"},
+		{"for i",
+			"[ForPhrase ForPhraseStmt BlockStmt FuncDecl File],false", "for phrase"},
+		{"=>",
+			"[LambdaExpr CallExpr ExprStmt BlockStmt FuncDecl File],true", "lambda expression"},
+		{"?",
+			"[ErrWrapExpr AssignStmt BlockStmt FuncDecl File],true", "error wrap ?: expression"},
+		{"$",
+			"[EnvExpr AssignStmt BlockStmt FuncDecl File],true", "environment variable"},
+		{"{HOME}",
+			"[EnvExpr AssignStmt BlockStmt FuncDecl File],false", "environment variable"},
+	}
+	for _, test := range tests {
+		f, start, end := findGopInterval(t, new(token.FileSet), gopInput, test.substr)
+		if f == nil {
+			continue
+		}
+
+		path, exact := astutil.PathEnclosingInterval(f, start, end)
+		if got := fmt.Sprintf("%s,%v", pathToString(path), exact); got != test.path {
+			t.Errorf("PathEnclosingInterval(%q): got %q, want %q",
+				test.substr, got, test.path)
+			continue
+		}
+		if got := astutil.NodeDescription(path[0]); got != test.desc {
+			t.Errorf("NodeDescription(%q): got %q, want %q", test.substr, got, test.desc)
+		}
+	}
+}
+
+// findGopInterval is like findInterval, but parses input as Go+ source.
+func findGopInterval(t *testing.T, fset *token.FileSet, input, substr string) (f *ast.File, start, end token.Pos) {
+	f, err := parser.ParseFile(fset, "<input>.gop", input, 0)
+	if err != nil {
+		t.Errorf("parse error: %s", err)
+		return
+	}
+
+	i := strings.Index(input, substr)
+	if i < 0 {
+		t.Errorf("%q is not a substring of input", substr)
+		f = nil
+		return
+	}
+
+	filePos := fset.File(f.Name.Pos())
+	return f, filePos.Pos(i), filePos.Pos(i + len(substr))
+}
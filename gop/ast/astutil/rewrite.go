@@ -6,11 +6,11 @@ package astutil
 
 import (
 	"fmt"
-	"go/ast"
 	"reflect"
 	"sort"
 
-	"golang.org/x/tools/internal/typeparams"
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/internal/gop/typeparams"
 )
 
 // An ApplyFunc is invoked by Apply for each node n, even if n is nil,
@@ -41,6 +41,12 @@ type ApplyFunc func(*Cursor) bool
 // Children are traversed in the order in which they appear in the
 // respective node's struct definition. A package's files are
 // traversed in the filenames' alphabetical order.
+//
+// goxls: The expressions embedded in a Go+ string literal, such as x in
+// "x = ${x}", are children of the *ast.BasicLit, in its Extra.Parts, and
+// the elements of the i-th row of an *ast.MatrixLit are those of its field
+// named "Elts[i]". All but the Body of a shadow entry *ast.FuncDecl are
+// synthesized and not traversed, as by ast.Walk.
 func Apply(root ast.Node, pre, post ApplyFunc) (result ast.Node) {
 	parent := &struct{ ast.Node }{root}
 	defer func() {
@@ -85,6 +91,8 @@ func (c *Cursor) Parent() ast.Node { return c.parent }
 // Name returns the name of the parent Node field that contains the current Node.
 // If the parent is a *ast.Package and the current Node is a *ast.File, Name returns
 // the filename for the current Node.
+// goxls: If the parent is a *ast.BasicLit, Name returns "Parts", the field of
+// its Extra, and if it is a *ast.MatrixLit, "Elts[i]" for its i-th row.
 func (c *Cursor) Name() string { return c.name }
 
 // Index reports the index >= 0 of the current Node in the slice of Nodes that
@@ -100,7 +108,24 @@ func (c *Cursor) Index() int {
 
 // field returns the current node's parent field value.
 func (c *Cursor) field() reflect.Value {
-	return reflect.Indirect(reflect.ValueOf(c.parent)).FieldByName(c.name)
+	return fieldByName(c.parent, c.name)
+}
+
+// fieldByName returns the value of the field of parent with the given name.
+func fieldByName(parent ast.Node, name string) reflect.Value {
+	// goxls: Go+ nodes whose children are not in their own fields
+	switch p := parent.(type) {
+	case *ast.BasicLit:
+		if name == "Parts" {
+			return reflect.ValueOf(p.Extra).Elem().FieldByName(name)
+		}
+	case *ast.MatrixLit:
+		var row int
+		if _, err := fmt.Sscanf(name, "Elts[%d]", &row); err == nil {
+			return reflect.ValueOf(p.Elts).Index(row)
+		}
+	}
+	return reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
 }
 
 // Replace replaces the current Node with n.
@@ -227,9 +252,14 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.applyList(n, "List")
 
 	// Expressions
-	case *ast.BadExpr, *ast.Ident, *ast.BasicLit:
+	case *ast.BadExpr, *ast.Ident:
 		// nothing to do
 
+	case *ast.BasicLit:
+		if n.Extra != nil { // goxls: Go+ string literal with ${expr}
+			a.applyList(n, "Parts")
+		}
+
 	case *ast.Ellipsis:
 		a.apply(n, "Elt", nil, n.Elt)
 
@@ -422,16 +452,20 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 		a.applyList(n, "Specs")
 
 	case *ast.FuncDecl:
-		a.apply(n, "Doc", nil, n.Doc)
-		a.apply(n, "Recv", nil, n.Recv)
-		a.apply(n, "Name", nil, n.Name)
-		a.apply(n, "Type", nil, n.Type)
+		if !n.Shadow { // goxls: Go+ shadow func
+			a.apply(n, "Doc", nil, n.Doc)
+			a.apply(n, "Recv", nil, n.Recv)
+			a.apply(n, "Name", nil, n.Name)
+			a.apply(n, "Type", nil, n.Type)
+		}
 		a.apply(n, "Body", nil, n.Body)
 
 	// Files and packages
 	case *ast.File:
 		a.apply(n, "Doc", nil, n.Doc)
-		a.apply(n, "Name", nil, n.Name)
+		if n.HasPkgDecl() { // goxls: check has package
+			a.apply(n, "Name", nil, n.Name)
+		}
 		a.applyList(n, "Decls")
 		// Don't walk n.Comments; they have either been walked already if
 		// they are Doc comments, or they can be easily walked explicitly.
@@ -447,6 +481,59 @@ func (a *application) apply(parent ast.Node, name string, iter *iterator, n ast.
 			a.apply(n, name, nil, n.Files[name])
 		}
 
+	// goxls: Go+ extended expr, stmt and decl
+	case *ast.EnvExpr:
+		a.apply(n, "Name", nil, n.Name)
+
+	case *ast.SliceLit:
+		a.applyList(n, "Elts")
+
+	case *ast.MatrixLit:
+		for i := range n.Elts {
+			a.applyList(n, fmt.Sprintf("Elts[%d]", i))
+		}
+
+	case *ast.ElemEllipsis:
+		a.apply(n, "Elt", nil, n.Elt)
+
+	case *ast.ErrWrapExpr:
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Default", nil, n.Default)
+
+	case *ast.LambdaExpr:
+		a.applyList(n, "Lhs")
+		a.applyList(n, "Rhs")
+
+	case *ast.LambdaExpr2:
+		a.applyList(n, "Lhs")
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.ForPhrase:
+		a.apply(n, "Key", nil, n.Key)
+		a.apply(n, "Value", nil, n.Value)
+		a.apply(n, "X", nil, n.X)
+		a.apply(n, "Init", nil, n.Init)
+		a.apply(n, "Cond", nil, n.Cond)
+
+	case *ast.ComprehensionExpr:
+		a.apply(n, "Elt", nil, n.Elt)
+		a.applyList(n, "Fors")
+
+	case *ast.ForPhraseStmt:
+		a.apply(n, "ForPhrase", nil, n.ForPhrase)
+		a.apply(n, "Body", nil, n.Body)
+
+	case *ast.RangeExpr:
+		a.apply(n, "First", nil, n.First)
+		a.apply(n, "Last", nil, n.Last)
+		a.apply(n, "Expr3", nil, n.Expr3)
+
+	case *ast.OverloadFuncDecl:
+		a.apply(n, "Doc", nil, n.Doc)
+		a.apply(n, "Recv", nil, n.Recv)
+		a.apply(n, "Name", nil, n.Name)
+		a.applyList(n, "Funcs")
+
 	default:
 		panic(fmt.Sprintf("Apply: unexpected node type %T", n))
 	}
@@ -469,7 +556,7 @@ func (a *application) applyList(parent ast.Node, name string) {
 	a.iter.index = 0
 	for {
 		// must reload parent.name each time, since cursor modifications might change it
-		v := reflect.Indirect(reflect.ValueOf(parent)).FieldByName(name)
+		v := fieldByName(parent, name)
 		if a.iter.index >= v.Len() {
 			break
 		}
@@ -477,6 +564,11 @@ func (a *application) applyList(parent ast.Node, name string) {
 		// element x may be nil in a bad AST - be cautious
 		var x ast.Node
 		if e := v.Index(a.iter.index); e.IsValid() {
+			if _, ok := e.Interface().(string); ok {
+				// goxls: the text of a Go+ string literal with ${expr}
+				a.iter.index++
+				continue
+			}
 			x = e.Interface().(ast.Node)
 		}
 
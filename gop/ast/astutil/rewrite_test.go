@@ -0,0 +1,493 @@
+// Copyright 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package astutil_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/ast/astutil"
+)
+
+type rewriteTest struct {
+	name       string
+	orig, want string
+	pre, post  astutil.ApplyFunc
+}
+
+var rewriteTests = []rewriteTest{
+	{name: "nop", orig: "package p\n", want: "package p\n"},
+
+	{name: "replace",
+		orig: `package p
+
+var x int
+`,
+		want: `package p
+
+var t T
+`,
+		post: func(c *astutil.Cursor) bool {
+			if _, ok := c.Node().(*ast.ValueSpec); ok {
+				c.Replace(valspec("t", "T"))
+				return false
+			}
+			return true
+		},
+	},
+
+	{name: "set doc strings",
+		orig: `package p
+
+const z = 0
+
+type T struct{}
+
+var x int
+`,
+		want: `package p
+// a foo is a foo
+const z = 0
+// a foo is a foo
+type T struct{}
+// a foo is a foo
+var x int
+`,
+		post: func(c *astutil.Cursor) bool {
+			if _, ok := c.Parent().(*ast.GenDecl); ok && c.Name() == "Doc" && c.Node() == nil {
+				c.Replace(&ast.CommentGroup{List: []*ast.Comment{{Text: "// a foo is a foo"}}})
+			}
+			return true
+		},
+	},
+
+	{name: "insert names",
+		orig: `package p
+
+const a = 1
+`,
+		want: `package p
+
+const a, b, c = 1, 2, 3
+`,
+		pre: func(c *astutil.Cursor) bool {
+			if _, ok := c.Parent().(*ast.ValueSpec); ok {
+				switch c.Name() {
+				case "Names":
+					c.InsertAfter(ast.NewIdent("c"))
+					c.InsertAfter(ast.NewIdent("b"))
+				case "Values":
+					c.InsertAfter(&ast.BasicLit{Kind: token.INT, Value: "3"})
+					c.InsertAfter(&ast.BasicLit{Kind: token.INT, Value: "2"})
+				}
+			}
+			return true
+		},
+	},
+
+	{name: "insert",
+		orig: `package p
+
+var (
+	x int
+	y int
+)
+`,
+		want: `package p
+
+var before1 int
+var before2 int
+
+var (
+	x int
+	y int
+)
+var after2 int
+var after1 int
+`,
+		pre: func(c *astutil.Cursor) bool {
+			if _, ok := c.Node().(*ast.GenDecl); ok {
+				c.InsertBefore(vardecl("before1", "int"))
+				c.InsertAfter(vardecl("after1", "int"))
+				c.InsertAfter(vardecl("after2", "int"))
+				c.InsertBefore(vardecl("before2", "int"))
+			}
+			return true
+		},
+	},
+
+	{name: "delete",
+		orig: `package p
+
+var x int
+var y int
+var z int
+`,
+		want: `package p
+
+var y int
+var z int
+`,
+		pre: func(c *astutil.Cursor) bool {
+			n := c.Node()
+			if d, ok := n.(*ast.GenDecl); ok && d.Specs[0].(*ast.ValueSpec).Names[0].Name == "x" {
+				c.Delete()
+			}
+			return true
+		},
+	},
+
+	{name: "insertafter-delete",
+		orig: `package p
+
+var x int
+var y int
+var z int
+`,
+		want: `package p
+
+var x1 int
+
+var y int
+var z int
+`,
+		pre: func(c *astutil.Cursor) bool {
+			n := c.Node()
+			if d, ok := n.(*ast.GenDecl); ok && d.Specs[0].(*ast.ValueSpec).Names[0].Name == "x" {
+				c.InsertAfter(vardecl("x1", "int"))
+				c.Delete()
+			}
+			return true
+		},
+	},
+
+	{name: "delete-insertafter",
+		orig: `package p
+
+var x int
+var y int
+var z int
+`,
+		want: `package p
+
+var y int
+var x1 int
+var z int
+`,
+		pre: func(c *astutil.Cursor) bool {
+			n := c.Node()
+			if d, ok := n.(*ast.GenDecl); ok && d.Specs[0].(*ast.ValueSpec).Names[0].Name == "x" {
+				c.Delete()
+				// The cursor is now effectively atop the 'var y int' node.
+				c.InsertAfter(vardecl("x1", "int"))
+			}
+			return true
+		},
+	},
+}
+
+// goxls: Go+ extended nodes
+func init() {
+	rename := func(from, to string) astutil.ApplyFunc {
+		return func(c *astutil.Cursor) bool {
+			if ident, ok := c.Node().(*ast.Ident); ok && ident.Name == from {
+				c.Replace(ast.NewIdent(to))
+			}
+			return true
+		}
+	}
+	rewriteTests = append(rewriteTests, []rewriteTest{
+		{name: "lambda",
+			orig: `package p
+
+var f = (x, y) => x + y
+var g = x => {
+	return x
+}
+`,
+			want: `package p
+
+var f = (a, y) => a + y
+var g = a => {
+	return a
+}
+`,
+			post: rename("x", "a"),
+		},
+		{name: "comprehension",
+			orig: `package p
+
+var a = [x*x for x <- [1, 2, 3] if x > 1]
+var b = {x: i for i, x <- a if i > 0}
+`,
+			want: `package p
+
+var a = [v*v for v <- [1, 2, 3] if v > 1]
+var b = {v: i for i, v <- a if i > 0}
+`,
+			post: rename("x", "v"),
+		},
+		{name: "forphrase-range",
+			orig: `package p
+
+func f() {
+	for i <- 1:10:2 {
+		println(i)
+	}
+}
+`,
+			want: `package p
+
+func f() {
+	for j <- 1:n:2 {
+		println(j)
+	}
+}
+`,
+			post: func(c *astutil.Cursor) bool {
+				switch n := c.Node().(type) {
+				case *ast.Ident:
+					if n.Name == "i" {
+						c.Replace(ast.NewIdent("j"))
+					}
+				case *ast.BasicLit:
+					if n.Value == "10" {
+						c.Replace(ast.NewIdent("n"))
+					}
+				}
+				return true
+			},
+		},
+		{name: "errwrap",
+			orig: `package p
+
+func f() {
+	a := g()!
+	b := g()?:0
+}
+`,
+			want: `package p
+
+func f() {
+	a := h()!
+	b := h()?:0
+}
+`,
+			post: rename("g", "h"),
+		},
+		{name: "overload-insert",
+			orig: `package p
+
+func add = (
+	addInt
+	addFloat
+)
+`,
+			want: `package p
+
+func add = (
+	addInt
+	addFloat
+	addString
+)
+`,
+			post: func(c *astutil.Cursor) bool {
+				if ident, ok := c.Node().(*ast.Ident); ok && ident.Name == "addFloat" {
+					c.InsertAfter(ast.NewIdent("addString"))
+				}
+				return true
+			},
+		},
+	}...)
+}
+
+func valspec(name, typ string) *ast.ValueSpec {
+	return &ast.ValueSpec{Names: []*ast.Ident{ast.NewIdent(name)},
+		Type: ast.NewIdent(typ),
+	}
+}
+
+func vardecl(name, typ string) *ast.GenDecl {
+	return &ast.GenDecl{
+		Tok:   token.VAR,
+		Specs: []ast.Spec{valspec(name, typ)},
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	t.Run("*", func(t *testing.T) {
+		for _, test := range rewriteTests {
+			test := test
+			t.Run(test.name, func(t *testing.T) {
+				t.Parallel()
+				fset := token.NewFileSet()
+				f, err := parser.ParseFile(fset, test.name, test.orig, parser.ParseComments)
+				if err != nil {
+					t.Fatal(err)
+				}
+				n := astutil.Apply(f, test.pre, test.post)
+				var buf bytes.Buffer
+				if err := format.Node(&buf, fset, n); err != nil {
+					t.Fatal(err)
+				}
+				got := buf.String()
+				if got != test.want {
+					t.Errorf("got:\n\n%s\nwant:\n\n%s\n", got, test.want)
+				}
+			})
+		}
+	})
+}
+
+// TestRewriteGopParts checks the rewriting of the expressions of a string
+// literal, which are not in a field of the literal but in its Extra.Parts.
+func TestRewriteGopParts(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.gop", "package p\n\nvar s = \"x=${x}, y=${y}\"\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	astutil.Apply(f, nil, func(c *astutil.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Ident); ok && ident.Name == "x" {
+			if c.Name() != "Parts" || c.Index() != 1 {
+				t.Errorf("x: got field %s[%d], want Parts[1]", c.Name(), c.Index())
+			}
+			c.Replace(&ast.CallExpr{Fun: &ast.SelectorExpr{X: ident, Sel: ast.NewIdent("String")}})
+		}
+		return true
+	})
+	lit := f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+	if parts := lit.Extra.Parts; len(parts) != 4 || parts[0] != "x=" || parts[2] != ", y=" {
+		t.Errorf("got parts %v, want the text unchanged", parts)
+	} else if _, ok := parts[1].(*ast.CallExpr); !ok {
+		t.Errorf("got part %T, want *ast.CallExpr", parts[1])
+	}
+
+	// An expression inserted after another one by the cursor follows it
+	// among the parts, and is not visited.
+	f, err = parser.ParseFile(fset, "p.gop", "package p\n\nvar s = \"${x}${y}\"\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexes []int
+	astutil.Apply(f, func(c *astutil.Cursor) bool {
+		if ident, ok := c.Node().(*ast.Ident); ok && c.Name() == "Parts" {
+			indexes = append(indexes, c.Index())
+			if ident.Name == "x" {
+				c.InsertAfter(ast.NewIdent("z"))
+			}
+		}
+		return true
+	}, nil)
+	lit = f.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec).Values[0].(*ast.BasicLit)
+	if got, want := fmt.Sprint(indexes), "[0 2]"; got != want {
+		t.Errorf("got the indexes of the parts %s, want %s", got, want)
+	}
+	if got, want := partNames(lit.Extra.Parts), "x z y"; got != want {
+		t.Errorf("got parts %s, want %s", got, want)
+	}
+}
+
+func partNames(parts []any) string {
+	var names []string
+	for _, part := range parts {
+		if ident, ok := part.(*ast.Ident); ok {
+			names = append(names, ident.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// TestRewriteMatrixRows checks the rewriting of the elements of the rows
+// of a matrix literal, which the parser does not produce yet.
+func TestRewriteMatrixRows(t *testing.T) {
+	lits := func(values ...string) []ast.Expr {
+		var elts []ast.Expr
+		for _, v := range values {
+			elts = append(elts, &ast.BasicLit{Kind: token.INT, Value: v})
+		}
+		return elts
+	}
+	lit := func(v string) *ast.BasicLit {
+		return &ast.BasicLit{Kind: token.INT, Value: v}
+	}
+	for _, test := range []struct {
+		name    string
+		rewrite func(c *astutil.Cursor, value string)
+		fields  string // the fields and indexes of the elements, as rewritten
+		want    string
+	}{
+		{"delete", func(c *astutil.Cursor, v string) {
+			if v == "2" || v == "5" {
+				c.Delete()
+			}
+		}, "Elts[0][0]=1 Elts[0][1]=2 Elts[0][1]=3 Elts[1][0]=4 Elts[1][1]=5 Elts[1][1]=6", "1, 3; 4, 6"},
+		{"replace", func(c *astutil.Cursor, v string) {
+			if v == "1" || v == "6" {
+				c.Replace(lit(v + v))
+			}
+		}, "Elts[0][0]=1 Elts[0][1]=2 Elts[0][2]=3 Elts[1][0]=4 Elts[1][1]=5 Elts[1][2]=6", "11, 2, 3; 4, 5, 66"},
+		{"insert", func(c *astutil.Cursor, v string) {
+			switch v {
+			case "1":
+				c.InsertBefore(lit("0"))
+			case "6":
+				c.InsertAfter(lit("7"))
+			}
+		}, "Elts[0][0]=1 Elts[0][2]=2 Elts[0][3]=3 Elts[1][0]=4 Elts[1][1]=5 Elts[1][2]=6", "0, 1, 2, 3; 4, 5, 6, 7"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			mat := &ast.MatrixLit{Elts: [][]ast.Expr{lits("1", "2", "3"), lits("4", "5", "6")}}
+			var fields []string
+			astutil.Apply(mat, func(c *astutil.Cursor) bool {
+				if c.Parent() == mat {
+					v := c.Node().(*ast.BasicLit).Value
+					fields = append(fields, fmt.Sprintf("%s[%d]=%s", c.Name(), c.Index(), v))
+					test.rewrite(c, v)
+				}
+				return true
+			}, nil)
+			if got := strings.Join(fields, " "); got != test.fields {
+				t.Errorf("got fields %s, want %s", got, test.fields)
+			}
+			var rows []string
+			for _, row := range mat.Elts {
+				var values []string
+				for _, e := range row {
+					values = append(values, e.(*ast.BasicLit).Value)
+				}
+				rows = append(rows, strings.Join(values, ", "))
+			}
+			if got := strings.Join(rows, "; "); got != test.want {
+				t.Errorf("got matrix [%s], want [%s]", got, test.want)
+			}
+		})
+	}
+}
+
+var sink ast.Node
+
+func BenchmarkRewrite(b *testing.B) {
+	for _, test := range rewriteTests {
+		b.Run(test.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				fset := token.NewFileSet()
+				f, err := parser.ParseFile(fset, test.name, test.orig, parser.ParseComments)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				sink = astutil.Apply(f, test.pre, test.post)
+			}
+		})
+	}
+}
@@ -64,6 +64,16 @@ func TestMarker(t *testing.T) {
 				"Bool":          {true},
 			},
 		},
+		{
+			filename:    "testdata/test.spx",
+			expectNotes: 4,
+			expectMarkers: map[string]string{
+				"αSimpleMarker": "α",
+				"StringMarker":  "\"hello\"",
+				"LineComment":   "someFunc",
+				"NonIdentifier": "+",
+			},
+		},
 		{
 			filename:    "testdata/go.fake.mod",
 			expectNotes: 2,
@@ -72,6 +82,14 @@ func TestMarker(t *testing.T) {
 				"βMarker": "require golang.org/modfile v0.0.0",
 			},
 		},
+		{
+			filename:    "testdata/gop.fake.mod",
+			expectNotes: 2,
+			expectMarkers: map[string]string{
+				"αMarker": "αGameα",
+				"βMarker": "class .spx Sprite",
+			},
+		},
 	} {
 		t.Run(tt.filename, func(t *testing.T) {
 			content, err := ioutil.ReadFile(tt.filename)
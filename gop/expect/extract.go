@@ -60,8 +60,17 @@ func Parse(fset *token.FileSet, filename string, content []byte) ([]*Note, error
 			return nil, err
 		}
 		return ExtractGop(fset, file)
+	case ".spx", ".gmx", ".gsh":
+		// goxls: the classfiles Go+ knows without a gop.mod file.
+		return ParseClass(fset, filename, content, nil)
 	case ".mod":
-		file, err := modfile.Parse(filename, content, nil)
+		parse := modfile.Parse
+		if strings.HasPrefix(filepath.Base(filename), "gop.") {
+			// goxls: a gop.mod file has directives, such as project and
+			// class, that a go.mod file has not.
+			parse = modfile.ParseLax
+		}
+		file, err := parse(filename, content, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -81,6 +90,30 @@ func Parse(fset *token.FileSet, filename string, content []byte) ([]*Note, error
 	return nil, nil
 }
 
+// ParseClass collects all the notes present in a Go+ classfile, as Parse.
+// classKind reports whether filename is a classfile, and whether it is the
+// file of a project or of a work class, as the ClassKind method of the
+// module registering the classfiles in its gop.mod file. If it is nil, the
+// classfiles are those Go+ knows by default, such as .spx and .gmx files.
+//
+// The positions of the notes are those in the classfile, although the Go+
+// compiler moves its statements into the methods of the class.
+func ParseClass(fset *token.FileSet, filename string, content []byte, classKind func(fname string) (isProj, ok bool)) ([]*Note, error) {
+	var src interface{}
+	if content != nil {
+		src = content
+	}
+	conf := gopparser.Config{
+		ClassKind: classKind,
+		Mode:      gopparser.ParseComments | gopparser.AllErrors,
+	}
+	file, err := gopparser.ParseEntry(fset, filename, src, conf)
+	if file == nil {
+		return nil, err
+	}
+	return ExtractGop(fset, file)
+}
+
 // extractMod collects all the notes present in a go.mod file.
 // Each comment whose text starts with @ is parsed as a comma-separated
 // sequence of notes.
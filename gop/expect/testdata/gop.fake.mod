@@ -0,0 +1,8 @@
+// This file is named gop.fake.mod so it does not register classfiles of a
+// real module.
+
+gop 1.2
+
+project .gmx αGameα example.com/game //@mark(αMarker, "αGameα")
+
+class .spx Sprite //@mark(βMarker, "class .spx Sprite")
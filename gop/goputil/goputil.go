@@ -4,6 +4,13 @@
 
 package goputil
 
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/mod/gopmod"
+)
+
 type Kind int
 
 const (
@@ -22,6 +29,34 @@ func FileKind(fext string) Kind {
 	return FileUnknown
 }
 
+// ModFileKind returns the kind of the file fname of a package of the
+// module mod, whose classfiles also include those registered by its gop.mod.
+func ModFileKind(mod *gopmod.Module, fname string) Kind {
+	if kind := FileKind(filepath.Ext(fname)); kind != FileUnknown {
+		return kind
+	}
+	if _, ok := mod.ClassKind(fname); ok {
+		return FileGopClass
+	}
+	return FileUnknown
+}
+
 func Exts() string {
 	return "gop,spx,rdx,gox,gmx"
 }
+
+// IsAutogen reports whether fname is the base name of a file of the Go
+// code generated by gop (gop_autogen*.go).
+func IsAutogen(fname string) bool {
+	return strings.HasPrefix(fname, "gop_autogen") && strings.HasSuffix(fname, ".go")
+}
+
+// GenGoFiles returns the files generated by gop among files.
+func GenGoFiles(files []string) (ret []string) {
+	for _, file := range files {
+		if IsAutogen(filepath.Base(file)) {
+			ret = append(ret, file)
+		}
+	}
+	return
+}
@@ -39,7 +39,10 @@ var (
 	onceInit sync.Once
 )
 
-func lookupCmd(cmd string) string {
+// LookupCmd returns the path of the executable cmd, searching PATH, GOBIN
+// and the bin directories of GOPATH in turn. If cmd is not found, it is
+// returned as is.
+func LookupCmd(cmd string) string {
 	if bin, err := exec.LookPath(cmd); err == nil {
 		return bin
 	}
@@ -61,7 +64,7 @@ func lookupCmd(cmd string) string {
 
 func Get() langserver.Client {
 	onceInit.Do(func() {
-		cmd := lookupCmd("gop")
+		cmd := LookupCmd("gop")
 		ls = langserver.ServeAndDial(nil, cmd, "serve", "-v")
 	})
 	return ls
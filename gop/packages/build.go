@@ -0,0 +1,314 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"bytes"
+	"go/build"
+	"go/build/constraint"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/gop/goputil"
+)
+
+// A buildContext selects the Go+ files of the packages as the go command
+// selects the Go files: from the overlay of a Config, if there, by the
+// GOOS and GOARCH suffixes of their names and by their build constraints.
+type buildContext struct {
+	build.Context
+
+	// overlay maps the absolute paths of the files to their contents,
+	// including the Go files generated from the Go+ files in the overlay.
+	overlay map[string][]byte
+}
+
+func newBuildContext(cfg *Config) *buildContext {
+	ctxt := build.Default
+	for _, kv := range cfg.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		switch k {
+		case "GOOS":
+			ctxt.GOOS = v
+		case "GOARCH":
+			ctxt.GOARCH = v
+		case "CGO_ENABLED":
+			ctxt.CgoEnabled = v == "1"
+		case "GOPATH":
+			ctxt.GOPATH = v
+		}
+	}
+	ctxt.BuildTags = buildTags(cfg.BuildFlags)
+	var overlay map[string][]byte
+	if len(cfg.Overlay) > 0 {
+		overlay = make(map[string][]byte, len(cfg.Overlay))
+		for file, contents := range cfg.Overlay {
+			overlay[filepath.Clean(file)] = contents
+		}
+	}
+	return &buildContext{ctxt, overlay}
+}
+
+// buildTags returns the tags of the -tags flag of buildFlags.
+func buildTags(buildFlags []string) (tags []string) {
+	for i := 0; i < len(buildFlags); i++ {
+		flag := strings.TrimPrefix(buildFlags[i], "-")
+		var list string
+		switch {
+		case flag == "-tags" || flag == "tags":
+			if i+1 < len(buildFlags) {
+				i++
+				list = buildFlags[i]
+			}
+		case strings.HasPrefix(flag, "-tags="), strings.HasPrefix(flag, "tags="):
+			_, list, _ = strings.Cut(flag, "=")
+		default:
+			continue
+		}
+		// The tags were separated by spaces before Go 1.13.
+		tags = strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return
+}
+
+// readFile returns the contents of file, from the overlay if it is there.
+func (p *buildContext) readFile(file string) ([]byte, error) {
+	if contents, ok := p.overlay[file]; ok {
+		return contents, nil
+	}
+	return os.ReadFile(file)
+}
+
+// readDir returns the sorted names of the files of dir, on disk and in the
+// overlay.
+func (p *buildContext) readDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil && !p.hasOverlay(dir) {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	for file := range p.overlay {
+		if filepath.Dir(file) == dir {
+			if name := filepath.Base(file); !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// addOverlay adds file, with its contents, to the overlay.
+func (p *buildContext) addOverlay(file string, contents []byte) {
+	if p.overlay == nil {
+		p.overlay = make(map[string][]byte)
+	}
+	p.overlay[file] = contents
+}
+
+// hasGopOverlay reports whether there are Go+ files in the overlay.
+func (p *buildContext) hasGopOverlay() bool {
+	for file := range p.overlay {
+		if goputil.FileKind(filepath.Ext(file)) != goputil.FileUnknown {
+			return true
+		}
+	}
+	return false
+}
+
+// hasOverlay reports whether there are Go+ files of dir in the overlay.
+func (p *buildContext) hasOverlay(dir string) bool {
+	dir = filepath.Clean(dir)
+	for file := range p.overlay {
+		if filepath.Dir(file) == dir && goputil.FileKind(filepath.Ext(file)) != goputil.FileUnknown {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the filter of the files of dir for the Go+ compiler.
+func (p *buildContext) filter(dir string) func(fs.FileInfo) bool {
+	return func(fi fs.FileInfo) bool {
+		src, err := p.readFile(filepath.Join(dir, fi.Name()))
+		return err != nil || p.matchFile(fi.Name(), src)
+	}
+}
+
+// matchFile reports whether the file name, whose source is src, is built:
+// whether its GOOS and GOARCH suffixes and its build constraints are
+// satisfied.
+func (p *buildContext) matchFile(name string, src []byte) bool {
+	return p.goodOSArchFile(name) && p.match(src)
+}
+
+// goodOSArchFile reports whether the GOOS and GOARCH suffixes of the file
+// name, as in name_$(GOOS)_$(GOARCH)_test.gop, are satisfied, as go/build.
+func (p *buildContext) goodOSArchFile(name string) bool {
+	name, _, _ = strings.Cut(name, ".")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return true
+	}
+	l := strings.Split(name[i:], "_") // ignore everything before the first _
+	if n := len(l); n > 0 && l[n-1] == "test" {
+		l = l[:n-1]
+	}
+	n := len(l)
+	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return p.matchTag(l[n-1]) && p.matchTag(l[n-2])
+	}
+	if n >= 1 && (knownOS[l[n-1]] || knownArch[l[n-1]]) {
+		return p.matchTag(l[n-1])
+	}
+	return true
+}
+
+// match reports whether the build constraints of the source file src, in
+// its //go:build lines, are satisfied.
+func (p *buildContext) match(src []byte) bool {
+	for len(src) > 0 {
+		var line []byte
+		line, src, _ = bytes.Cut(src, []byte("\n"))
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if !bytes.HasPrefix(line, []byte("//")) {
+			break // the constraints come before the code
+		}
+		if constraint.IsGoBuild(string(line)) {
+			if x, err := constraint.Parse(string(line)); err == nil {
+				return x.Eval(p.matchTag)
+			}
+		}
+	}
+	return true
+}
+
+// matchTag reports whether the build tag name is satisfied, as go/build.
+func (p *buildContext) matchTag(name string) bool {
+	ctxt := &p.Context
+	switch {
+	case name == ctxt.GOOS, name == ctxt.GOARCH, name == ctxt.Compiler:
+		return true
+	case name == "cgo":
+		return ctxt.CgoEnabled
+	case name == "unix":
+		return unixOS[ctxt.GOOS]
+	case name == "linux":
+		return ctxt.GOOS == "android"
+	case name == "solaris":
+		return ctxt.GOOS == "illumos"
+	case name == "darwin":
+		return ctxt.GOOS == "ios"
+	}
+	return contains(ctxt.BuildTags, name) || contains(ctxt.ToolTags, name) || contains(ctxt.ReleaseTags, name)
+}
+
+// unixOS is the set of GOOS values matched by the "unix" build tag.
+var unixOS = map[string]bool{
+	"aix":       true,
+	"android":   true,
+	"darwin":    true,
+	"dragonfly": true,
+	"freebsd":   true,
+	"hurd":      true,
+	"illumos":   true,
+	"ios":       true,
+	"linux":     true,
+	"netbsd":    true,
+	"openbsd":   true,
+	"solaris":   true,
+}
+
+// knownOS and knownArch are the GOOS and GOARCH values that go/build
+// recognizes as file name suffixes.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true,
+		"freebsd": true, "hurd": true, "illumos": true, "ios": true, "js": true,
+		"linux": true, "nacl": true, "netbsd": true, "openbsd": true, "plan9": true,
+		"solaris": true, "wasip1": true, "windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true,
+		"arm64": true, "arm64be": true, "loong64": true, "mips": true, "mipsle": true,
+		"mips64": true, "mips64le": true, "mips64p32": true, "mips64p32le": true,
+		"ppc": true, "ppc64": true, "ppc64le": true, "riscv": true, "riscv64": true,
+		"s390": true, "s390x": true, "sparc": true, "sparc64": true, "wasm": true,
+	}
+)
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// overlayFS is the file system of the Go+ compiler with the files of the
+// overlay.
+type overlayFS struct {
+	*buildContext
+}
+
+func (p overlayFS) ReadDir(dirname string) ([]fs.DirEntry, error) {
+	names, err := p.readDir(dirname)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]fs.DirEntry, len(names))
+	for i, name := range names {
+		entries[i] = overlayEntry{p.buildContext, filepath.Join(dirname, name)}
+	}
+	return entries, nil
+}
+
+func (p overlayFS) ReadFile(filename string) ([]byte, error) {
+	return p.readFile(filename)
+}
+
+func (p overlayFS) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (p overlayFS) Base(filename string) string {
+	return filepath.Base(filename)
+}
+
+func (p overlayFS) Abs(path string) (string, error) {
+	return filepath.Abs(path)
+}
+
+// overlayEntry is a file of the overlayFS.
+type overlayEntry struct {
+	ctx  *buildContext
+	file string
+}
+
+func (p overlayEntry) Name() string               { return filepath.Base(p.file) }
+func (p overlayEntry) IsDir() bool                { return false }
+func (p overlayEntry) Type() fs.FileMode          { return 0 }
+func (p overlayEntry) Info() (fs.FileInfo, error) { return p, nil }
+func (p overlayEntry) Mode() fs.FileMode          { return 0644 }
+func (p overlayEntry) ModTime() time.Time         { return time.Time{} }
+func (p overlayEntry) Sys() any                   { return nil }
+
+func (p overlayEntry) Size() int64 {
+	src, _ := p.ctx.readFile(p.file)
+	return int64(len(src))
+}
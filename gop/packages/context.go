@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/goplus/mod"
 	"github.com/goplus/mod/gopmod"
 	"golang.org/x/tools/go/packages"
 )
@@ -84,3 +85,17 @@ func (p *Context) LoadMod(mod *Module) *gopmod.Module {
 	}
 	return gopmod.Default
 }
+
+// LoadModOf loads the Go+ module of the package in dir, from the go.mod
+// file above it, for the packages whose module is unknown: those loaded
+// without NeedModule or in GOPATH mode.
+func (p *Context) LoadModOf(dir string) *gopmod.Module {
+	if dir != "" {
+		if gomod, err := mod.GOMOD(dir); err == nil {
+			if ret, err := p.LoadModFrom(gomod); err == nil {
+				return ret
+			}
+		}
+	}
+	return gopmod.Default
+}
@@ -5,15 +5,60 @@
 package packages
 
 import (
+	"bytes"
 	"context"
+	"go/types"
+	"io/fs"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 
+	"github.com/goplus/gogen"
+	"github.com/goplus/gop"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/cl"
 	"github.com/goplus/gop/env"
+	"github.com/goplus/gop/parser"
+	"github.com/goplus/gop/scanner"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/c2go"
+	"github.com/goplus/gop/x/gopprojs"
+	"github.com/goplus/mod/gopmod"
+	"github.com/qiniu/x/errors"
 	"golang.org/x/tools/gop/langserver"
 )
 
+// A GenGoMode specifies how LoadEx generates the Go code (gop_autogen.go
+// files) of the Go+ packages before loading them.
+type GenGoMode int
+
+const (
+	// GenGoCmd generates the Go code by the gop command, if it is
+	// installed, ignoring the errors. This is the default.
+	//
+	// The gop command generates the Go code from the files on disk: if the
+	// Overlay of the Config has Go+ files, the Go code is generated as by
+	// GenGoInProcess instead.
+	GenGoCmd GenGoMode = iota
+
+	// GenGoInProcess generates the Go code in-process by the Go+ compiler,
+	// whether or not the gop command is installed. The errors of the Go+
+	// packages are reported in their Errors. The packages that they import
+	// are listed as by the Config, with its Dir, Env and BuildFlags, from
+	// their export data: unlike the gop command, it writes no cache of them.
+	//
+	// The Go+ files are those of the GopFiles of the packages: the Go code
+	// of a package with Go+ files in the Overlay of the Config is added to
+	// the overlay rather than written.
+	GenGoInProcess
+
+	// GenGoNone does not generate the Go code, which is up to date.
+	GenGoNone
+)
+
 var (
 	gopInstalled = env.Installed()
 )
@@ -32,6 +77,265 @@ func GenGo(patternIn ...string) (patternOut []string, err error) {
 	return
 }
 
+// genGoInProcess generates the Go code of the Go+ packages matched by
+// patternIn, relative to the Dir of cfg, by the Go+ compiler. It returns
+// the patterns to load and the errors of the Go+ packages by package
+// directory.
+//
+// Only the packages of the module of the Dir, or of the GOPATH without a
+// module, are generated: the Go code of the external ones is published
+// along with them. The packages they import are listed as by the Config,
+// so that the generation does not depend on the environment of the
+// process. The Go+ files are selected by ctx, and the Go code of the
+// packages with Go+ files in the overlay of ctx is added to it rather
+// than written.
+func genGoInProcess(cfg *Config, gopCfg *GopConfig, patternIn []string, ctx *buildContext) (patternOut []string, errs map[string][]Error) {
+	pattern, patternOut := buildPattern(patternIn)
+	// Unlike the gop command, which is not given the packages of the
+	// standard library, the packages are looked up here: those that
+	// buildPattern takes for standard ones, as the packages of a GOPATH
+	// tree, are generated too.
+	for _, v := range patternIn {
+		if !strings.HasPrefix(v, "file=") && !contains(pattern, v) {
+			pattern = append(pattern, v)
+		}
+	}
+	if debugVerbose {
+		log.Println("GenGo (in-process):", pattern, "in:", patternIn, "out:", patternOut)
+	}
+	if len(pattern) == 0 {
+		return
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	dir, _ = filepath.Abs(dir)
+	projs, err := gopprojs.ParseAll(pattern...)
+	if err != nil { // the patterns are reported invalid when loading
+		return
+	}
+	mod, err := gop.LoadMod(dir)
+	if err != nil {
+		errs = map[string][]Error{dir: genGoErrors(nil, err)}
+		return
+	}
+
+	errs = make(map[string][]Error)
+	fset := token.NewFileSet()
+	done := make(map[string]bool)
+	var imp *genGoImporter
+	gen := func(pkgDir string) {
+		if done[pkgDir] {
+			return
+		}
+		done[pkgDir] = true
+		if err := genGoPkg(pkgDir, modOf(mod, pkgDir), fset, imp, ctx, gopCfg.GenGoStub); err != nil {
+			errs[pkgDir] = append(errs[pkgDir], genGoErrors(fset, err)...)
+		}
+	}
+	imp = newGenGoImporter(cfg, ctx, mod, gen)
+	for _, proj := range projs {
+		var pkgDir string
+		var recursively bool
+		switch v := proj.(type) {
+		case *gopprojs.DirProj:
+			pkgDir, recursively = cutRecursive(v.Dir)
+			if !filepath.IsAbs(pkgDir) {
+				pkgDir = filepath.Join(dir, pkgDir)
+			}
+		case *gopprojs.PkgPathProj:
+			pkgPath, rec := cutRecursive(v.Path)
+			if pkgDir = imp.localDir(pkgPath); pkgDir == "" {
+				continue
+			}
+			recursively = rec
+		default:
+			continue
+		}
+		if recursively {
+			walkPkgDirs(pkgDir, gen)
+		} else {
+			gen(pkgDir)
+		}
+	}
+	// The packages whose Go code failed to be generated are loaded even if
+	// they have no Go files, to report their errors.
+	dirs := make([]string, 0, len(errs))
+	for pkgDir := range errs {
+		dirs = append(dirs, pkgDir)
+	}
+	sort.Strings(dirs)
+	patternOut = append(patternOut, dirs...)
+	return
+}
+
+// modOf returns the module of the Go+ package in dir, of the module mod:
+// without a module, in GOPATH mode, it is the module of the go.mod file
+// above dir, if any, which the go command ignores but whose gop.mod file
+// registers the classfiles of the package.
+func modOf(mod *gopmod.Module, dir string) *gopmod.Module {
+	if !mod.HasModfile() {
+		if ret, err := gop.LoadMod(dir); err == nil {
+			return ret
+		}
+	}
+	return mod
+}
+
+// genGoPkg generates the Go code of the Go+ package in dir, of the module
+// mod, importing its packages by imp. It is gop.GenGo but for the build
+// context ctx: the Go code of a package with Go+ files in its overlay is
+// added to it rather than written.
+//
+// If stubOnErr is set and the Go code fails to be generated, Go code that
+// only declares the package is added to the overlay of ctx instead, leaving
+// the Go code on disk as it is, and no error is returned.
+func genGoPkg(dir string, mod *gopmod.Module, fset *token.FileSet, imp types.Importer, ctx *buildContext, stubOnErr bool) error {
+	pkgs, err := parser.ParseFSDir(fset, overlayFS{ctx}, dir, parser.Config{
+		ClassKind: mod.ClassKind,
+		Filter:    ctx.filter(dir),
+		Mode:      parser.ParseComments | parser.SaveAbsFile,
+	})
+	if err == nil {
+		err = genGoFiles(dir, pkgs, mod, fset, imp, ctx)
+	}
+	if err != nil && stubOnErr {
+		for name := range pkgs {
+			if !strings.HasSuffix(name, "_test") {
+				ctx.addOverlay(filepath.Join(dir, "gop_autogen.go"), []byte(gogen.GeneratedHeader+"package "+name+"\n"))
+				return nil
+			}
+		}
+	}
+	return err
+}
+
+// genGoFiles generates the Go code of the Go+ packages pkgs of dir.
+func genGoFiles(dir string, pkgs map[string]*ast.Package, mod *gopmod.Module, fset *token.FileSet, imp types.Importer, ctx *buildContext) (err error) {
+	relBase := mod.Root()
+	if !mod.HasModfile() {
+		relBase = dir
+	}
+	clConf := &cl.Config{
+		Fset:         fset,
+		RelativeBase: relBase,
+		Importer:     imp,
+		LookupClass:  mod.LookupClass,
+		LookupPub:    c2go.LookupPub(mod),
+	}
+	var out, test *gogen.Package
+	for name, pkg := range pkgs {
+		if strings.HasSuffix(name, "_test") {
+			if test, err = cl.NewPackage("", pkg, clConf); err != nil {
+				return err
+			}
+		} else if len(pkg.Files) > 0 {
+			if out != nil {
+				return gop.ErrMultiPackges
+			}
+			if out, err = cl.NewPackage("", pkg, clConf); err != nil {
+				return err
+			}
+		}
+	}
+	if out == nil {
+		return nil // no Go+ files
+	}
+	for _, gen := range []struct {
+		pkg   *gogen.Package
+		fname string
+		file  []string
+	}{
+		{out, "gop_autogen.go", nil},
+		{out, "gop_autogen_test.go", []string{"_test"}},
+		{test, "gop_autogen2_test.go", []string{"_test"}},
+	} {
+		if gen.pkg == nil {
+			continue
+		}
+		var b bytes.Buffer
+		b.WriteString(gogen.GeneratedHeader)
+		if err := gen.pkg.WriteTo(&b, gen.file...); err == syscall.ENOENT {
+			continue // no such file, as gop_autogen_test.go without tests
+		} else if err != nil {
+			return err
+		}
+		if err := writeGoFile(ctx, dir, gen.fname, b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeGoFile writes the generated Go file fname of dir, or adds it to the
+// overlay of ctx if dir has Go+ files in it.
+func writeGoFile(ctx *buildContext, dir, fname string, data []byte) error {
+	file := filepath.Join(dir, fname)
+	if ctx.hasOverlay(dir) {
+		ctx.addOverlay(file, data)
+		return nil
+	}
+	return os.WriteFile(file, data, 0666)
+}
+
+func cutRecursive(pattern string) (string, bool) {
+	if strings.HasSuffix(pattern, "/...") {
+		return pattern[:len(pattern)-4], true
+	}
+	return pattern, false
+}
+
+// walkPkgDirs calls fn for root and the directories under it that the gop
+// command generates for root/...: those whose name does not start with '_'
+// and that are not in another module. These are the rules of the walk of
+// gop.GenGoEx, which gop does not export, so that the same packages are
+// generated in-process as by the gop command.
+func walkPkgDirs(root string, fn func(dir string)) {
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), "_") || (path != root && hasMod(path)) {
+			return filepath.SkipDir
+		}
+		fn(path)
+		return nil
+	})
+}
+
+// hasMod reports whether dir is the root of a module.
+func hasMod(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
+
+// genGoErrors converts an error of the Go+ compiler, whose positions are
+// in fset, to package errors.
+func genGoErrors(fset *token.FileSet, err error) (ret []Error) {
+	switch err := errors.Err(err).(type) {
+	case errors.List:
+		for _, e := range err {
+			ret = append(ret, genGoErrors(fset, e)...)
+		}
+	case scanner.ErrorList:
+		for _, e := range err {
+			ret = append(ret, Error{Pos: e.Pos.String(), Msg: e.Msg, Kind: ParseError})
+		}
+	case *gogen.CodeError:
+		ret = append(ret, Error{Pos: fset.Position(err.Pos).String(), Msg: err.Msg, Kind: TypeError})
+	case *gogen.ImportError:
+		pos := "-"
+		if err.Pos.IsValid() {
+			pos = fset.Position(err.Pos).String()
+		}
+		ret = append(ret, Error{Pos: pos, Msg: err.Err.Error(), Kind: TypeError})
+	default:
+		ret = append(ret, Error{Pos: "-", Msg: err.Error(), Kind: UnknownError})
+	}
+	return
+}
+
 type none = struct{}
 
 func buildPattern(pattern []string) (gopPattern []string, allPattern []string) {
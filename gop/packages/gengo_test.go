@@ -0,0 +1,166 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gop/packages"
+)
+
+func TestGenGoInProcess(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.gop":  "package a\n\nfunc F() int {\n\treturn 1\n}\n",
+		"b/b.gop":  "package b\n\nfunc G() {\n\tprintln(\n}\n",
+		"c/c.go":   "package c\n\nfunc H() {}\n",
+		"c/c.gop":  "package c\n\nfunc K() {\n\tH(1)\n}\n",
+		"_x/x.gop": "package x\n\nfunc X() {\n\tundefined()\n}\n",
+		"n/go.mod": "module example.com/n\n",
+		"n/n.gop":  "package n\n\nfunc N() {\n\tundefined()\n}\n",
+	})
+
+	cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	pkgs, err := packages.LoadEx(gop, cfg, "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"example.com/m/a": "",
+		"example.com/m/b": filepath.Join(dir, "b", "b.gop") + ":5:1: expected operand",
+		"example.com/m/c": filepath.Join(dir, "c", "c.gop") + ":4:2: too many arguments",
+	}
+	for _, pkg := range pkgs {
+		w, ok := want[pkg.PkgPath]
+		if !ok {
+			t.Errorf("unexpected package %s", pkg.PkgPath)
+			continue
+		}
+		delete(want, pkg.PkgPath)
+		if w == "" {
+			if len(pkg.Errors) > 0 || len(pkg.GopFiles) != 1 || pkg.Types.Scope().Lookup("F") == nil {
+				t.Errorf("%s: got errors %v and Go+ files %v, want F of a.gop", pkg.PkgPath, pkg.Errors, pkg.GopFiles)
+			}
+			continue
+		}
+		if len(pkg.Errors) == 0 || !strings.HasPrefix(pkg.Errors[0].Error(), w) {
+			t.Errorf("%s: got errors %v, want %q first", pkg.PkgPath, pkg.Errors, w)
+		}
+	}
+	for path := range want {
+		t.Errorf("package %s not loaded", path)
+	}
+	// The packages of another module are not generated.
+	if _, err := os.Stat(filepath.Join(dir, "n", "gop_autogen.go")); err == nil {
+		t.Errorf("the Go code of n, of another module, was generated")
+	}
+}
+
+// TestGenGoInProcessNoGopRoot checks that the Go code is generated
+// in-process where Go+ is not installed, as on CI images.
+func TestGenGoInProcessNoGopRoot(t *testing.T) {
+	t.Setenv("GOPROOT", "")
+	dir := writeModule(t, map[string]string{
+		"a/a.gop": "package a\n\nimport \"strconv\"\n\nfunc F() string {\n\treturn strconv.itoa(1)\n}\n",
+	})
+	cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	pkgs, err := packages.LoadEx(gop, cfg, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 || pkgs[0].Types.Scope().Lookup("F") == nil {
+		t.Errorf("loading failed")
+	}
+}
+
+// TestGenGoInProcessBuiltins checks that the Go+ builtins are bound to
+// complete packages, though the Go code only imports some of them, and that
+// the dependencies of its imports read from their export data are not.
+func TestGenGoInProcessBuiltins(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.gop": "package a\n\nfunc F() {\n\tprintln \"F\"\n}\n",
+	})
+	cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	pkgs, err := packages.LoadEx(gop, cfg, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 || pkgs[0].GopTypesInfo == nil {
+		t.Errorf("loading failed")
+	}
+}
+
+// TestGenGoInProcessGOPATH checks that the Go code of the Go+ packages of a
+// GOPATH tree is generated in the environment of the Config, not in that of
+// the process, so that tests may load such trees in parallel.
+func TestGenGoInProcessGOPATH(t *testing.T) {
+	t.Parallel()
+	dir := writeTree(t, map[string]string{
+		"src/a/a.gop": "package a\n\nimport \"b\"\n\nfunc F() int {\n\treturn b.Bar(1)\n}\n",
+		"src/b/b.gop": "package b\n\nfunc Bar(x int) int {\n\treturn x * 2\n}\n",
+	})
+
+	cfg := &packages.Config{
+		Dir:  dir,
+		Mode: packages.LoadSyntax,
+		Env:  append(os.Environ(), "GOPATH="+dir, "GO111MODULE=off"),
+	}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	pkgs, err := packages.LoadEx(gop, cfg, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("got %d packages, want 1", len(pkgs))
+	}
+	if pkg := pkgs[0]; len(pkg.Errors) > 0 || len(pkg.GopFiles) != 1 || pkg.Types.Scope().Lookup("F") == nil {
+		t.Errorf("%s: got errors %v and Go+ files %v, want F of a.gop", pkg.PkgPath, pkg.Errors, pkg.GopFiles)
+	}
+}
+
+func TestGenGoStub(t *testing.T) {
+	t.Parallel()
+	dir := writeTree(t, map[string]string{
+		"go.mod": "module example.com/stub\n\ngo 1.18\n",
+		"a.gop":  "func F() int {\n\treturn undefined\n}\n\nfunc G() int {\n\treturn 1\n}\n",
+	})
+	// The Go code generated before the error is not overwritten by the stub.
+	autogen := filepath.Join(dir, "gop_autogen.go")
+	const old = "package main\n\nfunc Old() {}\n"
+	if err := os.WriteFile(autogen, []byte(old), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &packages.Config{
+		Dir:  dir,
+		Mode: packages.LoadSyntax,
+	}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess, GenGoStub: true}
+	pkgs, err := packages.LoadEx(gop, cfg, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 {
+		t.Fatalf("got %d packages, want 1", len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.TypeErrors) != 1 || !strings.Contains(pkg.TypeErrors[0].Msg, "undefined") {
+		t.Errorf("got type errors %v, want undefined", pkg.TypeErrors)
+	}
+	if pkg.Types == nil || pkg.Types.Scope().Lookup("G") == nil || pkg.GopTypesInfo == nil {
+		t.Errorf("G of a.gop is not type-checked")
+	}
+	if pkg.Types != nil && pkg.Types.Scope().Lookup("Old") != nil {
+		t.Errorf("got Old of the Go code on disk, want the stub")
+	}
+	if data, err := os.ReadFile(autogen); err != nil || string(data) != old {
+		t.Errorf("got %s on disk (%v), want it untouched", data, err)
+	}
+}
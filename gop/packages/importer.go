@@ -0,0 +1,172 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gop/env"
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/go/gcexportdata"
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/gop/goputil"
+)
+
+// A genGoImporter imports the packages of the Go+ packages whose Go code
+// is generated in-process, from their export data. The packages are listed
+// by the go command as for the Config, with its Dir, Env, BuildFlags and
+// the overlay of the build context: unlike the importer of the gop command,
+// it does not depend on the environment of the process.
+//
+// The Go code of the Go+ packages of the module, or of the GOPATH, that
+// are imported is generated before listing them.
+type genGoImporter struct {
+	cfg *Config
+	ctx *buildContext
+	mod *gopmod.Module
+
+	// genGo generates the Go code of the Go+ package in dir, once.
+	genGo func(dir string)
+
+	fset *token.FileSet
+	pkgs map[string]*types.Package // shared by all the export data
+
+	// gopRoot is the root directory of Go+, looked up on the first import
+	// of a package of Go+ itself, or "" if it is not installed.
+	gopRoot     string
+	gopRootOnce bool
+}
+
+func newGenGoImporter(cfg *Config, ctx *buildContext, mod *gopmod.Module, genGo func(dir string)) *genGoImporter {
+	return &genGoImporter{
+		cfg: cfg, ctx: ctx, mod: mod, genGo: genGo,
+		fset: token.NewFileSet(),
+		pkgs: make(map[string]*types.Package),
+	}
+}
+
+// Import imports the package pkgPath.
+func (p *genGoImporter) Import(pkgPath string) (*types.Package, error) {
+	if pkgPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg := p.pkgs[pkgPath]; pkg != nil && pkg.Complete() {
+		return pkg, nil
+	}
+	cfg := &packages.Config{
+		Mode:       NeedName | NeedExportFile,
+		Context:    p.cfg.Context,
+		Dir:        p.cfg.Dir,
+		Env:        p.cfg.Env,
+		BuildFlags: p.cfg.BuildFlags,
+		Overlay:    p.ctx.overlay,
+	}
+	if isGopRootPkg(pkgPath) {
+		// The packages of Go+ itself are those of its installation, if
+		// any, in module mode, whether or not the module requires them.
+		// Otherwise they are those the module requires.
+		if gopRoot := p.lookupGopRoot(); gopRoot != "" {
+			env := cfg.Env
+			if env == nil {
+				env = os.Environ()
+			}
+			cfg.Dir, cfg.Env, cfg.Overlay = gopRoot, append(env[:len(env):len(env)], "GO111MODULE=on"), nil
+		}
+	} else if dir := p.localDir(pkgPath); dir != "" {
+		p.genGo(dir)
+	}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("go list %s: %d packages found", pkgPath, len(pkgs))
+	}
+	if pkg := pkgs[0]; pkg.ExportFile == "" {
+		if len(pkg.Errors) > 0 {
+			return nil, pkg.Errors[0]
+		}
+		return nil, fmt.Errorf("no export data for %s", pkgPath)
+	}
+	f, err := os.Open(pkgs[0].ExportFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gcexportdata.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading export data for %s: %v", pkgPath, err)
+	}
+	return gcexportdata.Read(r, p.fset, p.pkgs, pkgPath)
+}
+
+// lookupGopRoot returns the root directory of Go+, or "" if Go+ is not
+// installed. It is looked up once, as by the gop command, which panics if
+// it is not found.
+func (p *genGoImporter) lookupGopRoot() string {
+	if !p.gopRootOnce {
+		p.gopRootOnce = true
+		func() {
+			defer func() {
+				if e := recover(); e != nil && debugVerbose {
+					log.Println("GOPROOT:", e)
+				}
+			}()
+			p.gopRoot = env.GOPROOT()
+		}()
+	}
+	return p.gopRoot
+}
+
+// localDir returns the directory of the Go+ package pkgPath if it is in
+// the module or, without a module, in the GOPATH, and "" otherwise.
+func (p *genGoImporter) localDir(pkgPath string) string {
+	if p.mod.HasModfile() {
+		if p.mod.PkgType(pkgPath) != gopmod.PkgtModule {
+			return ""
+		}
+		pkg, err := p.mod.Lookup(pkgPath)
+		if err != nil || !p.ctx.hasGopFiles(p.mod, pkg.Dir) {
+			return ""
+		}
+		return pkg.Dir
+	}
+	for _, root := range filepath.SplitList(p.ctx.GOPATH) {
+		dir := filepath.Join(root, "src", filepath.FromSlash(pkgPath))
+		if p.ctx.hasGopFiles(modOf(p.mod, dir), dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// isGopRootPkg reports whether pkgPath is a package of Go+ itself, which
+// the generated Go code may import whether or not the module requires it.
+func isGopRootPkg(pkgPath string) bool {
+	for _, mod := range []string{"github.com/goplus/gop", "github.com/qiniu/x"} {
+		if pkgPath == mod || strings.HasPrefix(pkgPath, mod+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasGopFiles reports whether dir has Go+ files of the module mod, on disk
+// or in the overlay.
+func (p *buildContext) hasGopFiles(mod *gopmod.Module, dir string) bool {
+	names, _ := p.readDir(dir)
+	for _, name := range names {
+		if goputil.ModFileKind(mod, name) != goputil.FileUnknown {
+			return true
+		}
+	}
+	return false
+}
@@ -6,15 +6,20 @@ package packages
 
 import (
 	"context"
+	"fmt"
 	goast "go/ast"
+	"go/constant"
 	"go/types"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"strings"
 	"sync"
 
+	"github.com/goplus/gogen"
 	"github.com/goplus/gop"
 	"github.com/goplus/gop/ast"
 	"github.com/goplus/gop/parser"
@@ -114,6 +119,12 @@ const (
 
 	// NeedNongen adds CompiledNongenGoFiles, NongenSyntax.
 	NeedNongen = LoadMode(1 << 30)
+
+	// NeedGopDeps adds the GopSyntax and GopTypesInfo requested by the
+	// LoadMode in the packages in Imports, so that the whole import graph
+	// is type-checked from its Go+ files. It implies NeedImports and NeedDeps,
+	// which add them as well.
+	NeedGopDeps = LoadMode(1 << 29)
 )
 
 const (
@@ -150,10 +161,10 @@ type Config = packages.Config
 type Package struct {
 	packages.Package
 
-	// GopFiles lists the absolute file paths of the package's Go source files.
-	// It may include files that should not be compiled, for example because
-	// they contain non-matching build tags, are documentary pseudo-files such as
-	// unsafe/unsafe.go or builtin/builtin.go, or are subject to cgo preprocessing.
+	// GopFiles lists the absolute file paths of the package's Go+ source files,
+	// on disk or in the Overlay of the Config, whose build constraints (//go:build
+	// lines) are satisfied by the -tags of the BuildFlags and by the GOOS and
+	// GOARCH of the Env of the Config.
 	GopFiles []string
 
 	// CompiledGopFiles lists the absolute file paths of the package's source
@@ -183,8 +194,8 @@ type Package struct {
 	// GopSyntax is the package's syntax trees, for the files listed in CompiledGopFiles.
 	//
 	// The NeedSyntax LoadMode bit populates this field for packages matching the patterns.
-	// If NeedDeps and NeedImports are also set, this field will also be populated
-	// for dependencies.
+	// If NeedDeps and NeedImports, or NeedGopDeps, are also set, this field will
+	// also be populated for dependencies.
 	//
 	// GopSyntax is kept in the same order as CompiledGopFiles, with the caveat that nils are
 	// removed. If parsing returned nil, GopSyntax may be shorter than CompiledGopFiles.
@@ -192,6 +203,9 @@ type Package struct {
 
 	// GopTypesInfo provides type information about the package's syntax trees.
 	// It is set only when GopSyntax is set.
+	//
+	// The Go+ files of the packages are type-checked in parallel, in the
+	// order of their imports, sharing the types.Context of the Context.
 	GopTypesInfo *typesutil.Info
 }
 
@@ -214,6 +228,17 @@ type GopConfig struct {
 	// Context is an opaque packages.Load context.
 	// Contexts are safe for concurrent use.
 	Context *Context
+
+	// GenGo specifies how the Go code of the Go+ packages is generated
+	// before loading them. See GenGoMode.
+	GenGo GenGoMode
+
+	// GenGoStub, with GenGoInProcess, loads the Go+ packages whose Go code
+	// fails to be generated, as those with type errors, all the same: their
+	// Go code only declares the package, in the Overlay rather than on disk,
+	// and they are type-checked from their Go+ files, whose errors are
+	// reported in their Errors.
+	GenGoStub bool
 }
 
 // Load loads and returns the Go/Go+ packages named by the given patterns.
@@ -249,18 +274,38 @@ func Load(cfg *Config, patterns ...string) ([]*Package, error) {
 // proceeding with further analysis. The PrintErrors function is
 // provided for convenient display of all errors.
 func LoadEx(gop *GopConfig, cfg *Config, patterns ...string) ([]*Package, error) {
-	patterns, _ = GenGo(patterns...)
-
 	var conf Config
 	if cfg != nil {
 		conf = *cfg
 	}
+	if gop == nil {
+		gop = new(GopConfig)
+	}
+
+	build := newBuildContext(&conf)
+	genGo := gop.GenGo
+	if genGo == GenGoCmd && build.hasGopOverlay() {
+		genGo = GenGoInProcess // the gop command only sees the files on disk
+	}
+	var genErrs map[string][]Error
+	switch genGo {
+	case GenGoCmd:
+		patterns, _ = GenGo(patterns...)
+	case GenGoInProcess:
+		patterns, genErrs = genGoInProcess(&conf, gop, patterns, build)
+		if build.overlay != nil {
+			conf.Overlay = build.overlay
+		}
+	}
 	if conf.Fset == nil {
 		conf.Fset = token.NewFileSet()
 	}
 	if conf.Mode == 0 {
 		conf.Mode = NeedName | NeedFiles | NeedCompiledGoFiles
 	}
+	if conf.Mode&NeedGopDeps != 0 {
+		conf.Mode |= NeedImports | NeedDeps
+	}
 	pkgs, err := packages.Load(&conf, patterns...)
 	if err != nil {
 		return nil, err
@@ -269,31 +314,62 @@ func LoadEx(gop *GopConfig, cfg *Config, patterns ...string) ([]*Package, error)
 	pkgMap := make(map[*packages.Package]*Package)
 	ret := make([]*Package, len(pkgs))
 
-	var ld *loader
-	if conf.Mode&(NeedSyntax|NeedTypes|NeedTypesInfo) != 0 {
+	ld := &loader{Fset: conf.Fset, Overlay: build.overlay, cfg: &conf, build: build}
+	needSyntax := conf.Mode&(NeedSyntax|NeedTypes|NeedTypesInfo) != 0
+	if needSyntax {
 		if conf.Context == nil {
 			conf.Context = context.Background()
 		}
-		if gop == nil {
-			gop = new(GopConfig)
-		}
-		ctx := gop.Context
-		if ctx == nil {
-			ctx = Default
+		ld.Context = gop.Context
+		if ld.Context == nil {
+			ld.Context = Default
 		}
-		parse := gop.ParseFile
-		if parse == nil {
-			parse = parser.ParseEntry
+		ld.ParseFile = gop.ParseFile
+		if ld.ParseFile == nil {
+			ld.ParseFile = parser.ParseEntry
 		}
-		ld = &loader{conf.Fset, ctx, parse, conf.Overlay, conf.Context}
+		ld.ctx = conf.Context
 	}
 
 	for i, pkg := range pkgs {
 		ret[i] = pkgOf(pkgMap, pkg, ld, conf.Mode)
 	}
+	if needSyntax {
+		ld.loadGopPackages(ret, conf.Mode)
+	}
+	if len(genErrs) > 0 {
+		addGenGoErrors(ret, genErrs)
+	}
 	return ret, nil
 }
 
+// addGenGoErrors adds the errors of the Go code generation to the packages
+// of the directories where they occurred, in front of the errors of the
+// packages that they likely cause.
+func addGenGoErrors(pkgs []*Package, errs map[string][]Error) {
+	Visit(pkgs, nil, func(pkg *Package) {
+		if dir := pkgDir(pkg); dir != "" && len(errs[dir]) > 0 {
+			pkg.Errors = append(errs[dir], pkg.Errors...)
+			delete(errs, dir)
+		}
+	})
+}
+
+// pkgDir returns the directory of pkg, or "" if it is unknown. A package
+// of Go+ files whose Go code failed to be generated has no Go files, but
+// go list still reports its directory.
+func pkgDir(pkg *Package) string {
+	if dir := internal.GetDir(&pkg.Package); dir != "" {
+		return dir
+	}
+	for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles, pkg.GopFiles} {
+		if len(files) > 0 {
+			return filepath.Dir(files[0])
+		}
+	}
+	return ""
+}
+
 func importPkgs(pkgMap map[*packages.Package]*Package, pkgs map[string]*packages.Package, ld *loader, mode LoadMode) map[string]*Package {
 	if len(pkgs) == 0 {
 		return nil
@@ -340,7 +416,9 @@ func pkgOf(pkgMap map[*packages.Package]*Package, pkg *packages.Package, ld *loa
 	for i, file := range pkg.CompiledGoFiles {
 		dir, fname := filepath.Split(file)
 		if isAutogen(fname) { // has Go+ files
-			addGopFiles(ret, ld, dir, mode, isGoTestFile(fname) || hasGoTestFile(pkg.CompiledGoFiles[i+1:]))
+			test := isGoTestFile(fname) || hasGoTestFile(pkg.CompiledGoFiles[i+1:])
+			ld.addGopFiles(ret, dir, test)
+			ld.pkg(ret).test = test
 			if needNongen {
 				initNongen(ret, i)
 			}
@@ -384,8 +462,23 @@ func autogenFiles(ret *Package, test bool) []*goast.File {
 	return files
 }
 
-func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool) {
-	entries, err := os.ReadDir(dir)
+// GopFiles returns the Go+ files of the package pkgName in dir that the
+// packages loaded by cfg would have: those, including the classfiles
+// registered by the gop.mod of their module, whose GOOS and GOARCH
+// suffixes and build constraints are satisfied, along with the test files
+// if test is set. It is for the drivers given the Go files of a package
+// rather than its pattern, as by go vet.
+func GopFiles(cfg *Config, dir, pkgName string, test bool) []string {
+	ld := &loader{build: newBuildContext(cfg)}
+	ret := &Package{Package: packages.Package{Name: pkgName}}
+	ld.addGopFiles(ret, filepath.Clean(dir)+string(filepath.Separator), test)
+	return ret.GopFiles
+}
+
+// addGopFiles adds the Go+ files of dir, on disk or in the overlay, that
+// satisfy the build constraints to ret.
+func (ld *loader) addGopFiles(ret *Package, dir string, test bool) {
+	names, err := ld.build.readDir(filepath.Clean(dir))
 	if err != nil {
 		return
 	}
@@ -393,25 +486,32 @@ func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool)
 	pkgName := ret.Name
 	var mod *gopmod.Module
 	var once sync.Once
-	for _, e := range entries {
-		fname := e.Name()
+	loadMod := func() {
+		once.Do(func() {
+			mod, _ = gop.LoadMod(dir)
+		})
+	}
+	for _, fname := range names {
 		if strings.HasPrefix(fname, "_") {
 			continue
 		}
 		fext := path.Ext(fname)
-		if goputil.FileKind(fext) == goputil.FileUnknown {
+		if fext == ".go" {
 			continue
 		}
+		if goputil.FileKind(fext) == goputil.FileUnknown {
+			// a classfile registered by the gop.mod of the module
+			if loadMod(); mod == nil || goputil.ModFileKind(mod, fname) == goputil.FileUnknown {
+				continue
+			}
+		}
 		if !test {
 			if strings.HasSuffix(fname[:len(fname)-len(fext)], "_test") {
 				continue
 			}
 			// check gox class test
 			if strings.HasSuffix(fname, "test.gox") {
-				once.Do(func() {
-					mod, _ = gop.LoadMod(dir)
-				})
-				if mod != nil {
+				if loadMod(); mod != nil {
 					if _, ok := mod.ClassKind(fname); ok {
 						continue
 					}
@@ -419,50 +519,234 @@ func addGopFiles(ret *Package, ld *loader, dir string, mode LoadMode, test bool)
 			}
 		}
 		file := dir + fname
-		f, err := parser.ParseFile(fsetTemp, file, nil, parser.PackageClauseOnly)
+		src, err := ld.build.readFile(file)
+		if err != nil || !ld.build.matchFile(fname, src) {
+			continue
+		}
+		f, err := parser.ParseFile(fsetTemp, file, src, parser.PackageClauseOnly)
 		if err == nil && pkgName == f.Name.Name {
 			ret.GopFiles = append(ret.GopFiles, file)
 			ret.CompiledGopFiles = append(ret.CompiledGopFiles, file)
 			// goxls: todo - condition
 		}
 	}
-	if ld != nil && len(ret.CompiledGopFiles) > 0 {
-		ctx := ld.Context
-		mod := ctx.LoadMod(ret.Module)
-		ret.GopSyntax = ld.parseFiles(ret, mod, ret.CompiledGopFiles)
-		if mode&(NeedTypes|NeedTypesInfo) != 0 {
-			ret.GopTypesInfo = &typesutil.Info{
-				Types:      make(map[ast.Expr]types.TypeAndValue),
-				Defs:       make(map[*ast.Ident]types.Object),
-				Uses:       make(map[*ast.Ident]types.Object),
-				Implicits:  make(map[ast.Node]types.Object),
-				Scopes:     make(map[ast.Node]*types.Scope),
-				Selections: make(map[*ast.SelectorExpr]*types.Selection),
-				Instances:  make(map[*ast.Ident]types.Instance),
-				Overloads:  make(map[*ast.Ident]types.Object),
-			}
-			cfg := &types.Config{
-				Context: ctx.Types,
-				Error: func(err error) {
-					appendError(ret, err)
-				},
-			}
-			opts := &typesutil.Config{
-				Types: ret.Types,
-				Fset:  ld.Fset,
-				Mod:   mod,
+}
+
+// loadGopPackages parses and type-checks the Go+ files of the packages
+// pkgs, and of their dependencies if mode has NeedDeps and NeedImports.
+//
+// The packages are loaded in parallel, each one once its imports are, as
+// the Go+ type checker of a package uses the types of its imports. The
+// types of a Go+ package are completed as the Go+ type checker of the
+// packages that import it would, before these are checked: the checkers
+// then only read the types of their imports.
+func (ld *loader) loadGopPackages(pkgs []*Package, mode LoadMode) {
+	for _, pkg := range pkgs {
+		ld.pkg(pkg).needGop = true
+	}
+	if mode&(NeedDeps|NeedImports) == NeedDeps|NeedImports {
+		for _, lpkg := range ld.pkgs {
+			lpkg.needGop = true
+		}
+	}
+	var wg sync.WaitGroup
+	for _, pkg := range pkgs {
+		wg.Add(1)
+		go func(pkg *Package) {
+			ld.loadRecursive(pkg, mode)
+			wg.Done()
+		}(pkg)
+	}
+	wg.Wait()
+}
+
+// loadRecursive loads the Go+ files of pkg, if needed, once those of its
+// imports are loaded.
+func (ld *loader) loadRecursive(pkg *Package, mode LoadMode) {
+	lpkg := ld.pkg(pkg)
+	lpkg.loadOnce.Do(func() {
+		var wg sync.WaitGroup
+		for _, imp := range pkg.Imports {
+			wg.Add(1)
+			go func(imp *Package) {
+				ld.loadRecursive(imp, mode)
+				wg.Done()
+			}(imp)
+		}
+		wg.Wait()
+		if lpkg.needGop && len(pkg.CompiledGopFiles) > 0 {
+			cpuLimit <- true // wait
+			ld.loadGopFiles(pkg, mode, lpkg.test)
+			<-cpuLimit // signal
+		}
+		if pkg.Types != nil {
+			initGopPkg(pkg.Types)
+		}
+	})
+}
+
+// We use a counting semaphore to limit the number of packages whose Go+
+// files are parsed and type-checked in parallel per process: the imports
+// are loaded by as many goroutines as there are packages.
+var cpuLimit = make(chan bool, runtime.GOMAXPROCS(0))
+
+// A loaderPackage is the state of the loading of the Go+ files of a
+// package.
+type loaderPackage struct {
+	test     bool // whether the Go+ test files are loaded
+	needGop  bool // whether the Go+ files are loaded
+	loadOnce sync.Once
+}
+
+// pkg returns the loading state of pkg.
+func (ld *loader) pkg(pkg *Package) *loaderPackage {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+	lpkg, ok := ld.pkgs[pkg]
+	if !ok {
+		if ld.pkgs == nil {
+			ld.pkgs = make(map[*Package]*loaderPackage)
+		}
+		lpkg = new(loaderPackage)
+		ld.pkgs[pkg] = lpkg
+	}
+	return lpkg
+}
+
+// loadGopFiles parses the Go+ files of ret and type-checks them, if mode
+// needs it.
+func (ld *loader) loadGopFiles(ret *Package, mode LoadMode, test bool) {
+	ctx := ld.Context
+	mod := ctx.LoadMod(ret.Module)
+	if ret.Module == nil {
+		mod = ctx.LoadModOf(pkgDir(ret))
+	}
+	ret.GopSyntax = ld.parseFiles(ret, mod, ret.CompiledGopFiles)
+	if mode&(NeedTypes|NeedTypesInfo) == 0 || ret.Types == nil || ret.TypesInfo == nil {
+		return
+	}
+	ret.GopTypesInfo = &typesutil.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Instances:  make(map[*ast.Ident]types.Instance),
+		Overloads:  make(map[*ast.Ident]types.Object),
+	}
+	defer func() {
+		// The Go+ compiler may panic on code, or on packages, it does not
+		// expect, in the goroutine of the package: the panic is reported as
+		// an error of the package rather than crashing the process.
+		if e := recover(); e != nil {
+			appendError(ret, Error{Pos: "-", Msg: fmt.Sprintf("internal error: type-checking the Go+ files panicked: %v", e), Kind: UnknownError})
+			if debugVerbose {
+				log.Printf("typesutil.Check: panic: %v\n%s", e, debug.Stack())
 			}
+		}
+	}()
+	fallback := func() types.Importer {
+		imp := newGenGoImporter(ld.cfg, ld.build, mod, func(string) {})
+		imp.fset = ld.Fset
+		return imp
+	}
+	cfg := &types.Config{
+		Context:  ctx.Types,
+		Importer: newImporter(ret, fallback),
+		Error: func(err error) {
+			appendError(ret, err)
+		},
+	}
+	opts := &typesutil.Config{
+		Types: ret.Types,
+		Fset:  ld.Fset,
+		Mod:   mod,
+	}
 
-			scope := ret.Types.Scope()
-			objMap := typesutil.DeleteObjects(scope, autogenFiles(ret, test))
-			c := typesutil.NewChecker(cfg, opts, nil, ret.GopTypesInfo)
-			err = c.Files(nil, ret.GopSyntax)
-			typesutil.CorrectTypesInfo(scope, objMap, ret.TypesInfo.Uses)
-			if err != nil && debugVerbose {
-				log.Println("typesutil.Check:", err)
+	scope := ret.Types.Scope()
+	objMap := typesutil.DeleteObjects(scope, autogenFiles(ret, test))
+	c := typesutil.NewChecker(cfg, opts, nil, ret.GopTypesInfo)
+	err := c.Files(nil, ret.GopSyntax)
+	typesutil.CorrectTypesInfo(scope, objMap, ret.TypesInfo.Uses)
+	if err != nil && debugVerbose {
+		log.Println("typesutil.Check:", err)
+	}
+}
+
+// importer imports the dependencies of a package from the packages loaded
+// along with it, falling back to the export data of the others, such as the
+// Go+ builtin packages. The Go+ type checker thus shares the objects of the
+// dependencies with the Go one, which the facts of the analyzers are about.
+//
+// The export data is that of the packages listed as by the Config, as for
+// the Go+ packages whose Go code is generated in-process, rather than in
+// the directory and environment of the process. The importer of the
+// export data is only made on the first import of a package not loaded.
+//
+// The indirect dependencies read from the export data of the direct ones
+// are only shared if they are complete: those holding only the objects the
+// export data refers to would lack the others, such as the functions of os
+// the Go+ builtins are bound to.
+type importer struct {
+	pkgs map[string]*types.Package
+	gop  types.Importer
+
+	newGop func() types.Importer
+}
+
+func newImporter(ret *Package, fallback func() types.Importer) types.Importer {
+	pkgs := make(map[string]*types.Package)
+	seen := make(map[*types.Package]bool)
+	var addImports func(pkg *types.Package)
+	addImports = func(pkg *types.Package) {
+		for _, imp := range pkg.Imports() {
+			if seen[imp] {
+				continue
+			}
+			seen[imp] = true
+			if _, ok := pkgs[imp.Path()]; !ok && imp.Complete() {
+				pkgs[imp.Path()] = imp
 			}
+			addImports(imp)
 		}
 	}
+	for path, imp := range ret.Imports {
+		if imp.Types != nil {
+			pkgs[path] = imp.Types
+		}
+	}
+	for _, imp := range ret.Imports {
+		if imp.Types != nil {
+			addImports(imp.Types)
+		}
+	}
+	return &importer{pkgs: pkgs, newGop: fallback}
+}
+
+// gopPkgInit is the constant by which gogen marks the Go+ packages whose
+// types it completed on import, so as not to complete them again.
+const gopPkgInit = "__gop_inited"
+
+// initGopPkg completes the types of pkg, if it is a Go+ package, with its
+// overloaded functions and methods, as gogen does on import.
+func initGopPkg(pkg *types.Package) {
+	scope := pkg.Scope()
+	if scope.Lookup("GopPackage") == nil || scope.Lookup(gopPkgInit) != nil {
+		return
+	}
+	scope.Insert(types.NewConst(token.NoPos, pkg, gopPkgInit, types.Typ[types.UntypedBool], constant.MakeBool(true)))
+	gogen.InitThisGopPkg(pkg)
+}
+
+func (p *importer) Import(path string) (*types.Package, error) {
+	if pkg, ok := p.pkgs[path]; ok {
+		return pkg, nil
+	}
+	if p.gop == nil {
+		p.gop = p.newGop()
+	}
+	return p.gop.Import(path)
 }
 
 type loader struct {
@@ -479,6 +763,11 @@ type loader struct {
 	Overlay map[string][]byte
 
 	ctx context.Context
+
+	cfg   *Config
+	build *buildContext
+	mu    sync.Mutex
+	pkgs  map[*Package]*loaderPackage
 }
 
 // parseFiles reads and parses the Go+ source files and returns the ASTs
@@ -0,0 +1,205 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packages_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/goplus/gop/ast"
+	"golang.org/x/tools/gop/packages"
+)
+
+// writeModule writes the files of the module example.com/m to a temporary
+// directory, which it returns.
+func writeModule(t *testing.T, files map[string]string) string {
+	files["go.mod"] = "module example.com/m\n\ngo 1.18\n"
+	return writeTree(t, files)
+}
+
+// writeTree writes files, by their slash-separated paths, to a temporary
+// directory, which it returns.
+func writeTree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNeedGopDeps(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.gop": "package a\n\nimport \"example.com/m/b\"\n\nfunc F() int {\n\treturn b.Bar(1)\n}\n",
+		"b/b.gop": "package b\n\nfunc Bar(x int) int {\n\treturn x * 2\n}\n",
+	})
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	for _, test := range []struct {
+		mode    packages.LoadMode
+		gopDeps bool
+	}{
+		{packages.LoadSyntax, false},
+		{packages.LoadAllSyntax, true},
+		{packages.LoadSyntax | packages.NeedGopDeps, true},
+	} {
+		cfg := &packages.Config{Dir: dir, Mode: test.mode}
+		pkgs, err := packages.LoadEx(gop, cfg, "./a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+			t.Fatalf("%v: loading failed", test.mode)
+		}
+		a := pkgs[0]
+		b := a.Imports["example.com/m/b"]
+		if len(a.GopSyntax) != 1 || a.GopTypesInfo == nil {
+			t.Errorf("%v: got no Go+ syntax or types for %s", test.mode, a.PkgPath)
+		}
+		if got := len(b.GopSyntax) == 1 && b.GopTypesInfo != nil; got != test.gopDeps {
+			t.Errorf("%v: got Go+ syntax and types for %s: %v, want %v", test.mode, b.PkgPath, got, test.gopDeps)
+		}
+		if !test.gopDeps {
+			continue
+		}
+		// The uses of the dependency refer to its Go+ declarations.
+		var bar *ast.Ident
+		ast.Inspect(a.GopSyntax[0], func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok && sel.Sel.Name == "Bar" {
+				bar = sel.Sel
+			}
+			return bar == nil
+		})
+		obj := a.GopTypesInfo.Uses[bar]
+		if obj == nil || b.GopTypesInfo.Defs[b.GopSyntax[0].Decls[0].(*ast.FuncDecl).Name] != obj {
+			t.Errorf("%v: b.Bar refers to %v, want the Go+ declaration of Bar", test.mode, obj)
+		}
+	}
+}
+
+// TestParallelGopDeps checks that the Go+ packages that import the same
+// Go+ package with overloaded functions, which are type-checked in
+// parallel, all see its overloads, completed once before they are checked.
+func TestParallelGopDeps(t *testing.T) {
+	files := map[string]string{
+		"a/a.gop": "package a\n\nfunc Add = (\n\tfunc(x, y int) int {\n\t\treturn x + y\n\t}\n\tfunc(x, y string) string {\n\t\treturn x + y\n\t}\n)\n",
+	}
+	var patterns []string
+	for _, name := range []string{"b", "c", "d", "e"} {
+		files[name+"/"+name+".gop"] = "package " + name + "\n\nimport \"example.com/m/a\"\n\nvar X, Y = a.add(1, 2), a.add(\"x\", \"y\")\n"
+		patterns = append(patterns, "./"+name)
+	}
+	dir := writeModule(t, files)
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax | packages.NeedGopDeps}
+	pkgs, err := packages.LoadEx(gop, cfg, patterns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != len(patterns) {
+		t.Fatalf("loading failed")
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.GopTypesInfo.Overloads) == 0 {
+			t.Errorf("%s: got no overloads of a.Add", pkg.PkgPath)
+		}
+	}
+}
+
+func TestOverlayAndBuildTags(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.gop": "package a\n\nfunc A() int {\n\treturn 1\n}\n",
+		"a/x.gop": "//go:build ignore\n\npackage a\n\nfunc A() string {\n\treturn \"\"\n}\n",
+		"a/y.gop": "// Y is built with mytag.\n\n//go:build mytag\n\npackage a\n\nfunc Y() {}\n",
+		// Files are selected by their GOOS and GOARCH suffixes too.
+		"a/o_" + runtime.GOOS + ".gop":                        "package a\n\nfunc O() {}\n",
+		"a/p_" + runtime.GOOS + "_" + runtime.GOARCH + ".gop": "package a\n\nfunc P() {}\n",
+		"a/q_" + otherOS + ".gop":                             "package a\n\nfunc A() string {\n\treturn \"\"\n}\n",
+		"a/r_" + runtime.GOOS + "_" + otherArch + ".gop":      "package a\n\nfunc A() string {\n\treturn \"\"\n}\n",
+	})
+	o, p := "o_"+runtime.GOOS+".gop", "p_"+runtime.GOOS+"_"+runtime.GOARCH+".gop"
+	overlay := map[string][]byte{
+		filepath.Join(dir, "a", "c.gop"): []byte("package a\n\nfunc C() int {\n\treturn A()\n}\n"),
+	}
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	for _, test := range []struct {
+		buildFlags []string
+		overlay    map[string][]byte
+		want       []string
+	}{
+		{nil, nil, []string{"a.gop", o, p}},
+		{[]string{"-tags=mytag"}, nil, []string{"a.gop", o, p, "y.gop"}},
+		{[]string{"-tags", "mytag"}, overlay, []string{"a.gop", "c.gop", o, p, "y.gop"}},
+	} {
+		cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax, BuildFlags: test.buildFlags, Overlay: test.overlay}
+		pkgs, err := packages.LoadEx(gop, cfg, "./a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+			t.Fatalf("%v: loading failed", test.buildFlags)
+		}
+		pkg := pkgs[0]
+		var got []string
+		for _, file := range pkg.GopFiles {
+			got = append(got, filepath.Base(file))
+		}
+		if strings.Join(got, " ") != strings.Join(test.want, " ") {
+			t.Errorf("%v: got Go+ files %v, want %v", test.buildFlags, got, test.want)
+		}
+		// The Go code of the overlay is not written.
+		c := pkg.Types.Scope().Lookup("C")
+		if (c != nil) != (test.overlay != nil) || len(pkg.GopTypesInfo.Defs) == 0 {
+			t.Errorf("%v: got C = %v with the overlay %v", test.buildFlags, c, test.overlay != nil)
+		}
+	}
+	autogen, err := os.ReadFile(filepath.Join(dir, "a", "gop_autogen.go"))
+	if err != nil || strings.Contains(string(autogen), "func C") {
+		t.Errorf("gop_autogen.go has the Go code of the overlay: %v", err)
+	}
+}
+
+// otherOS and otherArch are a GOOS and a GOARCH other than those of the
+// tests.
+var otherOS, otherArch = pick("plan9", "windows"), pick("s390x", "wasm")
+
+func pick(x, y string) string {
+	if x == runtime.GOOS || x == runtime.GOARCH {
+		return y
+	}
+	return x
+}
+
+// TestOverlayGenGoCmd checks that the Go+ files of the overlay are loaded
+// by default, although the gop command only sees the files on disk.
+func TestOverlayGenGoCmd(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"a/a.gop": "package a\n\nfunc A() int {\n\treturn 1\n}\n",
+	})
+	overlay := map[string][]byte{
+		filepath.Join(dir, "a", "c.gop"): []byte("package a\n\nfunc C() int {\n\treturn A()\n}\n"),
+	}
+	cfg := &packages.Config{Dir: dir, Mode: packages.LoadSyntax, Overlay: overlay}
+	pkgs, err := packages.LoadEx(&packages.GopConfig{GenGo: packages.GenGoCmd}, cfg, "./a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+		t.Fatalf("loading failed")
+	}
+	if pkgs[0].Types.Scope().Lookup("C") == nil || len(pkgs[0].GopSyntax) != 2 {
+		t.Errorf("got no C of the overlay, with the Go+ files %v", pkgs[0].GopFiles)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a", "c.gop")); err == nil {
+		t.Errorf("the overlay was written")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packagestest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/mod/modfile"
+)
+
+// gopModVersion is the version of Go+ in the exported gop.mod files.
+const gopModVersion = "1.2"
+
+// writeGopMod writes the gop.mod file of module, registering its Projects.
+func writeGopMod(exported *Exported, module Module) error {
+	if _, ok := module.Files["gop.mod"]; ok {
+		return fmt.Errorf("module %s has both Projects and a gop.mod file", module.Name)
+	}
+	written, ok := exported.written[module.Name]
+	if !ok {
+		written = map[string]string{}
+		exported.written[module.Name] = written
+	}
+	fullpath := exported.Exporter.Filename(exported, module.Name, "gop.mod")
+	written["gop.mod"] = fullpath
+	if err := ioutil.WriteFile(fullpath, []byte(gopMod(module.Projects)), 0644); err != nil {
+		return err
+	}
+	// gop only reads a gop.mod file next to a go.mod file: in GOPATH mode,
+	// where the go command ignores it, a go.mod file declaring the module
+	// is written too, unless the module has one.
+	if _, ok := module.Files["go.mod"]; ok || exported.Exporter != Exporter(GOPATH) {
+		return nil
+	}
+	gomod := exported.Exporter.Filename(exported, module.Name, "go.mod")
+	return ioutil.WriteFile(gomod, []byte(fmt.Sprintf("module %s\n", module.Name)), 0644)
+}
+
+// gopMod returns the contents of a gop.mod file registering projs.
+func gopMod(projs []*modfile.Project) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gop %s\n", gopModVersion)
+	for _, p := range projs {
+		b.WriteString("\nproject")
+		if p.Ext != "" {
+			fmt.Fprintf(&b, " %s %s", p.Ext, p.Class)
+		}
+		for _, pkgPath := range p.PkgPaths {
+			b.WriteString(" " + modfile.AutoQuote(pkgPath))
+		}
+		b.WriteByte('\n')
+		for _, w := range p.Works {
+			fmt.Fprintf(&b, "class %s %s\n", w.Ext, w.Class)
+		}
+		for _, imp := range p.Import {
+			b.WriteString("import ")
+			if imp.Name != "" {
+				b.WriteString(modfile.AutoQuote(imp.Name) + " ")
+			}
+			b.WriteString(modfile.AutoQuote(imp.Path) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// classKind returns the kind of the classfiles registered by projs, as the
+// ClassKind method of a module with these projects in its gop.mod file.
+func classKind(projs []*modfile.Project) func(fname string) (isProj, ok bool) {
+	return func(fname string) (isProj, ok bool) {
+		ext := modfile.ClassExt(fname)
+		for _, p := range projs {
+			if p.Ext == ext {
+				return p.IsProj(ext, fname), true
+			}
+			for _, w := range p.Works {
+				if w.Ext == ext {
+					return p.IsProj(ext, fname), true
+				}
+			}
+		}
+		return
+	}
+}
+
+// classfiles returns the names of the classfiles of the exported modules,
+// mapped to the kind of the classfiles of their modules.
+func (e *Exported) classfiles() map[string]func(fname string) (isProj, ok bool) {
+	files := make(map[string]func(fname string) (isProj, ok bool))
+	for _, module := range e.Modules {
+		if len(module.Projects) == 0 {
+			continue
+		}
+		kind := classKind(module.Projects)
+		for fragment, filename := range e.written[module.Name] {
+			if _, ok := kind(path.Base(fragment)); ok {
+				files[filename] = kind
+			}
+		}
+		for fragment := range module.Overlay {
+			if _, ok := kind(path.Base(fragment)); ok {
+				files[e.Exporter.Filename(e, module.Name, filepath.FromSlash(fragment))] = kind
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package packagestest_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gop/packages"
+	"golang.org/x/tools/gop/packages/packagestest"
+)
+
+// classfileModules are a module with a classfile project, whose framework
+// package is in the module, and the packages of the project.
+var classfileModules = []packagestest.Module{{
+	Name: "golang.org/fake",
+	Files: map[string]interface{}{
+		"game/game.go": `package game
+
+type Game struct{}
+
+func (p *Game) Main() {}
+
+func (p *Game) Run() {}
+
+type Sprite struct{}
+
+func (p *Sprite) Say(msg string) {}
+`,
+		"demo/main.gmx": "var n int\n\nrun\n",
+		"demo/Kai.spx":  "func onMsg() {\n\tsay \"hi\"\n}\n",
+	},
+	Projects: []*modfile.Project{{
+		Ext:      ".gmx",
+		Class:    "Game",
+		Works:    []*modfile.Class{{Ext: ".spx", Class: "Sprite"}},
+		PkgPaths: []string{"golang.org/fake/game"},
+	}},
+}}
+
+// TestClassfile checks that the classfile projects of the exported modules
+// are loaded by both exporters.
+func TestClassfile(t *testing.T) { packagestest.TestAll(t, testClassfile) }
+func testClassfile(t *testing.T, exporter packagestest.Exporter) {
+	exported := packagestest.Export(t, exporter, classfileModules)
+	defer exported.Cleanup()
+
+	gop := &packages.GopConfig{GenGo: packages.GenGoInProcess}
+	for _, mode := range []packages.LoadMode{
+		packages.LoadSyntax,
+		packages.LoadSyntax | packages.NeedModule,
+	} {
+		cfg := *exported.Config
+		cfg.Mode = mode
+		pkgs, err := packages.LoadEx(gop, &cfg, "golang.org/fake/demo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if packages.PrintErrors(pkgs) > 0 || len(pkgs) != 1 {
+			t.Fatalf("%v: loading failed", mode)
+		}
+		pkg := pkgs[0]
+		var got []string
+		for _, f := range pkg.GopSyntax {
+			got = append(got, filepath.Base(pkg.Fset.File(f.Pos()).Name()))
+		}
+		if len(got) != 2 || pkg.GopTypesInfo == nil {
+			t.Fatalf("%v: got the Go+ syntax of %v, want Kai.spx and main.gmx", mode, got)
+		}
+		if pkg.Types.Scope().Lookup("Kai") == nil {
+			t.Errorf("%v: got no class Kai", mode)
+		}
+	}
+}
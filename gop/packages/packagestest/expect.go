@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 
 	"golang.org/x/tools/gop/expect"
@@ -171,9 +172,23 @@ func (e *Exported) getNotes() error {
 		return fmt.Errorf("unable to load packages for directories %s: %v", dirs, err)
 	}
 	seen := make(map[token.Position]struct{})
+	addNotes := func(l []*expect.Note) {
+		for _, note := range l {
+			pos := e.ExpectFileSet.Position(note.Pos)
+			if _, ok := seen[pos]; ok {
+				continue
+			}
+			notes = append(notes, note)
+			seen[pos] = struct{}{}
+		}
+	}
+	classfiles := e.classfiles() // goxls: the classfiles registered in gop.mod files
 	for _, pkg := range pkgs {
 		files := append(append([]string{}, pkg.GoFiles...), pkg.GopFiles...)
 		for _, filename := range files {
+			if _, ok := classfiles[filename]; ok {
+				continue
+			}
 			content, err := e.FileContents(filename)
 			if err != nil {
 				return err
@@ -182,15 +197,24 @@ func (e *Exported) getNotes() error {
 			if err != nil {
 				return fmt.Errorf("failed to extract expectations: %v", err)
 			}
-			for _, note := range l {
-				pos := e.ExpectFileSet.Position(note.Pos)
-				if _, ok := seen[pos]; ok {
-					continue
-				}
-				notes = append(notes, note)
-				seen[pos] = struct{}{}
-			}
+			addNotes(l)
+		}
+	}
+	filenames := make([]string, 0, len(classfiles))
+	for filename := range classfiles {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		content, err := e.FileContents(filename)
+		if err != nil {
+			return err
 		}
+		l, err := expect.ParseClass(e.ExpectFileSet, filename, content, classfiles[filename])
+		if err != nil {
+			return fmt.Errorf("failed to extract expectations: %v", err)
+		}
+		addNotes(l)
 	}
 	if _, ok := e.written[e.primary]; !ok {
 		e.notes = notes
@@ -209,6 +233,25 @@ func (e *Exported) getNotes() error {
 		}
 		notes = append(notes, l...)
 	}
+	// goxls: and the gop.mod markers, which are in the gop.mod files as is.
+	var gopmods []string
+	for fragment, filename := range e.written[e.primary] {
+		if filepath.Base(fragment) == "gop.mod" {
+			gopmods = append(gopmods, filename)
+		}
+	}
+	sort.Strings(gopmods)
+	for _, gopmod := range gopmods {
+		content, err := e.FileContents(gopmod)
+		if err != nil {
+			return err
+		}
+		l, err := expect.Parse(e.ExpectFileSet, gopmod, content)
+		if err != nil {
+			return fmt.Errorf("failed to extract expectations for gop.mod: %v", err)
+		}
+		notes = append(notes, l...)
+	}
 	e.notes = notes
 	return nil
 }
@@ -77,6 +77,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/goplus/mod/modfile"
 	"golang.org/x/tools/gop/expect"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/internal/testenv"
@@ -104,6 +105,18 @@ type Module struct {
 	// The keys are the file fragment as in the Files configuration.
 	// The values are the in memory overlay content for the file.
 	Overlay map[string][]byte
+
+	// goxls: Projects are the classfile projects that the module registers
+	// in its gop.mod file, which Export writes at the root of the module,
+	// along with a go.mod file in GOPATH mode.
+	// The framework packages of a project, such as example.com/game of
+	//
+	//	project .gmx Game example.com/game
+	//	class .spx Sprite
+	//
+	// can be packages of any of the exported modules, and the notes of the
+	// classfiles, such as the .gmx and .spx files, are expectations.
+	Projects []*modfile.Project
 }
 
 // A Writer is a function that writes out a test file.
@@ -265,6 +278,11 @@ func Export(t testing.TB, exporter Exporter, modules []Module) *Exported {
 			fullpath := exporter.Filename(exported, module.Name, filepath.FromSlash(fragment))
 			exported.Config.Overlay[fullpath] = value
 		}
+		if len(module.Projects) > 0 {
+			if err := writeGopMod(exported, module); err != nil {
+				t.Fatal(err)
+			}
+		}
 	}
 	if err := exporter.Finalize(exported); err != nil {
 		t.Fatal(err)
@@ -128,7 +128,7 @@ func loadAPI() (*source.APIJSON, error) {
 	} {
 		api.Analyzers = append(api.Analyzers, loadAnalyzers(m)...)
 	}
-	api.Hints = loadHints(source.AllInlayHints)
+	api.Hints = loadHints(source.AllInlayHints, source.AllGopInlayHints)
 	for _, category := range []reflect.Value{
 		reflect.ValueOf(defaults.UserOptions),
 	} {
@@ -528,18 +528,29 @@ func loadAnalyzers(m map[string]*source.Analyzer) []*source.AnalyzerJSON {
 	return json
 }
 
-func loadHints(m map[string]*source.Hint) []*source.HintJSON {
+func loadHints(m map[string]*source.Hint, gop map[string]*source.GopHint) []*source.HintJSON {
 	var sorted []string
 	for _, h := range m {
 		sorted = append(sorted, h.Name)
 	}
+	// goxls: Go+ hints that share a name with a Go hint are documented by it.
+	for _, h := range gop {
+		if _, ok := m[h.Name]; !ok {
+			sorted = append(sorted, h.Name)
+		}
+	}
 	sort.Strings(sorted)
 	var json []*source.HintJSON
 	for _, name := range sorted {
-		h := m[name]
+		var doc string
+		if h, ok := m[name]; ok {
+			doc = h.Doc
+		} else {
+			doc = gop[name].Doc
+		}
 		json = append(json, &source.HintJSON{
-			Name: h.Name,
-			Doc:  h.Doc,
+			Name: name,
+			Doc:  doc,
 		})
 	}
 	return json
@@ -0,0 +1,276 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package deprecated
+
+import (
+	"bytes"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/token"
+	goinspector "golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/objectpath"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/internal/typeparams"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopDeprecated",
+	Doc:              doc,
+	Requires:         []analysis.IAnalyzer{Analyzer},
+	Run:              gopCheckDeprecated,
+	FactTypes:        []analysis.Fact{(*gopDeprecationFact)(nil)},
+	RunDespiteErrors: true,
+}
+
+// gopDeprecationFact is the deprecationFact of GopAnalyzer: a fact type
+// may only be registered by a single analyzer.
+type gopDeprecationFact deprecationFact
+
+func (*gopDeprecationFact) AFact()           {}
+func (d *gopDeprecationFact) String() string { return "Deprecated: " + d.Msg }
+
+// gopCheckDeprecated reports the uses of deprecated symbols and package
+// imports in the Go+ files of the package.
+//
+// As the facts of an analyzer are only visible to itself, the deprecated
+// names of the Go files are collected again along with those of the Go+
+// files, so that they are known to the Go+ files of the dependent packages.
+func gopCheckDeprecated(pass *analysis.Pass) (interface{}, error) {
+	gopCollectDeprecatedNames(pass)
+
+	// Let collectDeprecatedNames deal with the facts of GopAnalyzer.
+	goPass := pass.GoPass
+	goPass.ExportObjectFact = func(obj types.Object, fact analysis.Fact) {
+		pass.ExportObjectFact(obj, (*gopDeprecationFact)(fact.(*deprecationFact)))
+	}
+	goPass.ExportPackageFact = func(fact analysis.Fact) {
+		pass.ExportPackageFact((*gopDeprecationFact)(fact.(*deprecationFact)))
+	}
+	goPass.AllObjectFacts = func() []analysis.ObjectFact {
+		facts := pass.AllObjectFacts()
+		for i, fact := range facts {
+			facts[i].Fact = (*deprecationFact)(fact.Fact.(*gopDeprecationFact))
+		}
+		return facts
+	}
+	goPass.AllPackageFacts = func() []analysis.PackageFact {
+		facts := pass.AllPackageFacts()
+		for i, fact := range facts {
+			facts[i].Fact = (*deprecationFact)(fact.Fact.(*gopDeprecationFact))
+		}
+		return facts
+	}
+	deprs, err := collectDeprecatedNames(&goPass, goinspector.New(pass.Files))
+	if err != nil || len(pass.GopFiles) == 0 || (len(deprs.packages) == 0 && len(deprs.objects) == 0) {
+		return nil, err
+	}
+	info := pass.GopTypesInfo
+
+	// The objects of the imported packages recorded in GopTypesInfo may
+	// not be those of the Go type checker, which the facts are about: the
+	// deprecations are keyed by package path and object path instead.
+	objects := make(map[gopObjectKey]*deprecationFact, len(deprs.objects))
+	for obj, depr := range deprs.objects {
+		if key, ok := gopKeyOf(obj); ok {
+			objects[key] = depr
+		}
+	}
+	packages := make(map[string]*deprecationFact, len(deprs.packages))
+	for pkg, depr := range deprs.packages {
+		packages[pkg.Path()] = depr
+	}
+
+	reportDeprecation := func(depr *deprecationFact, node ast.Node) {
+		buf := new(bytes.Buffer)
+		if err := format.Node(buf, pass.Fset, node); err != nil {
+			// This shouldn't happen but let's be conservative.
+			buf.Reset()
+			buf.WriteString("declaration")
+		}
+		pass.ReportRangef(node, "%s is deprecated: %s", buf, depr.Msg)
+	}
+
+	// lookup returns the deprecation of obj, if obj is used by the package.
+	lookup := func(obj types.Object) *deprecationFact {
+		if obj_, ok := obj.(*types.Func); ok {
+			obj = typeparams.OriginMethod(obj_)
+		}
+		if obj == nil || obj.Pkg() == nil || obj.Pkg() == pass.Pkg {
+			// A package is allowed to use its own deprecated objects
+			return nil
+		}
+		pkgPath, objPkgPath := pass.Pkg.Path(), obj.Pkg().Path()
+		if strings.TrimSuffix(pkgPath, "_test") == objPkgPath ||
+			strings.TrimSuffix(pkgPath, ".test") == objPkgPath ||
+			strings.TrimSuffix(pkgPath, ".test") == strings.TrimSuffix(objPkgPath, "_test") {
+			// foo_test and foo.test can use objects from foo, and foo.test
+			// those from foo_test.
+			return nil
+		}
+		key, ok := gopKeyOf(obj)
+		if !ok {
+			return nil
+		}
+		return objects[key]
+	}
+
+	// Go+ resolves the lowercase names of Go exported objects, as well as
+	// the names of Go+ builtins such as println, so the uses are checked
+	// for all identifiers rather than for selectors only. A use of an
+	// overloaded function refers to one of its overloads, which is
+	// deprecated along with the overloaded function itself.
+	for _, f := range pass.GopFiles {
+		sels := make(map[*ast.Ident]bool)
+		ast.Inspect(f, func(node ast.Node) bool {
+			var id *ast.Ident
+			var rng ast.Node
+			switch node := node.(type) {
+			case *ast.SelectorExpr:
+				id, rng = node.Sel, node
+				sels[id] = true
+			case *ast.Ident:
+				if sels[node] {
+					return true
+				}
+				id, rng = node, node
+			default:
+				return true
+			}
+			depr := lookup(info.Uses[id])
+			if depr == nil {
+				depr = lookup(info.Overloads[id])
+			}
+			if depr != nil {
+				reportDeprecation(depr, rng)
+			}
+			return true
+		})
+
+		for _, spec := range f.Imports {
+			var obj types.Object
+			if spec.Name != nil {
+				obj = info.ObjectOf(spec.Name)
+			} else {
+				obj = info.Implicits[spec]
+			}
+			pkgName, ok := obj.(*types.PkgName)
+			if !ok {
+				continue
+			}
+			imp := pkgName.Imported()
+
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			pkgPath := pass.Pkg.Path()
+			if strings.TrimSuffix(pkgPath, "_test") == path ||
+				strings.TrimSuffix(pkgPath, ".test") == path ||
+				strings.TrimSuffix(pkgPath, ".test") == strings.TrimSuffix(path, "_test") {
+				// foo_test and foo.test can import foo, and foo.test foo_test
+				continue
+			}
+			if depr, ok := packages[imp.Path()]; ok {
+				reportDeprecation(depr, spec.Path)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// gopObjectKey identifies an object independently of the type checker
+// that created it.
+type gopObjectKey struct {
+	pkgPath string
+	objPath objectpath.Path
+}
+
+// gopKeyOf returns the key of obj, if obj can be reached from the scope
+// of its package.
+func gopKeyOf(obj types.Object) (gopObjectKey, bool) {
+	path, err := objectpath.For(obj)
+	if err != nil {
+		return gopObjectKey{}, false
+	}
+	return gopObjectKey{obj.Pkg().Path(), path}, true
+}
+
+// gopCollectDeprecatedNames publishes the deprecated identifiers of the
+// Go+ files as Facts, as collectDeprecatedNames does for the Go files.
+func gopCollectDeprecatedNames(pass *analysis.Pass) {
+	info := pass.GopTypesInfo
+	doDocs := func(names []*ast.Ident, docs *ast.CommentGroup) {
+		alt := gopExtractDeprecatedMessage(docs)
+		if alt == "" {
+			return
+		}
+		for _, name := range names {
+			if obj := info.ObjectOf(name); obj != nil {
+				pass.ExportObjectFact(obj, &gopDeprecationFact{alt})
+			}
+		}
+	}
+
+	for _, f := range pass.GopFiles {
+		if alt := gopExtractDeprecatedMessage(f.Doc); alt != "" {
+			pass.ExportPackageFact(&gopDeprecationFact{alt})
+		}
+		ast.Inspect(f, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.GenDecl:
+				switch node.Tok {
+				case token.TYPE, token.CONST, token.VAR:
+					var names []*ast.Ident
+					for i := range node.Specs {
+						switch n := node.Specs[i].(type) {
+						case *ast.ValueSpec:
+							names = append(names, n.Names...)
+						case *ast.TypeSpec:
+							names = append(names, n.Name)
+						}
+					}
+					doDocs(names, node.Doc)
+				}
+			case *ast.FuncDecl:
+				doDocs([]*ast.Ident{node.Name}, node.Doc)
+			case *ast.OverloadFuncDecl:
+				doDocs([]*ast.Ident{node.Name}, node.Doc)
+			case *ast.TypeSpec:
+				doDocs([]*ast.Ident{node.Name}, node.Doc)
+			case *ast.ValueSpec:
+				doDocs(node.Names, node.Doc)
+			case *ast.StructType:
+				for _, field := range node.Fields.List {
+					doDocs(field.Names, field.Doc)
+				}
+			case *ast.InterfaceType:
+				for _, field := range node.Methods.List {
+					doDocs(field.Names, field.Doc)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func gopExtractDeprecatedMessage(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	parts := strings.Split(doc.Text(), "\n\n")
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "Deprecated: ") {
+			continue
+		}
+		alt := part[len("Deprecated: "):]
+		alt = strings.Replace(alt, "\n", " ", -1)
+		return strings.TrimSpace(alt)
+	}
+	return ""
+}
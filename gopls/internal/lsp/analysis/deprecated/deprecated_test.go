@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/internal/testenv"
 )
 
@@ -16,3 +17,9 @@ func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, Analyzer, "a")
 }
+
+func TestGop(t *testing.T) {
+	testenv.NeedsGo1Point(t, 19)
+	testdata := gopanalysistest.TestData()
+	gopanalysistest.Run(t, testdata, GopAnalyzer, "goplib", "gopuse")
+}
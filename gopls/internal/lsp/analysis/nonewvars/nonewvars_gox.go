@@ -0,0 +1,80 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nonewvars
+
+import (
+	"bytes"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/analysis/passes/inspect"
+	"golang.org/x/tools/gop/ast/inspector"
+	"golang.org/x/tools/internal/gop/analysisinternal"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopNonewvars",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer, inspect.Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.GopFiles) == 0 || len(pass.TypeErrors) == 0 {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{(*ast.AssignStmt)(nil)}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		assignStmt, _ := n.(*ast.AssignStmt)
+		// We only care about ":=".
+		if assignStmt.Tok != token.DEFINE {
+			return
+		}
+
+		var file *ast.File
+		for _, f := range pass.GopFiles {
+			if f.Pos() <= assignStmt.Pos() && assignStmt.Pos() < f.End() {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			return
+		}
+
+		for _, err := range pass.TypeErrors {
+			if !FixesError(err.Msg) {
+				continue
+			}
+			if assignStmt.Pos() > err.Pos || err.Pos >= assignStmt.End() {
+				continue
+			}
+			var buf bytes.Buffer
+			if err := format.Node(&buf, pass.Fset, file); err != nil {
+				continue
+			}
+			// Go+ reports the error at the left-hand side rather than
+			// at the ":=" token.
+			pass.Report(analysis.Diagnostic{
+				Pos:     err.Pos,
+				End:     analysisinternal.TypeErrorEndPos(pass.Fset, buf.Bytes(), err.Pos),
+				Message: err.Msg,
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Change ':=' to '='",
+					TextEdits: []analysis.TextEdit{{
+						Pos: assignStmt.TokPos,
+						End: assignStmt.TokPos + 1,
+					}},
+				}},
+			})
+		}
+	})
+	return nil, nil
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/nonewvars"
 	"golang.org/x/tools/internal/typeparams"
 )
@@ -19,4 +20,5 @@ func Test(t *testing.T) {
 		tests = append(tests, "typeparams")
 	}
 	analysistest.RunWithSuggestedFixes(t, testdata, nonewvars.Analyzer, tests...)
+	gopanalysistest.RunWithSuggestedFixes(t, testdata, nonewvars.GopAnalyzer, "gop")
 }
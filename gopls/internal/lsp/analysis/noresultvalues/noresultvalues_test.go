@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/noresultvalues"
 	"golang.org/x/tools/internal/typeparams"
 )
@@ -19,4 +20,5 @@ func Test(t *testing.T) {
 		tests = append(tests, "typeparams")
 	}
 	analysistest.RunWithSuggestedFixes(t, testdata, noresultvalues.Analyzer, tests...)
+	gopanalysistest.RunWithSuggestedFixes(t, testdata, noresultvalues.GopAnalyzer, "gop")
 }
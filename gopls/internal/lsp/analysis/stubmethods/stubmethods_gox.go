@@ -5,17 +5,69 @@
 package stubmethods
 
 import (
+	"bytes"
 	"fmt"
 	"go/types"
 	"strconv"
+	"strings"
 
 	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
 	"github.com/goplus/gop/token"
 	"github.com/goplus/gop/x/typesutil"
 	"golang.org/x/tools/gop/analysis"
 	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/internal/gop/analysisinternal"
 )
 
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopStubmethods",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	if len(pass.GopFiles) == 0 {
+		return nil, nil
+	}
+	for _, err := range pass.TypeErrors {
+		var file *ast.File
+		for _, f := range pass.GopFiles {
+			if f.Pos() <= err.Pos && err.Pos < f.End() {
+				file = f
+				break
+			}
+		}
+		if file == nil {
+			continue
+		}
+		// Get the end position of the error.
+		var buf bytes.Buffer
+		if err := format.Node(&buf, pass.Fset, file); err != nil {
+			continue
+		}
+		end := analysisinternal.TypeErrorEndPos(pass.Fset, buf.Bytes(), err.Pos)
+		if diag, ok := GopDiagnosticForError(pass.Fset, file, err.Pos, end, err.Msg, pass.GopTypesInfo); ok {
+			pass.Report(diag)
+		}
+	}
+
+	return nil, nil
+}
+
+// GopMatchesMessage reports whether msg matches the error message sought
+// after by the stubmethods fix in Go+ files. Besides the messages of
+// go/types, the Go+ type checker reports a failed assignment to an
+// interface as:
+//
+//	cannot use T{} (type T) as type io.Reader in assignment
+func GopMatchesMessage(msg string) bool {
+	return MatchesMessage(msg) ||
+		strings.HasPrefix(msg, "cannot use") && strings.Contains(msg, " as type ")
+}
+
 // GopDiagnosticForError computes a diagnostic suggesting to implement an
 // interface to fix the type checking error defined by (start, end, msg).
 //
@@ -24,7 +76,7 @@ import (
 // TODO(rfindley): simplify this signature once the stubmethods refactoring is
 // no longer wedged into the analysis framework.
 func GopDiagnosticForError(fset *token.FileSet, file *ast.File, start, end token.Pos, msg string, info *typesutil.Info) (analysis.Diagnostic, bool) {
-	if !MatchesMessage(msg) {
+	if !GopMatchesMessage(msg) {
 		return analysis.Diagnostic{}, false
 	}
 
@@ -8,10 +8,12 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/undeclaredname"
 )
 
 func Test(t *testing.T) {
 	testdata := analysistest.TestData()
 	analysistest.Run(t, testdata, undeclaredname.Analyzer, "a")
+	gopanalysistest.Run(t, testdata, undeclaredname.GopAnalyzer, "gop")
 }
@@ -0,0 +1,229 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unusedparams
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"github.com/goplus/gogen"
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gop/analysis"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:     "gopUnusedparams",
+	Doc:      Doc,
+	Requires: []analysis.IAnalyzer{Analyzer},
+	Run:      gopRun,
+}
+
+type gopParamData struct {
+	field  *ast.Field // nil for the parameters of a lambda
+	ident  *ast.Ident
+	typObj types.Object
+}
+
+// gopRun reports the unused parameters of the functions, function literals
+// and lambdas of the Go+ files.
+//
+// In addition to the methods, the methods of classfiles, whose receiver is
+// implicit, and the members of overloaded functions, whose parameters may
+// only be there to distinguish the overloads, are ignored. Unlike function
+// literals, lambdas whose body is a single expression are checked.
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	info := pass.GopTypesInfo
+	if len(pass.GopFiles) == 0 || info == nil {
+		return nil, nil
+	}
+
+	// The members of the overloaded functions are those recorded by the
+	// type checker, in the package scope or for the declarations of the
+	// overloaded functions, which may follow their members.
+	members := make(map[types.Object]bool)
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		if fn, ok := scope.Lookup(name).(*types.Func); ok {
+			if funcs, ok := gogen.CheckOverloadFunc(fn.Type().(*types.Signature)); ok {
+				for _, member := range funcs {
+					members[member] = true
+				}
+			}
+		}
+	}
+	overloads := make(map[ast.Node]bool)
+	for _, f := range pass.GopFiles {
+		ast.Inspect(f, func(n ast.Node) bool {
+			decl, ok := n.(*ast.OverloadFuncDecl)
+			if !ok {
+				return true
+			}
+			for _, fn := range decl.Funcs {
+				switch fn := fn.(type) {
+				case *ast.FuncLit:
+					overloads[fn] = true
+				case *ast.Ident:
+					members[info.ObjectOf(fn)] = true
+				case *ast.SelectorExpr:
+					members[info.ObjectOf(fn.Sel)] = true
+				}
+			}
+			return true
+		})
+	}
+
+	for _, f := range pass.GopFiles {
+		testFile := gopIsTestFile(pass.Fset, f.Pos())
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				// Ignore methods, including those of classfiles, as
+				// the unusedparams analyzer does.
+				if n.Recv != nil || testFile || members[info.ObjectOf(n.Name)] {
+					return true
+				}
+				gopCheckFunc(pass, n.Type.Params, n.Body)
+			case *ast.FuncLit:
+				if !overloads[n] {
+					gopCheckFunc(pass, n.Type.Params, n.Body)
+				}
+			case *ast.LambdaExpr:
+				gopCheckParams(pass, gopLambdaParams(info, n.Lhs), func(visit func(ast.Node) bool) {
+					for _, expr := range n.Rhs {
+						ast.Inspect(expr, visit)
+					}
+				})
+			case *ast.LambdaExpr2:
+				if n.Body != nil && len(n.Body.List) > 0 && !gopIsStub(n.Body) {
+					gopCheckParams(pass, gopLambdaParams(info, n.Lhs), func(visit func(ast.Node) bool) {
+						ast.Inspect(n.Body, visit)
+					})
+				}
+			}
+			return true
+		})
+	}
+	return nil, nil
+}
+
+// gopCheckFunc reports the unused parameters of a function whose body is
+// neither empty nor a stub.
+func gopCheckFunc(pass *analysis.Pass, fieldList *ast.FieldList, body *ast.BlockStmt) {
+	// If there are no arguments or the function is empty, then return.
+	if fieldList.NumFields() == 0 || body == nil || len(body.List) == 0 || gopIsStub(body) {
+		return
+	}
+	var params []*gopParamData
+	for _, f := range fieldList.List {
+		for _, i := range f.Names {
+			params = append(params, &gopParamData{
+				field:  f,
+				ident:  i,
+				typObj: pass.GopTypesInfo.ObjectOf(i),
+			})
+		}
+	}
+	gopCheckParams(pass, params, func(visit func(ast.Node) bool) {
+		ast.Inspect(body, visit)
+	})
+}
+
+// gopLambdaParams returns the parameters of a lambda.
+func gopLambdaParams(info *typesutil.Info, lhs []*ast.Ident) []*gopParamData {
+	params := make([]*gopParamData, 0, len(lhs))
+	for _, i := range lhs {
+		params = append(params, &gopParamData{ident: i, typObj: info.ObjectOf(i)})
+	}
+	return params
+}
+
+// gopIsStub reports whether body only contains a return statement or a
+// panic, which are ignored to reduce false positives.
+func gopIsStub(body *ast.BlockStmt) bool {
+	switch expr := body.List[0].(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		callExpr, ok := expr.X.(*ast.CallExpr)
+		if !ok || len(body.List) > 1 {
+			break
+		}
+		if fun, ok := callExpr.Fun.(*ast.Ident); ok && fun.Name == "panic" {
+			return true
+		}
+	}
+	return false
+}
+
+// gopCheckParams reports the params that are not used by the body that
+// inspect walks.
+func gopCheckParams(pass *analysis.Pass, params []*gopParamData, inspect func(visit func(ast.Node) bool)) {
+	byName := make(map[string]*gopParamData)
+	unused := make(map[*gopParamData]bool)
+	for _, param := range params {
+		if param.ident.Name == "_" || param.typObj == nil {
+			continue
+		}
+		byName[param.ident.Name] = param
+		unused[param] = true
+	}
+	if len(unused) == 0 {
+		return
+	}
+
+	// Traverse through the body of the function and
+	// check to see which parameters are unused.
+	inspect(func(node ast.Node) bool {
+		n, ok := node.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		param, ok := byName[n.Name]
+		if !ok {
+			return false
+		}
+		if nObj := pass.GopTypesInfo.ObjectOf(n); nObj != param.typObj {
+			return false
+		}
+		delete(unused, param)
+		return false
+	})
+
+	// Create the reports for the unused parameters, in order.
+	for _, u := range params {
+		if !unused[u] {
+			continue
+		}
+		start, end := u.ident.Pos(), u.ident.End()
+		if u.field != nil && len(u.field.Names) == 1 {
+			start, end = u.field.Pos(), u.field.End()
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:     start,
+			End:     end,
+			Message: fmt.Sprintf("potentially unused parameter: '%s'", u.ident.Name),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: `Replace with "_"`,
+				TextEdits: []analysis.TextEdit{{
+					Pos:     u.ident.Pos(),
+					End:     u.ident.End(),
+					NewText: []byte("_"),
+				}},
+			}},
+		})
+	}
+}
+
+// gopIsTestFile reports whether pos is in a Go+ test file: a _test file or
+// a test classfile.
+func gopIsTestFile(fset *token.FileSet, pos token.Pos) bool {
+	fname := filepath.Base(fset.File(pos).Name())
+	fext := filepath.Ext(fname)
+	return strings.HasSuffix(fname[:len(fname)-len(fext)], "_test") || strings.HasSuffix(fname, "test.gox")
+}
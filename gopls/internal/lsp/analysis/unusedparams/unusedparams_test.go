@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/unusedparams"
 	"golang.org/x/tools/internal/typeparams"
 )
@@ -20,3 +21,8 @@ func Test(t *testing.T) {
 	}
 	analysistest.RunWithSuggestedFixes(t, testdata, unusedparams.Analyzer, tests...)
 }
+
+func TestGop(t *testing.T) {
+	testdata := gopanalysistest.TestData()
+	gopanalysistest.RunWithSuggestedFixes(t, testdata, unusedparams.GopAnalyzer, "gop")
+}
@@ -0,0 +1,320 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package unusedvariable
+
+import (
+	"bytes"
+	"go/types"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/format"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/ast/astutil"
+)
+
+var GopAnalyzer = &analysis.Analyzer{
+	Name:             "gopUnusedvariable",
+	Doc:              Doc,
+	Requires:         []analysis.IAnalyzer{Analyzer},
+	Run:              gopRun,
+	RunDespiteErrors: true,
+}
+
+// gopRun reports the unused local variables of the Go+ files.
+//
+// Unlike go/types, the Go+ type checker does not report unused variables
+// as errors, so they are found from the uses recorded in GopTypesInfo:
+// only the variables declared by a var declaration or a short variable
+// declaration of a function body are considered, as they are the only
+// ones that the fixes apply to.
+func gopRun(pass *analysis.Pass) (interface{}, error) {
+	info := pass.GopTypesInfo
+	if len(pass.GopFiles) == 0 || info == nil {
+		return nil, nil
+	}
+	used := make(map[types.Object]bool)
+	for _, obj := range info.Uses {
+		used[obj] = true
+	}
+	unused := func(ident *ast.Ident) bool {
+		if ident.Name == "_" {
+			return false
+		}
+		v, ok := info.Defs[ident].(*types.Var)
+		return ok && !v.IsField() && !used[v]
+	}
+	for _, file := range pass.GopFiles {
+		var idents []*ast.Ident
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.GenDecl:
+				// Package-level variables may be unused.
+				return n.Tok != token.VAR
+			case *ast.DeclStmt:
+				if decl, ok := n.Decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+					for _, spec := range decl.Specs {
+						for _, name := range spec.(*ast.ValueSpec).Names {
+							if unused(name) {
+								idents = append(idents, name)
+							}
+						}
+					}
+				}
+			case *ast.AssignStmt:
+				if n.Tok == token.DEFINE {
+					for _, expr := range n.Lhs {
+						if name, ok := expr.(*ast.Ident); ok && unused(name) {
+							idents = append(idents, name)
+						}
+					}
+				}
+			}
+			return true
+		})
+		for _, ident := range idents {
+			gopRunForIdent(pass, file, ident)
+		}
+	}
+	return nil, nil
+}
+
+func gopRunForIdent(pass *analysis.Pass, file *ast.File, ident *ast.Ident) {
+	path, _ := astutil.PathEnclosingInterval(file, ident.Pos(), ident.Pos())
+	if len(path) < 2 || path[0] != ident {
+		return
+	}
+
+	diag := analysis.Diagnostic{
+		Pos:     ident.Pos(),
+		End:     ident.End(),
+		Message: ident.Name + unusedVariableSuffixes[0],
+	}
+
+	for i := range path {
+		switch stmt := path[i].(type) {
+		case *ast.ValueSpec:
+			// Find GenDecl to which offending ValueSpec belongs.
+			if decl, ok := path[i+1].(*ast.GenDecl); ok {
+				fixes := gopRemoveVariableFromSpec(pass, path, stmt, decl, ident)
+				// fixes may be nil
+				if len(fixes) > 0 {
+					diag.SuggestedFixes = fixes
+					pass.Report(diag)
+				}
+			}
+
+		case *ast.AssignStmt:
+			if stmt.Tok != token.DEFINE {
+				continue
+			}
+
+			containsIdent := false
+			for _, expr := range stmt.Lhs {
+				if expr == ident {
+					containsIdent = true
+				}
+			}
+			if !containsIdent {
+				continue
+			}
+
+			fixes := gopRemoveVariableFromAssignment(path, stmt, ident)
+			// fixes may be nil
+			if len(fixes) > 0 {
+				diag.SuggestedFixes = fixes
+				pass.Report(diag)
+			}
+		}
+	}
+}
+
+func gopRemoveVariableFromSpec(pass *analysis.Pass, path []ast.Node, stmt *ast.ValueSpec, decl *ast.GenDecl, ident *ast.Ident) []analysis.SuggestedFix {
+	newDecl := new(ast.GenDecl)
+	*newDecl = *decl
+	newDecl.Specs = nil
+
+	for _, spec := range decl.Specs {
+		if spec != stmt {
+			newDecl.Specs = append(newDecl.Specs, spec)
+			continue
+		}
+
+		newSpec := new(ast.ValueSpec)
+		*newSpec = *stmt
+		newSpec.Names = nil
+
+		for _, n := range stmt.Names {
+			if n != ident {
+				newSpec.Names = append(newSpec.Names, n)
+			}
+		}
+
+		if len(newSpec.Names) > 0 {
+			newDecl.Specs = append(newDecl.Specs, newSpec)
+		}
+	}
+
+	// decl.End() does not include any comments, so if a comment is present we
+	// need to account for it when we delete the statement
+	end := decl.End()
+	if stmt.Comment != nil && stmt.Comment.End() > end {
+		end = stmt.Comment.End()
+	}
+
+	// There are no other specs left in the declaration, the whole statement can
+	// be deleted
+	if len(newDecl.Specs) == 0 {
+		// Find parent DeclStmt and delete it
+		for _, node := range path {
+			if declStmt, ok := node.(*ast.DeclStmt); ok {
+				return []analysis.SuggestedFix{
+					{
+						Message:   suggestedFixMessage(ident.Name),
+						TextEdits: gopDeleteStmtFromBlock(path, declStmt),
+					},
+				}
+			}
+		}
+	}
+
+	var b bytes.Buffer
+	if err := format.Node(&b, pass.Fset, newDecl); err != nil {
+		return nil
+	}
+
+	return []analysis.SuggestedFix{
+		{
+			Message: suggestedFixMessage(ident.Name),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos: decl.Pos(),
+					// Avoid adding a new empty line
+					End:     end + 1,
+					NewText: b.Bytes(),
+				},
+			},
+		},
+	}
+}
+
+func gopRemoveVariableFromAssignment(path []ast.Node, stmt *ast.AssignStmt, ident *ast.Ident) []analysis.SuggestedFix {
+	// The only variable in the assignment is unused
+	if len(stmt.Lhs) == 1 {
+		// If LHS has only one expression to be valid it has to have 1 expression
+		// on RHS
+		//
+		// RHS may have side effects, preserve RHS
+		if gopExprMayHaveSideEffects(stmt.Rhs[0]) {
+			// Delete until RHS
+			return []analysis.SuggestedFix{
+				{
+					Message: suggestedFixMessage(ident.Name),
+					TextEdits: []analysis.TextEdit{
+						{
+							Pos: ident.Pos(),
+							End: stmt.Rhs[0].Pos(),
+						},
+					},
+				},
+			}
+		}
+
+		// RHS does not have any side effects, delete the whole statement
+		return []analysis.SuggestedFix{
+			{
+				Message:   suggestedFixMessage(ident.Name),
+				TextEdits: gopDeleteStmtFromBlock(path, stmt),
+			},
+		}
+	}
+
+	// Otherwise replace ident with `_`
+	return []analysis.SuggestedFix{
+		{
+			Message: suggestedFixMessage(ident.Name),
+			TextEdits: []analysis.TextEdit{
+				{
+					Pos:     ident.Pos(),
+					End:     ident.End(),
+					NewText: []byte("_"),
+				},
+			},
+		},
+	}
+}
+
+func gopDeleteStmtFromBlock(path []ast.Node, stmt ast.Stmt) []analysis.TextEdit {
+	// Find innermost enclosing BlockStmt.
+	// The body of a Go+ main without braces has no position, so it is
+	// not on the path: use that of its FuncDecl instead.
+	var block *ast.BlockStmt
+	for i := range path {
+		if blockStmt, ok := path[i].(*ast.BlockStmt); ok {
+			block = blockStmt
+			break
+		}
+		if decl, ok := path[i].(*ast.FuncDecl); ok && decl.Shadow {
+			block = decl.Body
+			break
+		}
+	}
+	if block == nil {
+		return nil
+	}
+
+	nodeIndex := -1
+	for i, blockStmt := range block.List {
+		if blockStmt == stmt {
+			nodeIndex = i
+			break
+		}
+	}
+
+	// The statement we need to delete was not found in BlockStmt
+	if nodeIndex == -1 {
+		return nil
+	}
+
+	// Delete until the end of the block unless there is another statement after
+	// the one we are trying to delete
+	end := block.Rbrace
+	if nodeIndex < len(block.List)-1 {
+		end = block.List[nodeIndex+1].Pos()
+	} else if !end.IsValid() {
+		end = stmt.End()
+	}
+
+	return []analysis.TextEdit{
+		{
+			Pos: stmt.Pos(),
+			End: end,
+		},
+	}
+}
+
+// gopExprMayHaveSideEffects reports whether the expression may have side
+// effects (because it contains a function call or channel receive). We
+// disregard runtime panics as well written programs should not encounter them.
+func gopExprMayHaveSideEffects(expr ast.Expr) bool {
+	var mayHaveSideEffects bool
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr: // possible function call
+			mayHaveSideEffects = true
+			return false
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW { // channel receive
+				mayHaveSideEffects = true
+				return false
+			}
+		case *ast.FuncLit, *ast.LambdaExpr, *ast.LambdaExpr2:
+			return false // evaluating what's inside a FuncLit has no effect
+		}
+		return true
+	})
+
+	return mayHaveSideEffects
+}
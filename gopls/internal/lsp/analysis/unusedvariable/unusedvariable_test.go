@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"golang.org/x/tools/go/analysis/analysistest"
+	gopanalysistest "golang.org/x/tools/gop/analysis/analysistest"
 	"golang.org/x/tools/gopls/internal/lsp/analysis/unusedvariable"
 )
 
@@ -21,4 +22,8 @@ func Test(t *testing.T) {
 	t.Run("assign", func(t *testing.T) {
 		analysistest.RunWithSuggestedFixes(t, testdata, unusedvariable.Analyzer, "assign")
 	})
+
+	t.Run("gop", func(t *testing.T) {
+		gopanalysistest.RunWithSuggestedFixes(t, testdata, unusedvariable.GopAnalyzer, "gop")
+	})
 }
@@ -19,6 +19,7 @@ import (
 	"go/types"
 	"log"
 	urlpkg "net/url"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"runtime/debug"
@@ -30,6 +31,7 @@ import (
 
 	gopast "github.com/goplus/gop/ast"
 	gopanalysis "golang.org/x/tools/gop/analysis"
+	"golang.org/x/tools/gop/goputil"
 
 	"github.com/goplus/gop/x/typesutil"
 
@@ -304,6 +306,17 @@ func (snapshot *snapshot) Analyze(ctx context.Context, pkgs map[PackageID]unit,
 				}
 				an.gopFiles[i] = fh
 			}
+
+			// goxls: the Go code generated for the Go+ files
+			for _, uri := range m.GoFiles {
+				if goputil.IsAutogen(filepath.Base(uri.Filename())) {
+					fh, err := snapshot.ReadFile(ctx, uri)
+					if err != nil {
+						return nil, err
+					}
+					an.genGoFiles = append(an.genGoFiles, fh)
+				}
+			}
 		}
 		// Add edge from predecessor.
 		if from != nil {
@@ -526,7 +539,8 @@ type analysisNode struct {
 	typesErr  error          // an error producing type information
 
 	// goxls: Go+ files
-	gopFiles []source.FileHandle // contents of CompiledGopFiles
+	gopFiles   []source.FileHandle // contents of CompiledGopFiles
+	genGoFiles []source.FileHandle // contents of the gop_autogen*.go files of GoFiles
 }
 
 func (an *analysisNode) String() string { return string(an.m.ID) }
@@ -749,6 +763,16 @@ func (an *analysisNode) cacheKey() [sha256.Size]byte {
 		fmt.Fprintln(hasher, fh.FileIdentity())
 	}
 
+	// goxls: Go+ files and the Go code generated for them
+	fmt.Fprintf(hasher, "gop files: %d\n", len(an.gopFiles))
+	for _, fh := range an.gopFiles {
+		fmt.Fprintln(hasher, fh.FileIdentity())
+	}
+	fmt.Fprintf(hasher, "generated files: %d\n", len(an.genGoFiles))
+	for _, fh := range an.genGoFiles {
+		fmt.Fprintln(hasher, fh.FileIdentity())
+	}
+
 	// vdeps, in PackageID order
 	depIDs := make([]string, 0, len(an.succs))
 	for depID := range an.succs {
@@ -956,6 +980,7 @@ func (an *analysisNode) typeCheck(parsed []*source.ParsedGoFile, gopParsed []*so
 		gopParsed:    gopParsed,
 		gopFiles:     make([]*gopast.File, len(gopParsed)),
 		gopTypesInfo: newGopTypeInfo(),
+		genGoFiles:   an.genGoFiles,
 	}
 	typeparams.InitInstanceInfo(pkg.typesInfo)
 
@@ -1159,6 +1184,7 @@ type analysisPackage struct {
 	gopParsed    []*source.ParsedGopFile
 	gopFiles     []*gopast.File // same as gopParsed[i].File
 	gopTypesInfo *typesutil.Info
+	genGoFiles   []source.FileHandle // the Go code generated for the Go+ files
 }
 
 // An action represents one unit of analysis work: the application of
@@ -1385,6 +1411,18 @@ func (act *action) exec() (interface{}, *actionSummary, error) {
 		ResultOf:     gopInputs,
 		GopFiles:     pkg.gopFiles,
 		GopTypesInfo: pkg.gopTypesInfo,
+		GenGoFiles:   make([]string, len(pkg.genGoFiles)),
+		ReadFile: func(filename string) ([]byte, error) {
+			for _, fh := range pkg.genGoFiles {
+				if fh.URI().Filename() == filename {
+					return fh.Content()
+				}
+			}
+			return nil, fmt.Errorf("%s is not a generated file of package %s", filename, pkg.m.PkgPath)
+		},
+	}
+	for i, fh := range pkg.genGoFiles {
+		pass.GenGoFiles[i] = fh.URI().Filename()
 	}
 	pass.SetAnalyzer(analyzer)
 
@@ -21,7 +21,10 @@ import (
 	"sync/atomic"
 
 	gopast "github.com/goplus/gop/ast"
+	gopparser "github.com/goplus/gop/parser"
 	"github.com/goplus/gop/x/typesutil"
+	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
 	"golang.org/x/mod/module"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/tools/go/ast/astutil"
@@ -1276,7 +1279,11 @@ func (s *snapshot) typerefs(ctx context.Context, m *source.Metadata, cgfs, gopCg
 		}
 	}
 
-	data, err := s.typerefData(ctx, m.ID, imports, cgfs, gopCgfs)
+	var mod *gopmod.Module
+	if len(gopCgfs) > 0 {
+		mod = m.GopMod_()
+	}
+	data, err := s.typerefData(ctx, m.ID, mod, imports, cgfs, gopCgfs)
 	if err != nil {
 		return nil, err
 	}
@@ -1293,8 +1300,8 @@ func (s *snapshot) typerefs(ctx context.Context, m *source.Metadata, cgfs, gopCg
 // goxls: add Go+ files
 // typerefData retrieves encoded typeref data from the filecache, or computes it on
 // a cache miss.
-func (s *snapshot) typerefData(ctx context.Context, id PackageID, imports map[ImportPath]*source.Metadata, cgfs, gopCgfs []source.FileHandle) ([]byte, error) {
-	key := typerefsKey(id, imports, cgfs, gopCgfs)
+func (s *snapshot) typerefData(ctx context.Context, id PackageID, mod *gopmod.Module, imports map[ImportPath]*source.Metadata, cgfs, gopCgfs []source.FileHandle) ([]byte, error) {
+	key := typerefsKey(id, mod, imports, cgfs, gopCgfs)
 	if data, err := filecache.Get(typerefsKind, key); err == nil {
 		return data, nil
 	} else if err != filecache.ErrNotFound {
@@ -1305,7 +1312,19 @@ func (s *snapshot) typerefData(ctx context.Context, id PackageID, imports map[Im
 	if err != nil {
 		return nil, err
 	}
-	data := typerefs.Encode(pgfs, id, imports)
+	// goxls: Go+ files
+	// data := typerefs.Encode(pgfs, id, imports)
+	var gopPgfs []*source.ParsedGopFile
+	if len(gopCgfs) > 0 {
+		// Function bodies are not purged from Go+ files: the elements
+		// of a Go+ map literal {k: v}, which has no type expression,
+		// determine its type.
+		gopPgfs, err = s.view.parseCache.parseGopFiles(ctx, mod, token.NewFileSet(), parserutil.ParseFull&^gopparser.ParseComments, false, gopCgfs...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data := typerefs.EncodeGop(pgfs, gopPgfs, mod, id, imports)
 
 	// Store the resulting data in the cache.
 	go func() {
@@ -1320,7 +1339,7 @@ func (s *snapshot) typerefData(ctx context.Context, id PackageID, imports map[Im
 // goxls: add Go+ files & use NongenGoFiles
 // typerefsKey produces a key for the reference information produced by the
 // typerefs package.
-func typerefsKey(id PackageID, imports map[ImportPath]*source.Metadata, compiledNongenGoFiles, compiledGopFiles []source.FileHandle) source.Hash {
+func typerefsKey(id PackageID, mod *gopmod.Module, imports map[ImportPath]*source.Metadata, compiledNongenGoFiles, compiledGopFiles []source.FileHandle) source.Hash {
 	hasher := sha256.New()
 
 	fmt.Fprintf(hasher, "typerefs: %s\n", id)
@@ -1347,6 +1366,15 @@ func typerefsKey(id PackageID, imports map[ImportPath]*source.Metadata, compiled
 	fmt.Fprintf(hasher, "compiledGopFiles: %d\n", len(compiledGopFiles))
 	for _, fh := range compiledGopFiles {
 		fmt.Fprintln(hasher, fh.FileIdentity())
+		// The implicit class types depend on the classfiles of the module.
+		if mod != nil {
+			if proj, ok := mod.LookupClass(modfile.ClassExt(fh.URI().Filename())); ok {
+				fmt.Fprintf(hasher, "classfile: %s %v\n", proj.Class, proj.PkgPaths)
+				for _, w := range proj.Works {
+					fmt.Fprintf(hasher, "work: %s %s\n", w.Ext, w.Class)
+				}
+			}
+		}
 	}
 
 	var hash [sha256.Size]byte
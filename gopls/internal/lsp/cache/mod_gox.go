@@ -0,0 +1,157 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/goplus/mod/modfile"
+	"github.com/qiniu/x/errors"
+	gomodfile "golang.org/x/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+	"golang.org/x/tools/internal/event/tag"
+	"golang.org/x/tools/internal/memoize"
+)
+
+// ParseGopMod parses a gop.mod file, using a cache. It may return partial results and an error.
+// See ParseMod.
+func (s *snapshot) ParseGopMod(ctx context.Context, fh source.FileHandle) (*source.ParsedGopModule, error) {
+	uri := fh.URI()
+
+	// gop.mod files share parseModHandles with go.mod files: their URIs differ.
+	s.mu.Lock()
+	entry, hit := s.parseModHandles.Get(uri)
+	s.mu.Unlock()
+
+	type parseGopModKey source.FileIdentity
+	type parseGopModResult struct {
+		parsed *source.ParsedGopModule
+		err    error
+	}
+
+	// cache miss?
+	if !hit {
+		promise, release := s.store.Promise(parseGopModKey(fh.FileIdentity()), func(ctx context.Context, _ interface{}) interface{} {
+			parsed, err := parseGopModImpl(ctx, fh)
+			return parseGopModResult{parsed, err}
+		})
+
+		entry = promise
+		s.mu.Lock()
+		s.parseModHandles.Set(uri, entry, func(_, _ interface{}) { release() })
+		s.mu.Unlock()
+	}
+
+	// Await result.
+	v, err := s.awaitPromise(ctx, entry.(*memoize.Promise))
+	if err != nil {
+		return nil, err
+	}
+	res := v.(parseGopModResult)
+	return res.parsed, res.err
+}
+
+// parseGopModImpl parses the gop.mod file whose name and contents are in fh.
+// It may return partial results and an error: the file of a gop.mod with
+// invalid directives holds its valid ones.
+func parseGopModImpl(ctx context.Context, fh source.FileHandle) (*source.ParsedGopModule, error) {
+	_, done := event.Start(ctx, "cache.ParseGopMod", tag.URI.Of(fh.URI()))
+	defer done()
+
+	contents, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	m := protocol.NewMapper(fh.URI(), contents)
+	file, parseErr := modfile.Parse(fh.URI().Filename(), contents, nil)
+	// Attempt to convert the error to a standardized parse error.
+	var parseErrors []*source.Diagnostic
+	if parseErr != nil {
+		addError := func(pos gomodfile.Position, msg string) error {
+			rng, err := m.OffsetRange(pos.Byte, pos.Byte)
+			if err != nil {
+				return err
+			}
+			parseErrors = append(parseErrors, &source.Diagnostic{
+				URI:      fh.URI(),
+				Range:    rng,
+				Severity: protocol.SeverityError,
+				Source:   source.ParseError,
+				Message:  msg,
+			})
+			return nil
+		}
+		switch errs := errors.Err(parseErr).(type) {
+		case gomodfile.ErrorList: // syntax errors
+			for _, mfErr := range errs {
+				if err := addError(mfErr.Pos, mfErr.Err.Error()); err != nil {
+					return nil, err
+				}
+			}
+		case modfile.ErrorList: // invalid directives
+			for _, e := range errs {
+				mfErr, ok := e.(*modfile.Error)
+				if !ok {
+					return nil, fmt.Errorf("unexpected parse error type %v", e)
+				}
+				if err := addError(mfErr.Pos, errors.Summary(mfErr.Err)); err != nil {
+					return nil, err
+				}
+			}
+			file = parseValidGopMod(fh.URI().Filename(), contents, errs)
+		default:
+			return nil, fmt.Errorf("unexpected parse error type %v", parseErr)
+		}
+	}
+	return &source.ParsedGopModule{
+		URI:         fh.URI(),
+		Mapper:      m,
+		File:        file,
+		ParseErrors: parseErrors,
+	}, parseErr
+}
+
+// parseValidGopMod parses the directives of a gop.mod file but the invalid
+// ones, whose lines are blanked out so that positions are preserved. As the
+// directives of a project depend on it, this is repeated until no error is
+// left. It returns nil if that fails.
+func parseValidGopMod(filename string, contents []byte, errs modfile.ErrorList) *modfile.File {
+	valid := append([]byte(nil), contents...)
+	for {
+		changed := false
+		for _, e := range errs {
+			mfErr, ok := e.(*modfile.Error)
+			if !ok || mfErr.Pos.Byte < 0 || mfErr.Pos.Byte > len(valid) {
+				return nil
+			}
+			start := bytes.LastIndexByte(valid[:mfErr.Pos.Byte], '\n') + 1
+			end := len(valid)
+			if i := bytes.IndexByte(valid[mfErr.Pos.Byte:], '\n'); i >= 0 {
+				end = mfErr.Pos.Byte + i
+			}
+			for i := start; i < end; i++ {
+				if valid[i] != ' ' {
+					valid[i], changed = ' ', true
+				}
+			}
+		}
+		if !changed {
+			return nil
+		}
+		file, err := modfile.Parse(filename, valid, nil)
+		if err == nil {
+			return file
+		}
+		list, ok := errors.Err(err).(modfile.ErrorList)
+		if !ok {
+			return nil
+		}
+		errs = list
+	}
+}
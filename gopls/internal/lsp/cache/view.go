@@ -412,6 +412,11 @@ func (v *View) Options() *source.Options {
 }
 
 func (v *View) FileKind(fh source.FileHandle) source.FileKind {
+	// goxls: gop.mod may be opened as a go.mod buffer
+	if filepath.Base(fh.URI().Filename()) == "gop.mod" {
+		return source.GopMod
+	}
+
 	// The kind of an unsaved buffer comes from the
 	// TextDocumentItem.LanguageID field in the didChange event,
 	// not from the file name. They may differ.
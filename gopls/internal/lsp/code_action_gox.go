@@ -119,14 +119,14 @@ func (s *Server) gopCodeAction(
 	var stubMethodsDiagnostics []protocol.Diagnostic
 	if wantQuickFixes && snapshot.View().Options().IsAnalyzerEnabled(stubmethods.Analyzer.Name) {
 		for _, pd := range diagnostics {
-			if stubmethods.MatchesMessage(pd.Message) {
+			if stubmethods.GopMatchesMessage(pd.Message) {
 				stubMethodsDiagnostics = append(stubMethodsDiagnostics, pd)
 			}
 		}
 	}
 
 	// Code actions requiring type information.
-	if len(stubMethodsDiagnostics) > 0 || want[protocol.RefactorRewrite] || want[protocol.GoTest] {
+	if len(stubMethodsDiagnostics) > 0 || want[protocol.RefactorRewrite] || want[protocol.RefactorInline] || want[protocol.GoTest] {
 		pkg, pgf, err := source.NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
 		if err != nil {
 			return nil, err
@@ -182,6 +182,14 @@ func (s *Server) gopCodeAction(
 			actions = append(actions, rewrites...)
 		}
 
+		if want[protocol.RefactorInline] {
+			rewrites, err := gopRefactorInline(pkg, pgf, params.Range)
+			if err != nil {
+				return nil, err
+			}
+			actions = append(actions, rewrites...)
+		}
+
 		if want[protocol.GoTest] {
 			fixes, err := gopTest(ctx, snapshot, pkg, pgf, params.Range)
 			if err != nil {
@@ -340,6 +348,44 @@ func gopRefactorRewrite(ctx context.Context, snapshot source.Snapshot, pkg sourc
 	return actions, nil
 }
 
+// gopRefactorInline returns inline actions available at the specified range.
+func gopRefactorInline(pkg source.Package, pgf *source.ParsedGopFile, rng protocol.Range) ([]protocol.CodeAction, error) {
+	var commands []protocol.Command
+
+	// If range is within call expression, offer inline action.
+	if call, fn, err := source.GopEnclosingStaticCall(pkg, pgf, rng); err == nil {
+		// Use the name of the call site, which differs from the callee
+		// for overloaded functions and lowercase Go+ calls.
+		name := fn.Name()
+		switch fun := call.Fun.(type) {
+		case *ast.Ident:
+			name = fun.Name
+		case *ast.SelectorExpr:
+			name = fun.Sel.Name
+		}
+		cmd, err := command.NewApplyFixCommand(fmt.Sprintf("Inline call to %s", name), command.ApplyFixArgs{
+			URI:   protocol.URIFromSpanURI(pgf.URI),
+			Fix:   source.InlineCall,
+			Range: rng,
+		})
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+
+	// Convert commands to actions.
+	var actions = make([]protocol.CodeAction, len(commands))
+	for i := range commands {
+		actions[i] = protocol.CodeAction{
+			Title:   commands[i].Title,
+			Kind:    protocol.RefactorInline,
+			Command: &commands[i],
+		}
+	}
+	return actions, nil
+}
+
 func gopTest(ctx context.Context, snapshot source.Snapshot, pkg source.Package, pgf *source.ParsedGopFile, rng protocol.Range) ([]protocol.CodeAction, error) {
 	fns, err := source.GopTestsAndBenchmarks(ctx, snapshot, pkg, pgf)
 	if err != nil {
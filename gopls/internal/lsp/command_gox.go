@@ -5,13 +5,26 @@
 package lsp
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
 	"sort"
+	"strconv"
+	"strings"
 
 	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gop/langserver"
 	"golang.org/x/tools/gopls/internal/goxls/parserutil"
 	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/progress"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
 	"golang.org/x/tools/internal/tokeninternal"
 )
 
@@ -51,6 +64,188 @@ func gopListImportsCmd(result *command.ListImportsResult, ctx context.Context, a
 	return nil
 }
 
+// RunGopCommand runs `gop <command> [args...]` in the directory args.URI.
+// The output is streamed to the client log, and failures located in Go+
+// sources are reported as diagnostics.
 func (c *commandHandler) RunGopCommand(ctx context.Context, args command.RunGopCommandArgs) error {
-	return nil
+	switch args.Command {
+	case "run", "build", "test":
+	default:
+		return fmt.Errorf("unsupported gop command: %q", args.Command)
+	}
+	return c.run(ctx, commandConfig{
+		async:       true,
+		progress:    "Running gop " + args.Command,
+		requireSave: true,
+		forURI:      args.URI,
+	}, func(ctx context.Context, deps commandDeps) error {
+		if err := c.runGopCommand(ctx, deps.snapshot, deps.work, args); err != nil {
+			return fmt.Errorf("running gop %s failed: %w", args.Command, err)
+		}
+		return nil
+	})
+}
+
+func (c *commandHandler) runGopCommand(ctx context.Context, snapshot source.Snapshot, work *progress.WorkDone, args command.RunGopCommandArgs) error {
+	dir := args.URI.SpanURI().Filename()
+	if fi, err := os.Stat(dir); err == nil && !fi.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	// Diagnostics of a previous run are obsolete now.
+	c.s.clearDiagnosticSource(gopCommandSource)
+
+	// create output
+	buf := &bytes.Buffer{}
+	ew := progress.NewEventWriter(ctx, "gop")
+	out := io.MultiWriter(ew, progress.NewWorkDoneWriter(ctx, work), buf)
+
+	cmdArgs := append([]string{args.Command}, args.Args...)
+	if args.Command == "run" && len(args.Args) == 0 {
+		cmdArgs = append(cmdArgs, ".") // unlike build and test, run needs a package
+	}
+	cmd := exec.CommandContext(ctx, langserver.LookupCmd("gop"), cmdArgs...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), snapshot.View().Options().EnvSlice()...)
+	cmd.Stdout, cmd.Stderr = out, out
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	for uri, diags := range gopCommandDiagnostics(ctx, snapshot, dir, buf.String()) {
+		c.s.storeDiagnostics(snapshot, uri, gopCommandSource, diags, true)
+	}
+	c.s.publishDiagnostics(ctx, true, snapshot)
+
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return runErr // e.g. gop not found
+		}
+		return c.s.client.ShowMessage(ctx, &protocol.ShowMessageParams{
+			Type:    protocol.Error,
+			Message: fmt.Sprintf("gop %s failed:\n%s", args.Command, gopTruncateOutput(buf.String(), gopMaxMessageLines)),
+		})
+	}
+	return c.s.client.ShowMessage(ctx, &protocol.ShowMessageParams{
+		Type:    protocol.Info,
+		Message: fmt.Sprintf("gop %s completed", args.Command),
+	})
+}
+
+// gopMaxMessageLines is the number of lines of the output of a failed gop
+// command shown to the user. The whole output is in the client log.
+const gopMaxMessageLines = 10
+
+// gopTruncateOutput returns the first max lines of output, followed by a
+// note on the number of lines left out.
+func gopTruncateOutput(output string, max int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) <= max {
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("%s\n... (%d more lines in the log)", strings.Join(lines[:max], "\n"), len(lines)-max)
+}
+
+// gopErrorLine matches the "file:line[:col]: message" lines reported by
+// the gop command, including the indented ones of failed tests. The file
+// may start with a Windows drive letter.
+var gopErrorLine = regexp.MustCompile(`^\s*((?:[A-Za-z]:)?[^\s:][^:]*):(\d+)(?::(\d+))?: (.*)$`)
+
+// gopParseErrorLine parses a line of the output of a gop command matching
+// gopErrorLine. The column is 0 if the line has none.
+func gopParseErrorLine(line string) (file string, lineNum, col int, msg string, ok bool) {
+	m := gopErrorLine.FindStringSubmatch(line)
+	if m == nil {
+		return "", 0, 0, "", false
+	}
+	lineNum, _ = strconv.Atoi(m[2])
+	col, _ = strconv.Atoi(m[3])
+	return m[1], lineNum, col, m[4], true
+}
+
+// gopCommandDiagnostics returns the diagnostics for the errors of Go+ files
+// found in the output of a gop command run in dir.
+func gopCommandDiagnostics(ctx context.Context, snapshot source.Snapshot, dir, output string) map[span.URI][]*source.Diagnostic {
+	reports := make(map[span.URI][]*source.Diagnostic)
+	mappers := make(map[span.URI]*protocol.Mapper)
+	for _, line := range strings.Split(output, "\n") {
+		file, lineNum, col, msg, ok := gopParseErrorLine(line)
+		if !ok {
+			continue
+		}
+		uri := span.URIFromPath(gopCommandFile(dir, file))
+		mapper, ok := mappers[uri]
+		if !ok {
+			if fh, err := snapshot.ReadFile(ctx, uri); err == nil && snapshot.View().FileKind(fh) == source.Gop {
+				if content, err := fh.Content(); err == nil {
+					mapper = protocol.NewMapper(uri, content)
+				}
+			}
+			mappers[uri] = mapper
+		}
+		if mapper == nil {
+			continue // not a Go+ file
+		}
+		rng, err := gopLineColRange(mapper, lineNum, col)
+		if err != nil {
+			continue
+		}
+		reports[uri] = append(reports[uri], &source.Diagnostic{
+			URI:      uri,
+			Range:    rng,
+			Severity: protocol.SeverityError,
+			Source:   source.GopCommandError,
+			Message:  msg,
+		})
+	}
+	return reports
+}
+
+// gopCommandFile returns the path of the file name reported by a gop command
+// run in dir. Relative names are relative to the module root, which is dir
+// or one of its parents.
+func gopCommandFile(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	for d := dir; ; {
+		filename := filepath.Join(d, name)
+		if _, err := os.Stat(filename); err == nil {
+			return filename
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return filepath.Join(dir, name)
+		}
+		d = parent
+	}
+}
+
+// gopLineColRange returns the range of the 1-based line and byte column in
+// m's file. Without a column (col == 0), the range covers the whole line.
+func gopLineColRange(m *protocol.Mapper, line, col int) (protocol.Range, error) {
+	if line < 1 {
+		return protocol.Range{}, fmt.Errorf("invalid line %d", line)
+	}
+	content := m.Content
+	start := 0
+	for i := 1; i < line; i++ {
+		nl := bytes.IndexByte(content[start:], '\n')
+		if nl < 0 {
+			return protocol.Range{}, fmt.Errorf("line %d is out of range", line)
+		}
+		start += nl + 1
+	}
+	end := len(content)
+	if nl := bytes.IndexByte(content[start:], '\n'); nl >= 0 {
+		end = start + nl
+	}
+	if col > 0 {
+		if start += col - 1; start > end {
+			start = end
+		}
+		end = start
+	}
+	return m.OffsetRange(start, end)
 }
@@ -0,0 +1,84 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+)
+
+func TestGopParseErrorLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		ok      bool
+		file    string
+		lineNum int
+		col     int
+		msg     string
+	}{
+		{"main.gop:3:5: undefined: foo", true, "main.gop", 3, 5, "undefined: foo"},
+		{"./bad/bad.gop:12: missing return", true, "./bad/bad.gop", 12, 0, "missing return"},
+		{"    foo_test.gop:7: want 1, got 2", true, "foo_test.gop", 7, 0, "want 1, got 2"},
+		{"/home/me/demo/main.gmx:1:10: call of Foo", true, "/home/me/demo/main.gmx", 1, 10, "call of Foo"},
+		{`C:\Users\me\demo\main.gop:3:5: undefined: foo`, true, `C:\Users\me\demo\main.gop`, 3, 5, "undefined: foo"},
+		{`d:\demo\Kai.spx:2: syntax error`, true, `d:\demo\Kai.spx`, 2, 0, "syntax error"},
+		{"# mod.test/demo", false, "", 0, 0, ""},
+		{"--- FAIL: TestFoo (0.00s)", false, "", 0, 0, ""},
+		{"FAIL\tmod.test/demo\t0.005s", false, "", 0, 0, ""},
+		{"exit status 1", false, "", 0, 0, ""},
+		{"main.gop:x:5: not a line", false, "", 0, 0, ""},
+		{"", false, "", 0, 0, ""},
+	}
+	for _, test := range tests {
+		file, lineNum, col, msg, ok := gopParseErrorLine(test.line)
+		if ok != test.ok || file != test.file || lineNum != test.lineNum || col != test.col || msg != test.msg {
+			t.Errorf("gopParseErrorLine(%q) = %q, %d, %d, %q, %v, want %q, %d, %d, %q, %v",
+				test.line, file, lineNum, col, msg, ok, test.file, test.lineNum, test.col, test.msg, test.ok)
+		}
+	}
+}
+
+func TestGopLineColRange(t *testing.T) {
+	m := protocol.NewMapper("file:///demo/main.gop", []byte("import \"fmt\"\n\nfmt.println \"hi\"\n"))
+	tests := []struct {
+		line, col int
+		want      protocol.Range // zero if an error is expected
+	}{
+		{1, 8, protocol.Range{Start: protocol.Position{Line: 0, Character: 7}, End: protocol.Position{Line: 0, Character: 7}}},
+		{3, 0, protocol.Range{Start: protocol.Position{Line: 2, Character: 0}, End: protocol.Position{Line: 2, Character: 16}}},
+		{2, 0, protocol.Range{Start: protocol.Position{Line: 1, Character: 0}, End: protocol.Position{Line: 1, Character: 0}}},
+		{3, 100, protocol.Range{Start: protocol.Position{Line: 2, Character: 16}, End: protocol.Position{Line: 2, Character: 16}}},
+		{4, 0, protocol.Range{Start: protocol.Position{Line: 3, Character: 0}, End: protocol.Position{Line: 3, Character: 0}}},
+		{0, 1, protocol.Range{}},
+		{5, 1, protocol.Range{}},
+	}
+	for _, test := range tests {
+		got, err := gopLineColRange(m, test.line, test.col)
+		if test.want == (protocol.Range{}) {
+			if err == nil {
+				t.Errorf("gopLineColRange(%d, %d) = %v, want error", test.line, test.col, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("gopLineColRange(%d, %d) failed: %v", test.line, test.col, err)
+		} else if got != test.want {
+			t.Errorf("gopLineColRange(%d, %d) = %v, want %v", test.line, test.col, got, test.want)
+		}
+	}
+}
+
+func TestGopTruncateOutput(t *testing.T) {
+	if got, want := gopTruncateOutput("a\nb\n", 2), "a\nb"; got != want {
+		t.Errorf("gopTruncateOutput = %q, want %q", got, want)
+	}
+	output := strings.Repeat("main.gop:1: error\n", 15)
+	got := gopTruncateOutput(output, 10)
+	if want := strings.Repeat("main.gop:1: error\n", 10) + "... (5 more lines in the log)"; got != want {
+		t.Errorf("gopTruncateOutput = %q, want %q", got, want)
+	}
+}
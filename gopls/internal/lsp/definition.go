@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 
+	"golang.org/x/tools/gopls/internal/lsp/mod"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
 	"golang.org/x/tools/gopls/internal/lsp/template"
@@ -31,6 +32,8 @@ func (s *Server) definition(ctx context.Context, params *protocol.DefinitionPara
 		return template.Definition(snapshot, fh, params.Position)
 	case source.Gop: // goxls: Go+
 		return source.GopDefinition(ctx, snapshot, fh, params.Position)
+	case source.GopMod: // goxls: gop.mod
+		return mod.GopModDefinition(ctx, snapshot, fh, params.Position)
 	case source.Go:
 		// Partial support for jumping from linkname directive (position at 2nd argument).
 		locations, err := source.LinknameDefinition(ctx, snapshot, fh, params.Position)
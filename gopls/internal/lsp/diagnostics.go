@@ -49,6 +49,8 @@ const (
 	workSource
 	modCheckUpgradesSource
 	modVulncheckSource // source.Govulncheck + source.Vulncheck
+	gopCommandSource   // goxls: Go+ - failures of `gop run/build/test`
+	gopModParseSource  // goxls: Go+ - gop.mod files
 )
 
 // A diagnosticReport holds results for a single diagnostic source.
@@ -111,6 +113,10 @@ func (d diagnosticSource) String() string {
 		return "FromCheckForUpgrades"
 	case modVulncheckSource:
 		return "FromModVulncheck"
+	case gopCommandSource: // goxls: Go+
+		return "FromGopCommand"
+	case gopModParseSource: // goxls: Go+
+		return "FromGopModParse"
 	default:
 		return fmt.Sprintf("From?%d?", d)
 	}
@@ -305,6 +311,13 @@ func (s *Server) diagnose(ctx context.Context, snapshot source.Snapshot, analyze
 	}
 	store(modParseSource, "diagnosing go.mod file", modReports, modErr, true)
 
+	// goxls: Diagnose gop.mod file.
+	gopModReports, gopModErr := mod.GopModDiagnostics(ctx, snapshot)
+	if ctx.Err() != nil {
+		return
+	}
+	store(gopModParseSource, "diagnosing gop.mod file", gopModReports, gopModErr, true)
+
 	// Diagnose go.mod upgrades.
 	upgradeReports, upgradeErr := mod.UpgradeDiagnostics(ctx, snapshot)
 	if ctx.Err() != nil {
@@ -493,6 +493,7 @@ var defaultFileAssociations = map[string]*regexp.Regexp{
 	"go.sum":  regexp.MustCompile(`^go(\.work)?\.sum$`),
 	"go.work": regexp.MustCompile(`^go\.work$`),
 	"gotmpl":  regexp.MustCompile(`^.*tmpl$`),
+	"gop":     regexp.MustCompile(`^.*\.(gop|gox|spx|gmx)$`), // goxls: Go+
 }
 
 // languageID returns the language identifier for the path p given the user
@@ -33,6 +33,8 @@ func (s *Server) formatting(ctx context.Context, params *protocol.DocumentFormat
 		return work.Format(ctx, snapshot, fh)
 	case source.Gop: // goxls: format Go+ files
 		return source.FormatGop(ctx, snapshot, fh)
+	case source.GopMod: // goxls: format gop.mod files
+		return mod.GopModFormat(ctx, snapshot, fh)
 	}
 	return nil, nil
 }
@@ -1,11 +1,14 @@
 package lsp
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"golang.org/x/tools/gopls/internal/lsp/cache"
 	"golang.org/x/tools/gopls/internal/lsp/debug"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/source"
 	"golang.org/x/tools/gopls/internal/lsp/tests"
 	"golang.org/x/tools/gopls/internal/span"
@@ -30,6 +33,10 @@ func testXLS(t *testing.T, datum *tests.Data) {
 	session := cache.NewSession(ctx, cache.New(nil), nil)
 	options := source.DefaultOptions().Clone()
 	tests.DefaultOptions(options)
+	// Semantic tokens of all the types and modifiers, as a client supporting
+	// them would request.
+	options.SemanticTypes = SemanticTypes()
+	options.SemanticMods = SemanticModifiers()
 	session.SetOptions(options)
 	options.SetEnvSlice(datum.Config.Env)
 	view, snapshot, release, err := session.NewView(ctx, datum.Config.Dir, span.URIFromPath(datum.Config.Dir), options)
@@ -99,3 +106,42 @@ func testXLS(t *testing.T, datum *tests.Data) {
 	r.server = NewServer(session, testClient{runner: r})
 	tests.Run(t, r, datum)
 }
+
+// gopSemanticTokens compares the semantic tokens of the Go+ file uri, one
+// per line as "line:col text type [modifiers]", with its golden file.
+func (r *runner) gopSemanticTokens(t *testing.T, uri span.URI) {
+	m, err := r.data.Mapper(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := r.server.semanticTokensFull(r.ctx, &protocol.SemanticTokensParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.URIFromSpanURI(uri)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	var line, char uint32
+	for d := tokens.Data; len(d) >= 5; d = d[5:] {
+		if d[0] > 0 {
+			char = 0
+		}
+		line, char = line+d[0], char+d[1]
+		start, err := m.PositionOffset(protocol.Position{Line: line, Character: char})
+		if err != nil {
+			t.Fatal(err)
+		}
+		end, err := m.PositionOffset(protocol.Position{Line: line, Character: char + d[2]})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&b, "%d:%d %s %s %v\n", line+1, char+1, m.Content[start:end], SemType(int(d[3])), SemMods(int(d[4])))
+	}
+	got := b.String()
+	want := string(r.data.Golden(t, "semantic", uri.Filename(), func() ([]byte, error) {
+		return []byte(got), nil
+	}))
+	if got != want {
+		t.Errorf("semantic tokens of %s:\ngot:\n%s\nwant:\n%s", uri.Filename(), got, want)
+	}
+}
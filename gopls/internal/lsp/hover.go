@@ -32,6 +32,8 @@ func (s *Server) hover(ctx context.Context, params *protocol.HoverParams) (*prot
 		return source.Hover(ctx, snapshot, fh, params.Position)
 	case source.Gop: // goxls: Go+
 		return source.GopHover(ctx, snapshot, fh, params.Position)
+	case source.GopMod: // goxls: gop.mod
+		return mod.GopModHover(ctx, snapshot, fh, params.Position)
 	case source.Tmpl:
 		return template.Hover(ctx, snapshot, fh, params.Position)
 	case source.Work:
@@ -0,0 +1,233 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+)
+
+// gopLinks returns the set of hyperlink annotations for the specified Go+ file.
+// See goLinks.
+func gopLinks(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) ([]protocol.DocumentLink, error) {
+	view := snapshot.View()
+
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []protocol.DocumentLink
+
+	// Create links for import specs.
+	if view.Options().ImportShortcut.ShowLinks() {
+
+		// If links are to pkg.go.dev, append module version suffixes.
+		// This requires the import map from the package metadata, and the
+		// Go+ module (gop.mod) for the classfile frameworks and Go+ packages
+		// it depends on. Ignore errors.
+		var depsByImpPath map[source.ImportPath]source.PackageID
+		var gopMod *gopmod.Module
+		if strings.ToLower(view.Options().LinkTarget) == "pkg.go.dev" {
+			if meta, err := source.NarrowestMetadataForFile(ctx, snapshot, fh.URI()); err == nil {
+				depsByImpPath = meta.DepsByImpPath
+				gopMod = meta.GopMod_()
+			}
+		}
+
+		for _, imp := range pgf.File.Imports {
+			importPath := source.GopUnquoteImportPath(imp)
+			if importPath == "" {
+				continue // bad import
+			}
+			// See golang/go#36998: don't link to modules matching GOPRIVATE.
+			if view.IsGoPrivatePath(string(importPath)) {
+				continue
+			}
+
+			urlPath := string(importPath)
+
+			// For pkg.go.dev, append module version suffix to package import path.
+			if m := snapshot.Metadata(depsByImpPath[importPath]); m != nil && m.Module != nil && m.Module.Path != "" && m.Module.Version != "" {
+				urlPath = strings.Replace(urlPath, m.Module.Path, m.Module.Path+"@"+m.Module.Version, 1)
+			} else if mod, ok := gopLookupDepMod(gopMod, urlPath); ok {
+				urlPath = strings.Replace(urlPath, mod.Path, mod.Path+"@"+mod.Version, 1)
+			}
+
+			start, end, err := safetoken.Offsets(pgf.Tok, imp.Path.Pos(), imp.Path.End())
+			if err != nil {
+				return nil, err
+			}
+			targetURL := source.BuildLink(view.Options().LinkTarget, urlPath, "")
+			// Account for the quotation marks in the positions.
+			l, err := toProtocolLink(pgf.Mapper, targetURL, start+len(`"`), end-len(`"`))
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, l)
+		}
+	}
+
+	urlRegexp := snapshot.View().Options().URLRegexp
+
+	// Gather links found in string literals.
+	var str []*ast.BasicLit
+	ast.Inspect(pgf.File, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.ImportSpec:
+			return false // don't process import strings again
+		case *ast.BasicLit:
+			if n.Kind == token.STRING {
+				str = append(str, n)
+			}
+		}
+		return true
+	})
+	for _, s := range str {
+		strOffset, err := safetoken.Offset(pgf.Tok, s.Pos())
+		if err != nil {
+			return nil, err
+		}
+		l, err := findLinksInString(urlRegexp, s.Value, strOffset, pgf.Mapper)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l...)
+	}
+
+	// Gather links found in comments.
+	for _, commentGroup := range pgf.File.Comments {
+		for _, comment := range commentGroup.List {
+			commentOffset, err := safetoken.Offset(pgf.Tok, comment.Pos())
+			if err != nil {
+				return nil, err
+			}
+			l, err := findLinksInString(urlRegexp, comment.Text, commentOffset, pgf.Mapper)
+			if err != nil {
+				return nil, err
+			}
+			links = append(links, l...)
+		}
+	}
+
+	return links, nil
+}
+
+// gopModLinks returns the set of hyperlink annotations for the specified
+// gop.mod file: the classfile framework packages of its project directives
+// and the packages they auto-import. See modLinks.
+func gopModLinks(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) ([]protocol.DocumentLink, error) {
+	view := snapshot.View()
+
+	pm, err := snapshot.ParseGopMod(ctx, fh)
+	if err != nil && pm == nil {
+		return nil, err
+	}
+	if pm.File == nil || !view.Options().ImportShortcut.ShowLinks() {
+		return nil, nil
+	}
+
+	// If links are to pkg.go.dev, append module version suffixes, using the
+	// Go+ module of the go.mod file next to gop.mod. Ignore errors.
+	var gopMod *gopmod.Module
+	if strings.ToLower(view.Options().LinkTarget) == "pkg.go.dev" {
+		if metas, err := snapshot.AllMetadata(ctx); err == nil {
+			dir := filepath.Dir(fh.URI().Filename())
+			for _, m := range metas {
+				if m.Module != nil && m.Module.GoMod != "" && filepath.Dir(m.Module.GoMod) == dir {
+					gopMod = m.GopMod_()
+					break
+				}
+			}
+		}
+	}
+
+	var links []protocol.DocumentLink
+	addLinks := func(line *modfile.Line, pkgPaths ...string) error {
+		if line == nil {
+			return nil
+		}
+		start, end := line.Start.Byte, line.End.Byte
+		for _, tok := range line.Token {
+			i := bytes.Index(pm.Mapper.Content[start:end], []byte(tok))
+			if i == -1 {
+				break // This should not happen.
+			}
+			tokStart, tokEnd := start+i, start+i+len(tok)
+			start = tokEnd
+			pkgPath := tok
+			if s, err := strconv.Unquote(tok); err == nil {
+				pkgPath, tokStart, tokEnd = s, tokStart+len(`"`), tokEnd-len(`"`)
+			}
+			if !gopContains(pkgPaths, pkgPath) || view.IsGoPrivatePath(pkgPath) {
+				continue
+			}
+			urlPath := pkgPath
+			if mod, ok := gopLookupDepMod(gopMod, urlPath); ok {
+				urlPath = strings.Replace(urlPath, mod.Path, mod.Path+"@"+mod.Version, 1)
+			}
+			target := source.BuildLink(view.Options().LinkTarget, urlPath, "")
+			l, err := toProtocolLink(pm.Mapper, target, tokStart, tokEnd)
+			if err != nil {
+				return err
+			}
+			links = append(links, l)
+		}
+		return nil
+	}
+	for _, p := range pm.File.Projects {
+		if err := addLinks(p.Syntax, p.PkgPaths...); err != nil {
+			return nil, err
+		}
+		for _, imp := range p.Import {
+			if err := addLinks(imp.Syntax, imp.Path); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return links, nil
+}
+
+// gopContains reports whether s is in list.
+func gopContains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// gopLookupDepMod finds the module providing pkgPath among the modules the
+// Go+ module depends on, such as the classfile frameworks declared in gop.mod.
+// Replaced modules are not reported, as their versions don't refer to modPath.
+func gopLookupDepMod(mod *gopmod.Module, pkgPath string) (module.Version, bool) {
+	if mod == nil {
+		return module.Version{}, false
+	}
+	for modPath := pkgPath; ; {
+		if dep, ok := mod.LookupDepMod(modPath); ok {
+			return dep, dep.Path == modPath && dep.Version != ""
+		}
+		i := strings.LastIndex(modPath, "/")
+		if i < 0 {
+			return module.Version{}, false
+		}
+		modPath = modPath[:i]
+	}
+}
@@ -414,6 +414,9 @@ func (r *runner) SemanticTokens(t *testing.T, spn span.Span) {
 	if err != nil {
 		t.Errorf("%v for Range %s", err, filename)
 	}
+	if filepath.Ext(filename) == ".gop" { // goxls: compare the tokens of Go+ files
+		r.gopSemanticTokens(t, uri)
+	}
 }
 
 func (r *runner) SuggestedFix(t *testing.T, spn span.Span, actionKinds []tests.SuggestedFix, expectedActions int) {
@@ -0,0 +1,142 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modcache"
+	"github.com/goplus/mod/modfile"
+	"github.com/goplus/mod/modload"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// gopModVerbs are the directives of a gop.mod file.
+var gopModVerbs = []string{"gop", "project", "class", "import"}
+
+// GopModCompletion completes the directives of a gop.mod file, and the
+// classfile frameworks known to its module in project directives.
+//
+// It works on the text of the line at position, as the file being edited
+// often doesn't parse.
+func GopModCompletion(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, position protocol.Position) (*protocol.CompletionList, error) {
+	ctx, done := event.Start(ctx, "mod.GopModCompletion")
+	defer done()
+
+	content, err := fh.Content()
+	if err != nil {
+		return nil, err
+	}
+	m := protocol.NewMapper(fh.URI(), content)
+	cursor, err := m.PositionOffset(position)
+	if err != nil {
+		return nil, fmt.Errorf("computing cursor offset: %w", err)
+	}
+	lineStart := bytes.LastIndexByte(content[:cursor], '\n') + 1
+	line := string(content[lineStart:cursor])
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	line = line[indent:]
+
+	items := []protocol.CompletionItem{} // must be a slice
+	complete := func(label string, kind protocol.CompletionItemKind, detail string, start int) error {
+		rng, err := m.OffsetRange(start, cursor)
+		if err != nil {
+			return err
+		}
+		items = append(items, protocol.CompletionItem{
+			Label:    label,
+			Kind:     kind,
+			Detail:   detail,
+			TextEdit: &protocol.TextEdit{Range: rng, NewText: label},
+		})
+		return nil
+	}
+
+	verb, args, found := strings.Cut(line, " ")
+	switch {
+	case !found: // completing a directive
+		for _, v := range gopModVerbs {
+			if strings.HasPrefix(v, verb) {
+				if err := complete(v, protocol.KeywordCompletion, "", lineStart+indent); err != nil {
+					return nil, err
+				}
+			}
+		}
+	case verb == "project": // completing a classfile framework
+		args = strings.TrimLeft(args, " \t")
+		projs, err := gopKnownProjects(ctx, snapshot, fh.URI())
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projs {
+			label := formatGopProject(p)
+			if strings.HasPrefix(label, args) {
+				if err := complete(label, protocol.ModuleCompletion, "classfile framework", cursor-len(args)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return &protocol.CompletionList{Items: items}, nil
+}
+
+// formatGopProject returns the arguments of the project directive of p.
+func formatGopProject(p *modfile.Project) string {
+	args := p.PkgPaths
+	if p.Ext != "" {
+		args = append([]string{p.Ext, p.Class}, args...)
+	}
+	return strings.Join(args, " ")
+}
+
+// gopKnownProjects returns the classfile projects known to the module of
+// the gop.mod file uri: the builtin ones, and the ones of the classfile
+// framework modules it requires that are present in the module cache.
+func gopKnownProjects(ctx context.Context, snapshot source.Snapshot, uri span.URI) ([]*modfile.Project, error) {
+	mod, err := gopModule(ctx, snapshot, uri)
+	if err != nil {
+		return nil, err
+	}
+	projs := []*modfile.Project{gopmod.SpxProject, gopmod.GshProject}
+	if mod.Opt != nil {
+		for _, classMod := range mod.Opt.ClassMods {
+			modVer, ok := mod.LookupDepMod(classMod)
+			if !ok {
+				continue
+			}
+			dir, err := modcache.Path(modVer)
+			if err != nil {
+				continue
+			}
+			m, err := modload.Load(dir)
+			if err != nil {
+				continue // not downloaded yet
+			}
+			projs = append(projs, m.Projects()...)
+		}
+	}
+
+	// Remove the duplicates, e.g. of a builtin framework required explicitly.
+	seen := make(map[string]bool)
+	ret := projs[:0:0]
+	for _, p := range projs {
+		if key := formatGopProject(p); !seen[key] {
+			seen[key] = true
+			ret = append(ret, p)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].PkgPaths[0] < ret[j].PkgPaths[0]
+	})
+	return ret, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/gop/goputil"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// GopModDefinition returns the location of the framework package, or of the
+// class declared by it, for the project, class or import directive of a
+// gop.mod file at the given position.
+func GopModDefinition(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, position protocol.Position) ([]protocol.Location, error) {
+	ctx, done := event.Start(ctx, "mod.GopModDefinition")
+	defer done()
+
+	pm, err := snapshot.ParseGopMod(ctx, fh)
+	if pm == nil || pm.File == nil { // use the valid directives of a partial result
+		return nil, fmt.Errorf("getting gop.mod file handle: %w", err)
+	}
+	offset, err := pm.Mapper.PositionOffset(position)
+	if err != nil {
+		return nil, fmt.Errorf("computing cursor position: %w", err)
+	}
+	d := gopModDirectiveAt(pm, offset)
+	if d == nil || d.proj == nil {
+		return nil, nil
+	}
+
+	// Resolve the directive to a package, and the class in it if any.
+	pkgPath, class := d.proj.PkgPaths[0], ""
+	switch {
+	case d.imp != nil:
+		pkgPath = d.imp.Path
+	case d.class != nil:
+		class = d.class.Class
+	default:
+		class = d.proj.Class
+		if i, _, _ := gopModTokenAt(pm.Mapper.Content, d.line, offset); i != -1 {
+			tok := d.line.Token[i]
+			if s, err := strconv.Unquote(tok); err == nil {
+				tok = s
+			}
+			for _, path := range d.proj.PkgPaths {
+				if tok == path {
+					pkgPath, class = path, ""
+				}
+			}
+		}
+	}
+
+	metas, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var meta *source.Metadata
+	for _, m := range metas {
+		if string(m.PkgPath) == pkgPath && !m.IsIntermediateTestVariant() && m.ForTest == "" {
+			meta = m
+			break
+		}
+	}
+
+	var files []span.URI
+	if meta != nil {
+		if class != "" {
+			pkgs, err := snapshot.TypeCheck(ctx, meta.ID)
+			if err != nil {
+				return nil, err
+			}
+			pkg := pkgs[0]
+			if obj := pkg.GetTypes().Scope().Lookup(class); obj != nil && obj.Pos().IsValid() {
+				loc, err := gopModMapPosition(ctx, pkg.FileSet(), snapshot, obj.Pos(), obj.Pos()+token.Pos(len(class)))
+				if err != nil {
+					return nil, err
+				}
+				return []protocol.Location{loc}, nil
+			}
+		}
+		files = append(files, meta.CompiledNongenGoFiles...)
+		files = append(files, meta.CompiledGopFiles...)
+	} else {
+		// The package is not loaded, e.g. a framework without classfiles
+		// in the workspace yet: look it up in the Go+ module.
+		if files, err = gopModPackageFiles(ctx, snapshot, fh.URI(), pkgPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// Jump to the package clauses of the package, as for an import path.
+	var locs []protocol.Location
+	for _, f := range files {
+		fh, err := snapshot.ReadFile(ctx, f)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		var loc protocol.Location
+		switch snapshot.View().FileKind(fh) {
+		case source.Go:
+			pgf, err := snapshot.ParseGo(ctx, fh, source.ParseHeader)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue
+			}
+			if loc, err = pgf.NodeLocation(pgf.File); err != nil {
+				return nil, err
+			}
+		case source.Gop:
+			pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseHeader)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue
+			}
+			if !pgf.HasPkgDecl() {
+				continue // e.g. a classfile
+			}
+			if loc, err = pgf.NodeLocation(pgf.File); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		locs = append(locs, loc)
+	}
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("package %q has no readable files", pkgPath)
+	}
+	return locs, nil
+}
+
+// gopModPackageFiles returns the non-test Go and Go+ files of the package
+// pkgPath, as found by the Go+ module of the gop.mod file uri.
+func gopModPackageFiles(ctx context.Context, snapshot source.Snapshot, uri span.URI, pkgPath string) ([]span.URI, error) {
+	if strings.HasPrefix(pkgPath, ".") {
+		return nil, fmt.Errorf("failed to resolve package %q", pkgPath)
+	}
+	mod, err := gopModule(ctx, snapshot, uri)
+	if err != nil {
+		return nil, err
+	}
+	pkg, err := mod.Lookup(pkgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve package %q: %w", pkgPath, err)
+	}
+	entries, err := os.ReadDir(pkg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []span.URI
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasSuffix(name, "_test.go") || strings.HasPrefix(name, "gop_autogen") {
+			continue
+		}
+		if ext := filepath.Ext(name); ext != ".go" && goputil.FileKind(ext) == goputil.FileUnknown {
+			continue
+		}
+		files = append(files, span.URIFromPath(filepath.Join(pkg.Dir, name)))
+	}
+	return files, nil
+}
+
+// gopModMapPosition returns the location of the interval [start, end) of
+// fset. See source.mapPosition.
+func gopModMapPosition(ctx context.Context, fset *token.FileSet, s source.FileSource, start, end token.Pos) (protocol.Location, error) {
+	file := fset.File(start)
+	uri := span.URIFromPath(file.Name())
+	fh, err := s.ReadFile(ctx, uri)
+	if err != nil {
+		return protocol.Location{}, err
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return protocol.Location{}, err
+	}
+	m := protocol.NewMapper(fh.URI(), content)
+	return m.PosLocation(file, start, end)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// GopModDiagnostics returns diagnostics from parsing the gop.mod files of
+// the modules in the workspace.
+func GopModDiagnostics(ctx context.Context, snapshot source.Snapshot) (map[span.URI][]*source.Diagnostic, error) {
+	ctx, done := event.Start(ctx, "mod.GopModDiagnostics", source.SnapshotLabels(snapshot)...)
+	defer done()
+
+	reports := make(map[span.URI][]*source.Diagnostic)
+	for _, modURI := range snapshot.ModFiles() {
+		uri := gopModURI(modURI)
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fh.Content(); err != nil && os.IsNotExist(err) {
+			continue // a module without gop.mod
+		}
+		reports[uri] = []*source.Diagnostic{}
+		pm, err := snapshot.ParseGopMod(ctx, fh)
+		if err != nil {
+			if pm == nil || len(pm.ParseErrors) == 0 {
+				return nil, err
+			}
+			reports[uri] = pm.ParseErrors
+		}
+	}
+	return reports, nil
+}
+
+// gopModURI returns the URI of the gop.mod file beside the go.mod file modURI.
+func gopModURI(modURI span.URI) span.URI {
+	return span.URIFromPath(filepath.Join(filepath.Dir(modURI.Filename()), "gop.mod"))
+}
+
+// gopModule returns the Go+ module of the gop.mod file uri, as loaded by the
+// metadata of the packages in that module, or gopmod.Default if there is none.
+func gopModule(ctx context.Context, snapshot source.Snapshot, uri span.URI) (*gopmod.Module, error) {
+	metas, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(uri.Filename())
+	for _, m := range metas {
+		if m.Module != nil && m.Module.GoMod != "" && filepath.Dir(m.Module.GoMod) == dir {
+			return m.GopMod_(), nil
+		}
+	}
+	return gopmod.Default, nil
+}
@@ -0,0 +1,29 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"context"
+
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+)
+
+// GopModFormat formats a gop.mod file.
+func GopModFormat(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "mod.GopModFormat")
+	defer done()
+
+	pm, err := snapshot.ParseGopMod(ctx, fh)
+	if err != nil {
+		return nil, err
+	}
+	formatted := modfile.Format(pm.File.Syntax)
+	// Calculate the edits to be made due to the change.
+	diffs := snapshot.View().Options().ComputeEdits(string(pm.Mapper.Content), string(formatted))
+	return source.ToProtocolEdits(pm.Mapper, diffs)
+}
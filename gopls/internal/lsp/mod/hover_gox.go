@@ -0,0 +1,156 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mod
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+)
+
+// GopModHover returns hover information for the gop, project, class and
+// import directives of a gop.mod file.
+func GopModHover(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, position protocol.Position) (*protocol.Hover, error) {
+	ctx, done := event.Start(ctx, "mod.GopModHover")
+	defer done()
+
+	// Get the position of the cursor.
+	pm, err := snapshot.ParseGopMod(ctx, fh)
+	if pm == nil || pm.File == nil { // use the valid directives of a partial result
+		return nil, fmt.Errorf("getting gop.mod file handle: %w", err)
+	}
+	offset, err := pm.Mapper.PositionOffset(position)
+	if err != nil {
+		return nil, fmt.Errorf("computing cursor position: %w", err)
+	}
+
+	// The cursor position is not on a directive.
+	d := gopModDirectiveAt(pm, offset)
+	if d == nil {
+		return nil, nil
+	}
+
+	// Get the range to highlight for the hover.
+	rng, err := pm.Mapper.OffsetRange(d.line.Start.Byte, d.line.End.Byte)
+	if err != nil {
+		return nil, err
+	}
+	options := snapshot.View().Options()
+	var header, body string
+	switch {
+	case d.imp != nil:
+		header = "import " + d.imp.Path
+		body = "Package automatically imported by the classfiles of " + describeGopProject(d.proj) + "."
+		if d.imp.Name != "" {
+			body = fmt.Sprintf("Package automatically imported as %s by the classfiles of %s.", d.imp.Name, describeGopProject(d.proj))
+		}
+	case d.class != nil:
+		header = "class " + d.class.Class
+		body = fmt.Sprintf("Work class for %s files of %s.", d.class.Ext, describeGopProject(d.proj))
+	case d.proj != nil:
+		header = "project"
+		if d.proj.Class != "" {
+			header += " " + d.proj.Class
+		}
+		body = describeGopProjectDetails(d.proj)
+	default:
+		header = "gop " + pm.File.Gop.Version
+		body = "The minimum version of Go+ required by this module."
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  options.PreferredContentFormat,
+			Value: formatHeader(header, options) + body,
+		},
+		Range: rng,
+	}, nil
+}
+
+// describeGopProject returns a short description of the classfile project p.
+func describeGopProject(p *modfile.Project) string {
+	s := "the classfile framework " + p.PkgPaths[0]
+	if p.Class != "" {
+		s = fmt.Sprintf("project %s (%s) of %s", p.Class, p.Ext, s)
+	}
+	return s
+}
+
+// describeGopProjectDetails returns a description of the classfile project
+// p, including its packages and work classes.
+func describeGopProjectDetails(p *modfile.Project) string {
+	var b strings.Builder
+	if p.Ext != "" {
+		fmt.Fprintf(&b, "Project class for %s files.\n\n", p.Ext)
+	} else {
+		b.WriteString("Classfiles registered by the framework package.\n\n")
+	}
+	fmt.Fprintf(&b, "Framework: %s\n", p.PkgPaths[0])
+	if len(p.PkgPaths) > 1 {
+		fmt.Fprintf(&b, "\nAlso imports: %s\n", strings.Join(p.PkgPaths[1:], ", "))
+	}
+	for _, w := range p.Works {
+		fmt.Fprintf(&b, "\nWork class: %s (%s)\n", w.Class, w.Ext)
+	}
+	return b.String()
+}
+
+// A gopModDirective is a directive of a gop.mod file.
+type gopModDirective struct {
+	line  *modfile.Line
+	proj  *modfile.Project // for project, class and import directives
+	class *modfile.Class   // for class directives
+	imp   *modfile.Import  // for import directives
+}
+
+// gopModDirectiveAt returns the directive of pm at offset, or nil.
+func gopModDirectiveAt(pm *source.ParsedGopModule, offset int) *gopModDirective {
+	in := func(line *modfile.Line) bool {
+		return line != nil && line.Start.Byte <= offset && offset <= line.End.Byte
+	}
+	if gop := pm.File.Gop; gop != nil && in(gop.Syntax) {
+		return &gopModDirective{line: gop.Syntax}
+	}
+	for _, p := range pm.File.Projects {
+		if in(p.Syntax) {
+			return &gopModDirective{line: p.Syntax, proj: p}
+		}
+		for _, w := range p.Works {
+			if in(w.Syntax) {
+				return &gopModDirective{line: w.Syntax, proj: p, class: w}
+			}
+		}
+		for _, imp := range p.Import {
+			if in(imp.Syntax) {
+				return &gopModDirective{line: imp.Syntax, proj: p, imp: imp}
+			}
+		}
+	}
+	return nil
+}
+
+// gopModTokenAt returns the index of the token of line at offset, and its
+// start and end offsets. The index is -1 if offset is not on a token.
+// For a line in a block, the verb of the block is not one of its tokens.
+func gopModTokenAt(content []byte, line *modfile.Line, offset int) (index, start, end int) {
+	pos := line.Start.Byte
+	for i, tok := range line.Token {
+		j := bytes.Index(content[pos:line.End.Byte], []byte(tok))
+		if j == -1 {
+			break // This should not happen.
+		}
+		start, end = pos+j, pos+j+len(tok)
+		if start <= offset && offset <= end {
+			return i, start, end
+		}
+		pos = end
+	}
+	return -1, 0, 0
+}
@@ -24,7 +24,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
-	"golang.org/x/tools/go/expect"
+	"golang.org/x/tools/gop/expect" // goxls: notes of Go+ files
 	"golang.org/x/tools/gopls/internal/hooks"
 	"golang.org/x/tools/gopls/internal/lsp/cache"
 	"golang.org/x/tools/gopls/internal/lsp/debug"
@@ -1617,7 +1617,7 @@ func codeAction(env *Env, uri protocol.DocumentURI, rng protocol.Range, actionKi
 			Command:   action.Command.Command,
 			Arguments: action.Command.Arguments,
 		}); err != nil {
-			env.T.Fatalf("error converting command %q to edits: %v", action.Command.Command, err)
+			return nil, err // goxls: @codeactionerr checks errors of commands such as refactor.inline
 		}
 
 		if err := applyDocumentChanges(env, env.Awaiter.takeDocumentChanges(), fileChanges); err != nil {
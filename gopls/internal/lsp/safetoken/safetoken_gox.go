@@ -0,0 +1,18 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package safetoken
+
+import "go/token"
+
+// AdjustedPosition returns the Position for the pos value in the given
+// file, as Position does, but adjusted by the //line directives of f, as
+// those of the Go code generated for Go+ files.
+func AdjustedPosition(f *token.File, pos token.Pos) token.Position {
+	// Work around issue #57490.
+	if int(pos) == f.Base()+f.Size()+1 {
+		pos--
+	}
+	return f.PositionFor(pos, true)
+}
@@ -0,0 +1,62 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"context"
+
+	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+)
+
+// gopSelectionRange implements the textDocument/selectionRange feature for
+// Go+ files. See selectionRange.
+func gopSelectionRange(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, positions []protocol.Position) ([]protocol.SelectionRange, error) {
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]protocol.SelectionRange, len(positions))
+	for i, protocolPos := range positions {
+		pos, err := pgf.PositionPos(protocolPos)
+		if err != nil {
+			return nil, err
+		}
+
+		path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+
+		tail := &result[i] // tail of the Parent linked list, built head first
+
+		first := true
+		for _, node := range path {
+			// The root of a file without a package clause and the nodes
+			// synthesized for its shadow entry may lack a valid position.
+			if !node.Pos().IsValid() {
+				continue
+			}
+			rng, err := pgf.NodeRange(node)
+			if err != nil {
+				return nil, err
+			}
+			// A shadow entry has the same extent as its statements.
+			if !first && rng == tail.Range {
+				continue
+			}
+
+			// Add node to tail.
+			if !first {
+				tail.Parent = &protocol.SelectionRange{}
+				tail = tail.Parent
+			}
+			tail.Range = rng
+			first = false
+		}
+	}
+
+	return result, nil
+}
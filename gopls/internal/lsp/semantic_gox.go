@@ -0,0 +1,958 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lsp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/types"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/internal/event"
+)
+
+func (s *Server) computeGopSemanticTokens(ctx context.Context, snapshot source.Snapshot, fh source.FileHandle, rng *protocol.Range) (*protocol.SemanticTokens, error) {
+	pkg, pgf, err := source.NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+
+	if rng == nil && len(pgf.Src) > maxFullFileSize {
+		err := fmt.Errorf("semantic tokens: file %s too large for full (%d>%d)",
+			fh.URI().Filename(), len(pgf.Src), maxFullFileSize)
+		return nil, err
+	}
+	vv := snapshot.View()
+	e := &gopEncoded{
+		ctx:            ctx,
+		metadataSource: snapshot,
+		pgf:            pgf,
+		rng:            rng,
+		ti:             pkg.GopTypesInfo(),
+		pkg:            pkg,
+		fset:           pkg.FileSet(),
+		out: &encoded{
+			ctx:       ctx,
+			tokTypes:  s.session.Options().SemanticTypes,
+			tokMods:   s.session.Options().SemanticMods,
+			noStrings: vv.Options().NoSemanticString,
+			noNumbers: vv.Options().NoSemanticNumber,
+		},
+	}
+	if err := e.init(); err != nil {
+		return nil, err
+	}
+	e.semantics()
+	return &protocol.SemanticTokens{
+		Data: e.out.Data(),
+		// For delta requests, but we've never seen any.
+		ResultID: fmt.Sprintf("%v", time.Now()),
+	}, nil
+}
+
+// gopEncoded walks a Go+ parse tree and collects semantic tokens.
+// The collected items are kept in out, which also knows the token
+// legend negotiated with the client.
+type gopEncoded struct {
+	out *encoded
+
+	ctx context.Context
+	// metadataSource is used to resolve imports
+	metadataSource source.MetadataSource
+	pgf            *source.ParsedGopFile
+	rng            *protocol.Range
+	ti             *typesutil.Info
+	pkg            source.Package
+	fset           *token.FileSet
+	// allowed starting and ending token.Pos, set by init
+	// used to avoid looking at declarations not in range
+	start, end token.Pos
+	// path from the root of the parse tree, used for debugging
+	stack []ast.Node
+}
+
+func (e *gopEncoded) semantics() {
+	f := e.pgf.File
+	// may not be in range, but harmless
+	if f.HasPkgDecl() {
+		e.token(f.Package, len("package"), tokKeyword, nil)
+		e.token(f.Name.NamePos, len(f.Name.Name), tokNamespace, nil)
+	}
+	inspect := func(n ast.Node) bool {
+		return e.inspector(n)
+	}
+	for _, d := range f.Decls {
+		// only look at the decls that overlap the range
+		start, end := d.Pos(), d.End()
+		if fn, ok := d.(*ast.FuncDecl); ok && fn.Shadow {
+			// The shadow entry of a script or classfile is synthesized by the
+			// parser: only its statements come from the source.
+			if fn.Body == nil {
+				continue
+			}
+			e.stack = append(e.stack, fn)
+			for _, stmt := range fn.Body.List {
+				if stmt.End() <= e.start || stmt.Pos() >= e.end {
+					continue
+				}
+				ast.Inspect(stmt, inspect)
+			}
+			e.stack = e.stack[:len(e.stack)-1]
+			continue
+		}
+		if end <= e.start || start >= e.end {
+			continue
+		}
+		ast.Inspect(d, inspect)
+	}
+	for _, cg := range f.Comments {
+		for _, c := range cg.List {
+			if !strings.Contains(c.Text, "\n") {
+				e.token(c.Pos(), len(c.Text), tokComment, nil)
+				continue
+			}
+			e.multiline(c.Pos(), c.End(), c.Text, tokComment)
+		}
+	}
+}
+
+func (e *gopEncoded) token(start token.Pos, leng int, typ tokenType, mods []string) {
+	if !start.IsValid() {
+		return
+	}
+	if start >= e.end || start+token.Pos(leng) <= e.start {
+		return
+	}
+	// want a line and column from start (in LSP coordinates). Ignore line directives.
+	lspRange, err := e.pgf.PosRange(start, start+token.Pos(leng))
+	if err != nil {
+		event.Error(e.ctx, "failed to convert to range", err)
+		return
+	}
+	if lspRange.End.Line != lspRange.Start.Line {
+		// this happens if users are typing at the end of the file, but report nothing
+		return
+	}
+	// token is all on one line
+	length := lspRange.End.Character - lspRange.Start.Character
+	e.out.add(lspRange.Start.Line, lspRange.Start.Character, length, typ, mods)
+}
+
+// convert the stack to a string, for debugging
+func (e *gopEncoded) strStack() string {
+	msg := []string{"["}
+	for i := len(e.stack) - 1; i >= 0; i-- {
+		s := e.stack[i]
+		msg = append(msg, strings.TrimPrefix(fmt.Sprintf("%T", s), "*ast."))
+	}
+	if len(e.stack) > 0 {
+		loc := e.stack[len(e.stack)-1].Pos()
+		if _, err := safetoken.Offset(e.pgf.Tok, loc); err != nil {
+			msg = append(msg, fmt.Sprintf("invalid position %v for %s", loc, e.pgf.URI))
+		} else {
+			add := safetoken.Position(e.pgf.Tok, loc)
+			nm := filepath.Base(add.Filename)
+			msg = append(msg, fmt.Sprintf("(%s:%d,col:%d)", nm, add.Line, add.Column))
+		}
+	}
+	msg = append(msg, "]")
+	return strings.Join(msg, " ")
+}
+
+func (e *gopEncoded) inspector(n ast.Node) bool {
+	pop := func() {
+		e.stack = e.stack[:len(e.stack)-1]
+	}
+	if n == nil {
+		pop()
+		return true
+	}
+	e.stack = append(e.stack, n)
+	switch x := n.(type) {
+	case *ast.ArrayType:
+	case *ast.AssignStmt:
+		e.token(x.TokPos, len(x.Tok.String()), tokOperator, nil)
+	case *ast.BasicLit:
+		e.basicLit(x)
+	case *ast.BinaryExpr:
+		e.token(x.OpPos, len(x.Op.String()), tokOperator, nil)
+	case *ast.BlockStmt:
+	case *ast.BranchStmt:
+		e.token(x.TokPos, len(x.Tok.String()), tokKeyword, nil)
+		// There's no semantic encoding for labels
+	case *ast.CallExpr:
+		if x.Ellipsis != token.NoPos {
+			e.token(x.Ellipsis, len("..."), tokOperator, nil)
+		}
+	case *ast.CaseClause:
+		iam := "case"
+		if x.List == nil {
+			iam = "default"
+		}
+		e.token(x.Case, len(iam), tokKeyword, nil)
+	case *ast.ChanType:
+		// chan | chan <- | <- chan
+		switch {
+		case x.Arrow == token.NoPos:
+			e.token(x.Begin, len("chan"), tokKeyword, nil)
+		case x.Arrow == x.Begin:
+			e.token(x.Arrow, 2, tokOperator, nil)
+			pos := e.findKeyword("chan", x.Begin+2, x.Value.Pos())
+			e.token(pos, len("chan"), tokKeyword, nil)
+		case x.Arrow != x.Begin:
+			e.token(x.Begin, len("chan"), tokKeyword, nil)
+			e.token(x.Arrow, 2, tokOperator, nil)
+		}
+	case *ast.CommClause:
+		iam := len("case")
+		if x.Comm == nil {
+			iam = len("default")
+		}
+		e.token(x.Case, iam, tokKeyword, nil)
+	case *ast.CompositeLit:
+	case *ast.DeclStmt:
+	case *ast.DeferStmt:
+		e.token(x.Defer, len("defer"), tokKeyword, nil)
+	case *ast.Ellipsis:
+		e.token(x.Ellipsis, len("..."), tokOperator, nil)
+	case *ast.EmptyStmt:
+	case *ast.ExprStmt:
+	case *ast.Field:
+	case *ast.FieldList:
+	case *ast.ForStmt:
+		e.token(x.For, len("for"), tokKeyword, nil)
+	case *ast.FuncDecl:
+	case *ast.FuncLit:
+	case *ast.FuncType:
+		if x.Func != token.NoPos {
+			e.token(x.Func, len("func"), tokKeyword, nil)
+		}
+	case *ast.GenDecl:
+		e.token(x.TokPos, len(x.Tok.String()), tokKeyword, nil)
+	case *ast.GoStmt:
+		e.token(x.Go, len("go"), tokKeyword, nil)
+	case *ast.Ident:
+		e.ident(x)
+	case *ast.IfStmt:
+		e.token(x.If, len("if"), tokKeyword, nil)
+		if x.Else != nil {
+			// x.Body.End() or x.Body.End()+1, not that it matters
+			pos := e.findKeyword("else", x.Body.End(), x.Else.Pos())
+			e.token(pos, len("else"), tokKeyword, nil)
+		}
+	case *ast.ImportSpec:
+		e.importSpec(x)
+		pop()
+		return false
+	case *ast.IncDecStmt:
+		e.token(x.TokPos, len(x.Tok.String()), tokOperator, nil)
+	case *ast.IndexExpr:
+	case *ast.IndexListExpr:
+	case *ast.InterfaceType:
+		e.token(x.Interface, len("interface"), tokKeyword, nil)
+	case *ast.KeyValueExpr:
+	case *ast.LabeledStmt:
+	case *ast.MapType:
+		e.token(x.Map, len("map"), tokKeyword, nil)
+	case *ast.ParenExpr:
+	case *ast.RangeStmt:
+		e.token(x.For, len("for"), tokKeyword, nil)
+		if !x.NoRangeOp {
+			// x.TokPos == token.NoPos is legal (for range foo {})
+			offset := x.TokPos
+			if offset == token.NoPos {
+				offset = x.For
+			}
+			pos := e.findKeyword("range", offset, x.X.Pos())
+			e.token(pos, len("range"), tokKeyword, nil)
+		}
+	case *ast.ReturnStmt:
+		e.token(x.Return, len("return"), tokKeyword, nil)
+	case *ast.SelectStmt:
+		e.token(x.Select, len("select"), tokKeyword, nil)
+	case *ast.SelectorExpr:
+	case *ast.SendStmt:
+		e.token(x.Arrow, len("<-"), tokOperator, nil)
+	case *ast.SliceExpr:
+	case *ast.StarExpr:
+		e.token(x.Star, len("*"), tokOperator, nil)
+	case *ast.StructType:
+		e.token(x.Struct, len("struct"), tokKeyword, nil)
+	case *ast.SwitchStmt:
+		e.token(x.Switch, len("switch"), tokKeyword, nil)
+	case *ast.TypeAssertExpr:
+		if x.Type == nil {
+			pos := e.findKeyword("type", x.Lparen, x.Rparen)
+			e.token(pos, len("type"), tokKeyword, nil)
+		}
+	case *ast.TypeSpec:
+	case *ast.TypeSwitchStmt:
+		e.token(x.Switch, len("switch"), tokKeyword, nil)
+	case *ast.UnaryExpr:
+		e.token(x.OpPos, len(x.Op.String()), tokOperator, nil)
+	case *ast.ValueSpec:
+	// Go+ extended nodes
+	case *ast.SliceLit:
+	case *ast.LambdaExpr:
+		e.token(x.Rarrow, len("=>"), tokOperator, nil)
+	case *ast.LambdaExpr2:
+		e.token(x.Rarrow, len("=>"), tokOperator, nil)
+	case *ast.ForPhrase:
+		e.forPhrase(x)
+	case *ast.ComprehensionExpr:
+	case *ast.ForPhraseStmt:
+	case *ast.RangeExpr:
+		e.token(x.To, len(":"), tokOperator, nil)
+		if x.Colon2 != token.NoPos {
+			e.token(x.Colon2, len(":"), tokOperator, nil)
+		}
+	case *ast.ErrWrapExpr:
+		// expr! | expr? | expr?:defval
+		e.token(x.TokPos, len(x.Tok.String()), tokOperator, nil)
+		if x.Default != nil {
+			pos := e.findKeyword(":", x.TokPos+1, x.Default.Pos())
+			e.token(pos, len(":"), tokOperator, nil)
+		}
+	case *ast.OverloadFuncDecl:
+		e.token(x.Func, len("func"), tokKeyword, nil)
+		e.token(x.Assign, len("="), tokOperator, nil)
+	case *ast.EnvExpr:
+		e.token(x.TokPos, len("$"), tokOperator, nil)
+	// things only seen with parsing or type errors, so ignore them
+	case *ast.BadDecl, *ast.BadExpr, *ast.BadStmt:
+		return true
+	// not going to see these
+	case *ast.File, *ast.Package:
+		e.unexpected(fmt.Sprintf("implement %T %s", x, safetoken.Position(e.pgf.Tok, x.Pos())))
+	// other things we knowingly ignore
+	case *ast.Comment, *ast.CommentGroup:
+		pop()
+		return false
+	default:
+		e.unexpected(fmt.Sprintf("failed to implement %T", x))
+	}
+	return true
+}
+
+func (e *gopEncoded) basicLit(x *ast.BasicLit) {
+	switch x.Kind {
+	case token.STRING, token.CSTRING:
+		if x.Extra != nil {
+			// "...${expr}...": the embedded expressions are visited as
+			// children, so only report the literal parts around them.
+			from := x.Pos()
+			for _, part := range x.Extra.Parts {
+				if expr, ok := part.(ast.Expr); ok {
+					e.token(from, int(expr.Pos()-from), tokString, nil)
+					from = expr.End()
+				}
+			}
+			e.token(from, int(x.End()-from), tokString, nil)
+			return
+		}
+		if strings.Contains(x.Value, "\n") {
+			e.multiline(x.Pos(), x.End(), x.Value, tokString)
+			return
+		}
+		e.token(x.Pos(), len(x.Value), tokString, nil)
+	default:
+		e.token(x.Pos(), len(x.Value), tokNumber, nil)
+	}
+}
+
+// forPhrase reports the keywords and operators of a Go+ for phrase:
+//
+//	for k, v <- X if cond
+//	for k, v <- X, init; cond
+func (e *gopEncoded) forPhrase(x *ast.ForPhrase) {
+	e.token(x.For, len("for"), tokKeyword, nil)
+	e.token(x.TokPos, len("<-"), tokOperator, nil)
+	if x.IfPos != token.NoPos {
+		if off, err := safetoken.Offset(e.pgf.Tok, x.IfPos); err == nil && bytes.HasPrefix(e.pgf.Src[off:], []byte("if")) {
+			e.token(x.IfPos, len("if"), tokKeyword, nil)
+		}
+	}
+}
+
+func (e *gopEncoded) ident(x *ast.Ident) {
+	if e.ti == nil {
+		what, mods := e.unkIdent(x)
+		if what != "" {
+			e.token(x.Pos(), len(x.String()), what, mods)
+		}
+		if semDebug {
+			log.Printf(" nil %s/nil/nil %q %v %s", x.String(), what, mods, e.strStack())
+		}
+		return
+	}
+	def := e.ti.Defs[x]
+	if def != nil {
+		what, mods := e.definitionFor(x, def)
+		if what != "" {
+			e.token(x.Pos(), len(x.String()), what, mods)
+		}
+		if semDebug {
+			log.Printf(" for %s/%T/%T got %s %v (%s)", x.String(), def, def.Type(), what, mods, e.strStack())
+		}
+		return
+	}
+	use := e.ti.Uses[x]
+	tok := func(pos token.Pos, lng int, tok tokenType, mods []string) {
+		e.token(pos, lng, tok, mods)
+		q := "nil"
+		if use != nil {
+			q = fmt.Sprintf("%T", use.Type())
+		}
+		if semDebug {
+			log.Printf(" use %s/%T/%s got %s %v (%s)", x.String(), use, q, tok, mods, e.strStack())
+		}
+	}
+
+	switch y := use.(type) {
+	case nil:
+		// An overloaded function name may only be recorded as an overload.
+		if obj, _ := e.ti.OverloadOf(x); obj != nil {
+			tok(x.Pos(), len(x.Name), tokFunction, nil)
+			return
+		}
+		what, mods := e.unkIdent(x)
+		if what != "" {
+			tok(x.Pos(), len(x.String()), what, mods)
+		} else if semDebug {
+			// tok() wasn't called, so didn't log
+			log.Printf(" nil %s/%T/nil %q %v (%s)", x.String(), use, what, mods, e.strStack())
+		}
+		return
+	case *types.Builtin:
+		tok(x.NamePos, len(x.Name), tokFunction, []string{"defaultLibrary"})
+	case *types.Const:
+		mods := []string{"readonly"}
+		tt := y.Type()
+		if _, ok := tt.(*types.Basic); ok {
+			tok(x.Pos(), len(x.String()), tokVariable, mods)
+			break
+		}
+		if ttx, ok := tt.(*types.Named); ok {
+			if _, ok := ttx.Underlying().(*types.Basic); ok {
+				tok(x.Pos(), len(x.String()), tokVariable, mods)
+				break
+			}
+		}
+		e.unexpected(fmt.Sprintf("%s %T %#v", x.String(), tt, tt))
+	case *types.Func:
+		var mods []string
+		if e.isGopBuiltin(x, y) {
+			mods = []string{"defaultLibrary"}
+		}
+		if sig, ok := y.Type().(*types.Signature); ok && sig.Recv() != nil && e.isCommandRecv(x) {
+			// auto-property or command-style method call, e.g. `a.len`
+			tok(x.Pos(), len(x.Name), tokMethod, mods)
+			break
+		}
+		tok(x.Pos(), len(x.Name), tokFunction, mods)
+	case *types.Label:
+		// nothing to map it to
+	case *types.Nil:
+		// nil is a predeclared identifier
+		tok(x.Pos(), len("nil"), tokVariable, []string{"readonly", "defaultLibrary"})
+	case *types.PkgName:
+		tok(x.Pos(), len(x.Name), tokNamespace, nil)
+	case *types.TypeName: // could be a tokTpeParam
+		var mods []string
+		if _, ok := y.Type().(*types.Basic); ok {
+			mods = []string{"defaultLibrary"}
+		} else if _, ok := y.Type().(*types.TypeParam); ok {
+			tok(x.Pos(), len(x.String()), tokTypeParam, mods)
+			break
+		}
+		tok(x.Pos(), len(x.String()), tokType, mods)
+	case *types.Var:
+		if isSignature(y) {
+			tok(x.Pos(), len(x.Name), tokFunction, nil)
+		} else if e.isParam(use.Pos()) {
+			// variable, unless use.pos is the pos of a Field in an ancestor
+			// FuncDecl, FuncLit or lambda and then it's a parameter
+			tok(x.Pos(), len(x.Name), tokParameter, nil)
+		} else {
+			tok(x.Pos(), len(x.Name), tokVariable, nil)
+		}
+	default:
+		if use.Type() != nil {
+			e.unexpected(fmt.Sprintf("%s %T/%T,%#v", x.String(), use, use.Type(), use))
+		} else {
+			e.unexpected(fmt.Sprintf("%s %T", x.String(), use))
+		}
+	}
+}
+
+// isGopBuiltin reports whether x is an unqualified use of a function that
+// is declared outside of the current package, such as the Go+ builtin
+// `println` (which resolves to fmt.Println).
+func (e *gopEncoded) isGopBuiltin(x *ast.Ident, fn *types.Func) bool {
+	if fn.Pkg() == nil || fn.Pkg() == e.pkg.GetTypes() {
+		return false
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		return false
+	}
+	if n := len(e.stack) - 2; n >= 0 {
+		if sel, ok := e.stack[n].(*ast.SelectorExpr); ok && sel.Sel == x {
+			return false
+		}
+	}
+	return true
+}
+
+// isCommandRecv reports whether x is the selector of a selector expression
+// that is not called with parentheses.
+func (e *gopEncoded) isCommandRecv(x *ast.Ident) bool {
+	n := len(e.stack) - 2
+	if n < 0 {
+		return false
+	}
+	sel, ok := e.stack[n].(*ast.SelectorExpr)
+	if !ok || sel.Sel != x {
+		return false
+	}
+	if n-1 >= 0 {
+		if call, ok := e.stack[n-1].(*ast.CallExpr); ok && call.Fun == sel {
+			return call.IsCommand()
+		}
+	}
+	return true
+}
+
+func (e *gopEncoded) isParam(pos token.Pos) bool {
+	inFields := func(fl *ast.FieldList) bool {
+		if fl == nil {
+			return false
+		}
+		for _, f := range fl.List {
+			for _, id := range f.Names {
+				if id.Pos() == pos {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	inIdents := func(ids []*ast.Ident) bool {
+		for _, id := range ids {
+			if id.Pos() == pos {
+				return true
+			}
+		}
+		return false
+	}
+	for i := len(e.stack) - 1; i >= 0; i-- {
+		switch n := e.stack[i].(type) {
+		case *ast.FuncDecl:
+			if inFields(n.Type.Params) {
+				return true
+			}
+		case *ast.FuncLit:
+			if inFields(n.Type.Params) {
+				return true
+			}
+		case *ast.LambdaExpr:
+			if inIdents(n.Lhs) {
+				return true
+			}
+		case *ast.LambdaExpr2:
+			if inIdents(n.Lhs) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// both e.ti.Defs and e.ti.Uses are nil. use the parse stack.
+// a lot of these only happen when the package doesn't compile
+// but in that case it is all best-effort from the parse tree
+func (e *gopEncoded) unkIdent(x *ast.Ident) (tokenType, []string) {
+	def := []string{"definition"}
+	n := len(e.stack) - 2 // parent of Ident
+	if n < 0 {
+		e.unexpected("no stack?")
+		return "", nil
+	}
+	switch nd := e.stack[n].(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.ParenExpr, *ast.StarExpr,
+		*ast.IncDecStmt, *ast.SliceExpr, *ast.ExprStmt, *ast.IndexExpr,
+		*ast.ReturnStmt, *ast.ChanType, *ast.SendStmt,
+		*ast.ForStmt,      // possibly incomplete
+		*ast.IfStmt,       /* condition */
+		*ast.KeyValueExpr, // either key or value
+		*ast.IndexListExpr,
+		*ast.SliceLit, *ast.RangeExpr, *ast.ErrWrapExpr, *ast.ComprehensionExpr,
+		*ast.BasicLit: // embedded in a string literal: "${x}"
+		return tokVariable, nil
+	case *ast.Ellipsis:
+		return tokType, nil
+	case *ast.CaseClause:
+		if n-2 >= 0 {
+			if _, ok := e.stack[n-2].(*ast.TypeSwitchStmt); ok {
+				return tokType, nil
+			}
+		}
+		return tokVariable, nil
+	case *ast.ArrayType:
+		if x == nd.Len {
+			// or maybe a Type Param, but we can't just from the parse tree
+			return tokVariable, nil
+		} else {
+			return tokType, nil
+		}
+	case *ast.MapType:
+		return tokType, nil
+	case *ast.CallExpr:
+		if x == nd.Fun {
+			return tokFunction, nil
+		}
+		return tokVariable, nil
+	case *ast.SwitchStmt:
+		return tokVariable, nil
+	case *ast.TypeAssertExpr:
+		if x == nd.X {
+			return tokVariable, nil
+		} else if x == nd.Type {
+			return tokType, nil
+		}
+	case *ast.ValueSpec:
+		for _, p := range nd.Names {
+			if p == x {
+				return tokVariable, def
+			}
+		}
+		for _, p := range nd.Values {
+			if p == x {
+				return tokVariable, nil
+			}
+		}
+		return tokType, nil
+	case *ast.SelectorExpr: // e.ti.Selections[nd] is nil, so no help
+		if n-1 >= 0 {
+			if ce, ok := e.stack[n-1].(*ast.CallExpr); ok {
+				// ... CallExpr SelectorExpr Ident (_.x())
+				if ce.Fun == nd && nd.Sel == x {
+					return tokFunction, nil
+				}
+			}
+		}
+		return tokVariable, nil
+	case *ast.AssignStmt:
+		for _, p := range nd.Lhs {
+			// x := ..., or x = ...
+			if p == x {
+				if nd.Tok != token.DEFINE {
+					def = nil
+				}
+				return tokVariable, def // '_' in _ = ...
+			}
+		}
+		// RHS, = x
+		return tokVariable, nil
+	case *ast.TypeSpec: // it's a type if it is either the Name or the Type
+		if x == nd.Type {
+			def = nil
+		}
+		return tokType, def
+	case *ast.Field:
+		// ident could be type in a field, or a method in an interface type, or a variable
+		if x == nd.Type {
+			return tokType, nil
+		}
+		if n-2 >= 0 {
+			_, okit := e.stack[n-2].(*ast.InterfaceType)
+			_, okfl := e.stack[n-1].(*ast.FieldList)
+			if okit && okfl {
+				return tokMethod, def
+			}
+		}
+		return tokVariable, nil
+	case *ast.LabeledStmt, *ast.BranchStmt:
+		// nothing to report
+	case *ast.CompositeLit:
+		if nd.Type == x {
+			return tokType, nil
+		}
+		return tokVariable, nil
+	case *ast.RangeStmt:
+		if nd.Tok != token.DEFINE {
+			def = nil
+		}
+		return tokVariable, def
+	case *ast.FuncDecl:
+		return tokFunction, def
+	case *ast.LambdaExpr, *ast.LambdaExpr2:
+		for _, p := range lambdaParams(nd) {
+			if p == x {
+				return tokParameter, def
+			}
+		}
+		return tokVariable, nil
+	case *ast.ForPhrase:
+		if x == nd.Key || x == nd.Value {
+			return tokVariable, def
+		}
+		return tokVariable, nil
+	case *ast.OverloadFuncDecl:
+		if x == nd.Name {
+			if nd.Recv != nil {
+				return tokMethod, def
+			}
+			return tokFunction, def
+		}
+		return tokFunction, nil
+	case *ast.EnvExpr:
+		return tokVariable, nil
+	default:
+		msg := fmt.Sprintf("%T undexpected: %s %s%q", nd, x.Name, e.strStack(), e.srcLine(x))
+		e.unexpected(msg)
+	}
+	return "", nil
+}
+
+func lambdaParams(n ast.Node) []*ast.Ident {
+	switch n := n.(type) {
+	case *ast.LambdaExpr:
+		return n.Lhs
+	case *ast.LambdaExpr2:
+		return n.Lhs
+	}
+	return nil
+}
+
+func isGopDeprecated(n *ast.CommentGroup) bool {
+	if n == nil {
+		return false
+	}
+	for _, c := range n.List {
+		if strings.HasPrefix(c.Text, "// Deprecated") {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *gopEncoded) definitionFor(x *ast.Ident, def types.Object) (tokenType, []string) {
+	mods := []string{"definition"}
+	for i := len(e.stack) - 1; i >= 0; i-- {
+		s := e.stack[i]
+		switch y := s.(type) {
+		case *ast.AssignStmt, *ast.RangeStmt, *ast.ForPhrase:
+			if x.Name == "_" {
+				return "", nil // not really a variable
+			}
+			return tokVariable, mods
+		case *ast.LambdaExpr, *ast.LambdaExpr2:
+			for _, p := range lambdaParams(y) {
+				if p == x {
+					return tokParameter, mods
+				}
+			}
+		case *ast.GenDecl:
+			if isGopDeprecated(y.Doc) {
+				mods = append(mods, "deprecated")
+			}
+			if y.Tok == token.CONST {
+				mods = append(mods, "readonly")
+			}
+			return tokVariable, mods
+		case *ast.OverloadFuncDecl:
+			if isGopDeprecated(y.Doc) {
+				mods = append(mods, "deprecated")
+			}
+			if y.Recv != nil {
+				return tokMethod, mods
+			}
+			return tokFunction, mods
+		case *ast.FuncDecl:
+			// If x is immediately under a FuncDecl, it is a function or method
+			if i == len(e.stack)-2 {
+				if isGopDeprecated(y.Doc) {
+					mods = append(mods, "deprecated")
+				}
+				if y.Recv != nil {
+					return tokMethod, mods
+				}
+				return tokFunction, mods
+			}
+			// if x < ... < FieldList < FuncDecl, this is the receiver, a variable
+			if _, ok := e.stack[i+1].(*ast.FieldList); ok {
+				if _, ok := def.(*types.TypeName); ok {
+					return tokTypeParam, mods
+				}
+				return tokVariable, nil
+			}
+			// if x < ... < FieldList < FuncType < FuncDecl, this is a param
+			return tokParameter, mods
+		case *ast.FuncType: // is it in the TypeParams?
+			if isGopTypeParam(x, y) {
+				return tokTypeParam, mods
+			}
+			return tokParameter, mods
+		case *ast.InterfaceType:
+			return tokMethod, mods
+		case *ast.TypeSpec:
+			// see encoded.definitionFor
+			if _, ok := e.stack[i+1].(*ast.FieldList); ok {
+				return tokTypeParam, mods
+			}
+			fldm := e.stack[len(e.stack)-2]
+			if fld, ok := fldm.(*ast.Field); ok {
+				// if len(fld.names) == 0 this is a tokType, being used
+				if len(fld.Names) == 0 {
+					return tokType, nil
+				}
+				return tokVariable, mods
+			}
+			return tokType, mods
+		}
+	}
+	// can't happen
+	msg := fmt.Sprintf("failed to find the decl for %s", safetoken.Position(e.pgf.Tok, x.Pos()))
+	e.unexpected(msg)
+	return "", []string{""}
+}
+
+func isGopTypeParam(x *ast.Ident, y *ast.FuncType) bool {
+	if y.TypeParams == nil {
+		return false
+	}
+	for _, p := range y.TypeParams.List {
+		for _, n := range p.Names {
+			if x == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (e *gopEncoded) multiline(start, end token.Pos, val string, tok tokenType) {
+	f := e.fset.File(start)
+	// the hard part is finding the lengths of lines. include the \n
+	leng := func(line int) int {
+		n := f.LineStart(line)
+		if line >= f.LineCount() {
+			return f.Size() - int(n)
+		}
+		return int(f.LineStart(line+1) - n)
+	}
+	spos := safetoken.StartPosition(e.fset, start)
+	epos := safetoken.EndPosition(e.fset, end)
+	sline := spos.Line
+	eline := epos.Line
+	// first line is from spos.Column to end
+	e.token(start, leng(sline)-spos.Column, tok, nil) // leng(sline)-1 - (spos.Column-1)
+	for i := sline + 1; i < eline; i++ {
+		// intermediate lines are from 1 to end
+		e.token(f.LineStart(i), leng(i)-1, tok, nil) // avoid the newline
+	}
+	// last line is from 1 to epos.Column
+	e.token(f.LineStart(eline), epos.Column-1, tok, nil) // columns are 1-based
+}
+
+// find the line in the source
+func (e *gopEncoded) srcLine(x ast.Node) string {
+	file := e.pgf.Tok
+	line := safetoken.Line(file, x.Pos())
+	start, err := safetoken.Offset(file, file.LineStart(line))
+	if err != nil {
+		return ""
+	}
+	end := start
+	for ; end < len(e.pgf.Src) && e.pgf.Src[end] != '\n'; end++ {
+
+	}
+	ans := e.pgf.Src[start:end]
+	return string(ans)
+}
+
+// findKeyword finds a keyword rather than guessing its location
+func (e *gopEncoded) findKeyword(keyword string, start, end token.Pos) token.Pos {
+	offset := int(start) - e.pgf.Tok.Base()
+	last := int(end) - e.pgf.Tok.Base()
+	buf := e.pgf.Src
+	if offset < 0 || last > len(buf) || offset > last {
+		e.unexpected(fmt.Sprintf("bad range:%s %v", keyword, safetoken.StartPosition(e.fset, start)))
+		return token.NoPos
+	}
+	idx := bytes.Index(buf[offset:last], []byte(keyword))
+	if idx != -1 {
+		return start + token.Pos(idx)
+	}
+	e.unexpected(fmt.Sprintf("not found:%s %v", keyword, safetoken.StartPosition(e.fset, start)))
+	return token.NoPos
+}
+
+func (e *gopEncoded) init() error {
+	if e.rng != nil {
+		var err error
+		e.start, e.end, err = e.pgf.RangePos(*e.rng)
+		if err != nil {
+			return fmt.Errorf("range span (%w) error for %s", err, e.pgf.File.Name)
+		}
+	} else {
+		tok := e.pgf.Tok
+		e.start, e.end = tok.Pos(0), tok.Pos(tok.Size()) // entire file
+	}
+	return nil
+}
+
+func (e *gopEncoded) importSpec(d *ast.ImportSpec) {
+	// a local package name or the last component of the Path
+	if d.Name != nil {
+		nm := d.Name.String()
+		if nm != "_" && nm != "." {
+			e.token(d.Name.Pos(), len(nm), tokNamespace, nil)
+		}
+		return // don't mark anything for . or _
+	}
+	importPath := source.GopUnquoteImportPath(d)
+	if importPath == "" {
+		return
+	}
+	// Import strings are implementation defined. Try to match with parse information.
+	depID := e.pkg.Metadata().DepsByImpPath[importPath]
+	if depID == "" {
+		return
+	}
+	depMD := e.metadataSource.Metadata(depID)
+	if depMD == nil {
+		// unexpected, but impact is that maybe some import is not colored
+		return
+	}
+	// Check whether the original literal contains the package's declared name.
+	j := strings.LastIndex(d.Path.Value, string(depMD.Name))
+	if j == -1 {
+		// Package name does not match import path, so there is nothing to report.
+		return
+	}
+	// Report virtual declaration at the position of the substring.
+	start := d.Path.Pos() + token.Pos(j)
+	e.token(start, len(depMD.Name), tokNamespace, nil)
+}
+
+// log unexpected state
+func (e *gopEncoded) unexpected(msg string) {
+	if semDebug {
+		panic(msg)
+	}
+	event.Error(e.ctx, e.strStack(), errors.New(msg))
+}
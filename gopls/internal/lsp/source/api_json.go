@@ -293,6 +293,41 @@ var GeneratedAPIJSON = &APIJSON{
 							Doc:     "find structs that would use less memory if their fields were sorted\n\nThis analyzer find structs that can be rearranged to use less memory, and provides\na suggested edit with the most compact order.\n\nNote that there are two different diagnostics reported. One checks struct size,\nand the other reports \"pointer bytes\" used. Pointer bytes is how many bytes of the\nobject that the garbage collector has to potentially scan for pointers, for example:\n\n\tstruct { uint32; string }\n\nhave 16 pointer bytes because the garbage collector has to scan up through the string's\ninner pointer.\n\n\tstruct { string; *uint32 }\n\nhas 24 pointer bytes because it has to scan further through the *uint32.\n\n\tstruct { string; uint32 }\n\nhas 8 because it can stop immediately after the string pointer.\n\nBe aware that the most compact order is not always the most efficient.\nIn rare cases it may cause two variables each updated by its own goroutine\nto occupy the same CPU cache line, inducing a form of memory contention\nknown as \"false sharing\" that slows down both goroutines.\n",
 							Default: "false",
 						},
+						{
+							Name:    "\"gopDeprecated\"",
+							Doc:     "check for use of deprecated identifiers\n\nThe deprecated analyzer looks for deprecated symbols and package imports.\n\nSee https://go.dev/wiki/Deprecated to learn about Go's convention\nfor documenting and signaling deprecated identifiers.",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopSimplifycompositelit\"",
+							Doc:     "check for composite literal simplifications\n\nAn array, slice, or map composite literal of the form:\n\t[]T{T{}, T{}}\nwill be simplified to:\n\t[]T{{}, {}}\n\nThis is one of the simplifications that \"gofmt -s\" applies, here to the\nGo+ files.\n\nA slice or a map that is created empty and then filled:\n\ts := make([]int, 0)\n\ts = append(s, 1, 2)\n\tm := make(map[string]int)\n\tm[\"a\"] = 1\nwill be simplified to a Go+ list or map literal:\n\ts := [1, 2]\n\tm := {\"a\": 1}\nprovided that the types of the literals are those of the variables.",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopSimplifylambda\"",
+							Doc:     "check for function literals that can be lambdas\n\nA function literal passed as an argument of a function call:\n\tsort.Slice(s, func(i, j int) bool { return s[i] < s[j] })\n\thttp.HandleFunc(\"/\", func(w http.ResponseWriter, r *http.Request) {\n\t\t...\n\t})\nwill be simplified to a Go+ lambda:\n\tsort.Slice(s, (i, j) => s[i] < s[j])\n\thttp.HandleFunc(\"/\", (w, r) => {\n\t\t...\n\t})\nas the types of the parameters and results of a lambda are those of the\nparameter of the function.\n\nFunction literals with unnamed parameters or named results, passed to a\nvariadic or overloaded function, or whose type is not that of the\nparameter are left as is.",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopSimplifyrange\"",
+							Doc:     "check for range statement simplifications\n\nA range of the form:\n\tfor x, _ = range v {...}\nwill be simplified to:\n\tfor x = range v {...}\n\nA range of the form:\n\tfor _ = range v {...}\nwill be simplified to:\n\tfor range v {...}\n\nA for phrase of the form:\n\tfor _, x <- v {...}\nwill be simplified to:\n\tfor x <- v {...}\n\nA loop of the form:\n\tfor i := 0; i < n; i++ {...}\nwhere neither i nor n is changed by the loop body, will be simplified to:\n\tfor i <- 0:n {...}\n\nThe first two are among the simplifications that \"gofmt -s\" applies,\nhere to the Go+ files.",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopSimplifyslice\"",
+							Doc:     "check for slice simplifications\n\nA slice expression of the form:\n\ts[a:len(s)]\nwill be simplified to:\n\ts[a:]\n\nThis is one of the simplifications that \"gofmt -s\" applies, here to the\nGo+ files.",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopUnusedparams\"",
+							Doc:     "check for unused parameters of functions\n\nThe unusedparams analyzer checks functions to see if there are\nany parameters that are not being used.\n\nTo reduce false positives it ignores:\n- methods\n- parameters that do not have a name or are underscored\n- functions in test files\n- functions with empty bodies or those with just a return stmt",
+							Default: "false",
+						},
+						{
+							Name:    "\"gopUnusedwrite\"",
+							Doc:     "checks for unused writes\n\nThe analyzer reports instances of writes to struct fields and\narrays that are never read. Specifically, when a struct object\nor an array is copied, its elements are copied implicitly by\nthe compiler, and any element write to this copy does nothing\nwith the original object.\n\nFor example:\n\n\ttype T struct { x int }\n\n\tfunc f(input []T) {\n\t\tfor i, v := range input {  // v is a copy\n\t\t\tv.x = i  // unused write to field x\n\t\t}\n\t}\n\nAnother example is about non-pointer receiver:\n\n\ttype T struct { x int }\n\n\tfunc (t T) f() {  // t is a copy\n\t\tt.x = i  // unused write to field x\n\t}",
+							Default: "false",
+						},
 						{
 							Name:    "\"httpresponse\"",
 							Doc:     "check for mistakes using HTTP responses\n\nA common mistake when using the net/http package is to defer a function\ncall to close the http.Response Body before checking the error that\ndetermines whether the response is valid:\n\n\tresp, err := http.Head(url)\n\tdefer resp.Body.Close()\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\t// (defer statement belongs here)\n\nThis checker helps uncover latent nil dereference bugs by reporting a\ndiagnostic for such mistakes.",
@@ -433,6 +468,26 @@ var GeneratedAPIJSON = &APIJSON{
 							Doc:     "suggest fixes for errors due to an incorrect number of return values\n\nThis checker provides suggested fixes for type errors of the\ntype \"wrong number of return values (want %d, got %d)\". For example:\n\tfunc m() (int, string, *bool, error) {\n\t\treturn\n\t}\nwill turn into\n\tfunc m() (int, string, *bool, error) {\n\t\treturn 0, \"\", nil, nil\n\t}\n\nThis functionality is similar to https://github.com/sqs/goreturns.\n",
 							Default: "true",
 						},
+						{
+							Name:    "\"gopNonewvars\"",
+							Doc:     "suggested fixes for \"no new vars on left side of :=\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no new vars on left side of :=\". For example:\n\tz := 1\n\tz := 2\nwill turn into\n\tz := 1\n\tz = 2\n",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopNoresultvalues\"",
+							Doc:     "suggested fixes for unexpected return values\n\nThis checker provides suggested fixes for type errors of the\ntype \"no result values expected\" or \"too many return values\".\nFor example:\n\tfunc z() { return nil }\nwill turn into\n\tfunc z() { return }\n",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopUndeclaredname\"",
+							Doc:     "suggested fixes for \"undeclared name: <>\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"undeclared name: <>\". It will either insert a new statement,\nsuch as:\n\n\"<> := \"\n\nor a new function declaration, such as:\n\nfunc <>(inferred parameters) {\n\tpanic(\"implement me!\")\n}\n",
+							Default: "true",
+						},
+						{
+							Name:    "\"gopUnusedvariable\"",
+							Doc:     "check for unused variables\n\nThe unusedvariable analyzer suggests fixes for unused variables errors.\n",
+							Default: "false",
+						},
 						{
 							Name:    "\"nonewvars\"",
 							Doc:     "suggested fixes for \"no new vars on left side of :=\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no new vars on left side of :=\". For example:\n\tz := 1\n\tz := 2\nwill turn into\n\tz := 1\n\tz = 2\n",
@@ -458,6 +513,11 @@ var GeneratedAPIJSON = &APIJSON{
 							Doc:     "note incomplete struct initializations\n\nThis analyzer provides diagnostics for any struct literals that do not have\nany fields initialized. Because the suggested fix for this analysis is\nexpensive to compute, callers should compute it separately, using the\nSuggestedFix function below.\n",
 							Default: "true",
 						},
+						{
+							Name:    "\"gopStubmethods\"",
+							Doc:     "stub methods analyzer\n\nThis analyzer generates method stubs for concrete types\nin order to implement a target interface",
+							Default: "true",
+						},
 						{
 							Name:    "\"infertypeargs\"",
 							Doc:     "check for unnecessary type arguments in call expressions\n\nExplicit type arguments may be omitted from call expressions if they can be\ninferred from function arguments, or from other type arguments:\n\n\tfunc f[T any](T) {}\n\t\n\tfunc _() {\n\t\tf[string](\"foo\") // string could be inferred\n\t}\n",
@@ -577,6 +637,21 @@ var GeneratedAPIJSON = &APIJSON{
 						Doc:     "Enable/disable inlay hints for implicit type parameters on generic functions:\n```go\n\tmyFoo/*[int, string]*/(1, \"hello\")\n```",
 						Default: "false",
 					},
+					{
+						Name:    "\"gopAliasNames\"",
+						Doc:     "Enable/disable inlay hints for the Go name behind a lowercase Go+ alias:\n```go\n\tprintln/* fmt.Println*/ \"hello\"\n```",
+						Default: "false",
+					},
+					{
+						Name:    "\"lambdaParameterTypes\"",
+						Doc:     "Enable/disable inlay hints for inferred types of lambda parameters:\n```go\n\tsort xs, (a/* int*/, b/* int*/) => a < b\n```",
+						Default: "false",
+					},
+					{
+						Name:    "\"overloadedFunctions\"",
+						Doc:     "Enable/disable inlay hints for the overload selected at a call of an overloaded function:\n```go\n\tadd/* Add__1*/ \"hello\"\n```",
+						Default: "false",
+					},
 					{
 						Name:    "\"parameterNames\"",
 						Doc:     "Enable/disable inlay hints for parameter names:\n```go\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)\n```",
@@ -1003,6 +1078,44 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:  "find structs that would use less memory if their fields were sorted\n\nThis analyzer find structs that can be rearranged to use less memory, and provides\na suggested edit with the most compact order.\n\nNote that there are two different diagnostics reported. One checks struct size,\nand the other reports \"pointer bytes\" used. Pointer bytes is how many bytes of the\nobject that the garbage collector has to potentially scan for pointers, for example:\n\n\tstruct { uint32; string }\n\nhave 16 pointer bytes because the garbage collector has to scan up through the string's\ninner pointer.\n\n\tstruct { string; *uint32 }\n\nhas 24 pointer bytes because it has to scan further through the *uint32.\n\n\tstruct { string; uint32 }\n\nhas 8 because it can stop immediately after the string pointer.\n\nBe aware that the most compact order is not always the most efficient.\nIn rare cases it may cause two variables each updated by its own goroutine\nto occupy the same CPU cache line, inducing a form of memory contention\nknown as \"false sharing\" that slows down both goroutines.\n",
 			URL:  "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/fieldalignment",
 		},
+		{
+			Name:    "gopDeprecated",
+			Doc:     "check for use of deprecated identifiers\n\nThe deprecated analyzer looks for deprecated symbols and package imports.\n\nSee https://go.dev/wiki/Deprecated to learn about Go's convention\nfor documenting and signaling deprecated identifiers.",
+			Default: true,
+		},
+		{
+			Name:    "gopSimplifycompositelit",
+			Doc:     "check for composite literal simplifications\n\nAn array, slice, or map composite literal of the form:\n\t[]T{T{}, T{}}\nwill be simplified to:\n\t[]T{{}, {}}\n\nThis is one of the simplifications that \"gofmt -s\" applies, here to the\nGo+ files.\n\nA slice or a map that is created empty and then filled:\n\ts := make([]int, 0)\n\ts = append(s, 1, 2)\n\tm := make(map[string]int)\n\tm[\"a\"] = 1\nwill be simplified to a Go+ list or map literal:\n\ts := [1, 2]\n\tm := {\"a\": 1}\nprovided that the types of the literals are those of the variables.",
+			URL:     "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifycompositelit",
+			Default: true,
+		},
+		{
+			Name:    "gopSimplifylambda",
+			Doc:     "check for function literals that can be lambdas\n\nA function literal passed as an argument of a function call:\n\tsort.Slice(s, func(i, j int) bool { return s[i] < s[j] })\n\thttp.HandleFunc(\"/\", func(w http.ResponseWriter, r *http.Request) {\n\t\t...\n\t})\nwill be simplified to a Go+ lambda:\n\tsort.Slice(s, (i, j) => s[i] < s[j])\n\thttp.HandleFunc(\"/\", (w, r) => {\n\t\t...\n\t})\nas the types of the parameters and results of a lambda are those of the\nparameter of the function.\n\nFunction literals with unnamed parameters or named results, passed to a\nvariadic or overloaded function, or whose type is not that of the\nparameter are left as is.",
+			URL:     "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifylambda",
+			Default: true,
+		},
+		{
+			Name:    "gopSimplifyrange",
+			Doc:     "check for range statement simplifications\n\nA range of the form:\n\tfor x, _ = range v {...}\nwill be simplified to:\n\tfor x = range v {...}\n\nA range of the form:\n\tfor _ = range v {...}\nwill be simplified to:\n\tfor range v {...}\n\nA for phrase of the form:\n\tfor _, x <- v {...}\nwill be simplified to:\n\tfor x <- v {...}\n\nA loop of the form:\n\tfor i := 0; i < n; i++ {...}\nwhere neither i nor n is changed by the loop body, will be simplified to:\n\tfor i <- 0:n {...}\n\nThe first two are among the simplifications that \"gofmt -s\" applies,\nhere to the Go+ files.",
+			URL:     "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifyrange",
+			Default: true,
+		},
+		{
+			Name:    "gopSimplifyslice",
+			Doc:     "check for slice simplifications\n\nA slice expression of the form:\n\ts[a:len(s)]\nwill be simplified to:\n\ts[a:]\n\nThis is one of the simplifications that \"gofmt -s\" applies, here to the\nGo+ files.",
+			URL:     "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/simplifyslice",
+			Default: true,
+		},
+		{
+			Name: "gopUnusedparams",
+			Doc:  "check for unused parameters of functions\n\nThe unusedparams analyzer checks functions to see if there are\nany parameters that are not being used.\n\nTo reduce false positives it ignores:\n- methods\n- parameters that do not have a name or are underscored\n- functions in test files\n- functions with empty bodies or those with just a return stmt",
+		},
+		{
+			Name: "gopUnusedwrite",
+			Doc:  "checks for unused writes\n\nThe analyzer reports instances of writes to struct fields and\narrays that are never read. Specifically, when a struct object\nor an array is copied, its elements are copied implicitly by\nthe compiler, and any element write to this copy does nothing\nwith the original object.\n\nFor example:\n\n\ttype T struct { x int }\n\n\tfunc f(input []T) {\n\t\tfor i, v := range input {  // v is a copy\n\t\t\tv.x = i  // unused write to field x\n\t\t}\n\t}\n\nAnother example is about non-pointer receiver:\n\n\ttype T struct { x int }\n\n\tfunc (t T) f() {  // t is a copy\n\t\tt.x = i  // unused write to field x\n\t}",
+			URL:  "https://pkg.go.dev/golang.org/x/tools/gop/analysis/passes/unusedwrite",
+		},
 		{
 			Name:    "httpresponse",
 			Doc:     "check for mistakes using HTTP responses\n\nA common mistake when using the net/http package is to defer a function\ncall to close the http.Response Body before checking the error that\ndetermines whether the response is valid:\n\n\tresp, err := http.Head(url)\n\tdefer resp.Body.Close()\n\tif err != nil {\n\t\tlog.Fatal(err)\n\t}\n\t// (defer statement belongs here)\n\nThis checker helps uncover latent nil dereference bugs by reporting a\ndiagnostic for such mistakes.",
@@ -1159,6 +1272,25 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "suggest fixes for errors due to an incorrect number of return values\n\nThis checker provides suggested fixes for type errors of the\ntype \"wrong number of return values (want %d, got %d)\". For example:\n\tfunc m() (int, string, *bool, error) {\n\t\treturn\n\t}\nwill turn into\n\tfunc m() (int, string, *bool, error) {\n\t\treturn 0, \"\", nil, nil\n\t}\n\nThis functionality is similar to https://github.com/sqs/goreturns.\n",
 			Default: true,
 		},
+		{
+			Name:    "gopNonewvars",
+			Doc:     "suggested fixes for \"no new vars on left side of :=\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no new vars on left side of :=\". For example:\n\tz := 1\n\tz := 2\nwill turn into\n\tz := 1\n\tz = 2\n",
+			Default: true,
+		},
+		{
+			Name:    "gopNoresultvalues",
+			Doc:     "suggested fixes for unexpected return values\n\nThis checker provides suggested fixes for type errors of the\ntype \"no result values expected\" or \"too many return values\".\nFor example:\n\tfunc z() { return nil }\nwill turn into\n\tfunc z() { return }\n",
+			Default: true,
+		},
+		{
+			Name:    "gopUndeclaredname",
+			Doc:     "suggested fixes for \"undeclared name: <>\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"undeclared name: <>\". It will either insert a new statement,\nsuch as:\n\n\"<> := \"\n\nor a new function declaration, such as:\n\nfunc <>(inferred parameters) {\n\tpanic(\"implement me!\")\n}\n",
+			Default: true,
+		},
+		{
+			Name: "gopUnusedvariable",
+			Doc:  "check for unused variables\n\nThe unusedvariable analyzer suggests fixes for unused variables errors.\n",
+		},
 		{
 			Name:    "nonewvars",
 			Doc:     "suggested fixes for \"no new vars on left side of :=\"\n\nThis checker provides suggested fixes for type errors of the\ntype \"no new vars on left side of :=\". For example:\n\tz := 1\n\tz := 2\nwill turn into\n\tz := 1\n\tz = 2\n",
@@ -1183,6 +1315,11 @@ var GeneratedAPIJSON = &APIJSON{
 			Doc:     "note incomplete struct initializations\n\nThis analyzer provides diagnostics for any struct literals that do not have\nany fields initialized. Because the suggested fix for this analysis is\nexpensive to compute, callers should compute it separately, using the\nSuggestedFix function below.\n",
 			Default: true,
 		},
+		{
+			Name:    "gopStubmethods",
+			Doc:     "stub methods analyzer\n\nThis analyzer generates method stubs for concrete types\nin order to implement a target interface",
+			Default: true,
+		},
 		{
 			Name:    "infertypeargs",
 			Doc:     "check for unnecessary type arguments in call expressions\n\nExplicit type arguments may be omitted from call expressions if they can be\ninferred from function arguments, or from other type arguments:\n\n\tfunc f[T any](T) {}\n\t\n\tfunc _() {\n\t\tf[string](\"foo\") // string could be inferred\n\t}\n",
@@ -1215,6 +1352,18 @@ var GeneratedAPIJSON = &APIJSON{
 			Name: "functionTypeParameters",
 			Doc:  "Enable/disable inlay hints for implicit type parameters on generic functions:\n```go\n\tmyFoo/*[int, string]*/(1, \"hello\")\n```",
 		},
+		{
+			Name: "gopAliasNames",
+			Doc:  "Enable/disable inlay hints for the Go name behind a lowercase Go+ alias:\n```go\n\tprintln/* fmt.Println*/ \"hello\"\n```",
+		},
+		{
+			Name: "lambdaParameterTypes",
+			Doc:  "Enable/disable inlay hints for inferred types of lambda parameters:\n```go\n\tsort xs, (a/* int*/, b/* int*/) => a < b\n```",
+		},
+		{
+			Name: "overloadedFunctions",
+			Doc:  "Enable/disable inlay hints for the overload selected at a call of an overloaded function:\n```go\n\tadd/* Add__1*/ \"hello\"\n```",
+		},
 		{
 			Name: "parameterNames",
 			Doc:  "Enable/disable inlay hints for parameter names:\n```go\n\tparseInt(/* str: */ \"123\", /* radix: */ 8)\n```",
@@ -0,0 +1,257 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+// isGopAutogen reports whether uri is a Go file generated by gop.
+func isGopAutogen(uri span.URI) bool {
+	return strings.HasPrefix(filepath.Base(uri.Filename()), "gop_autogen")
+}
+
+// A gopAutogenMapper translates positions within the gop_autogen*.go
+// files to the Go+ sources that produced them, following the //line
+// directives of the generated code.
+//
+// The columns of the generated code are unrelated to those of the Go+
+// source: a translated position is that of the first occurrence of the
+// same identifier on the Go+ line, or else the start of that line.
+type gopAutogenMapper struct {
+	ctx   context.Context
+	fs    FileSource
+	files map[span.URI]*token.File    // scanned generated files, or nil
+	srcs  map[span.URI]*gopSourceFile // Go+ sources, or nil
+}
+
+type gopSourceFile struct {
+	mapper *protocol.Mapper
+	lines  [][]byte
+}
+
+func newGopAutogenMapper(ctx context.Context, fs FileSource) *gopAutogenMapper {
+	return &gopAutogenMapper{
+		ctx:   ctx,
+		fs:    fs,
+		files: make(map[span.URI]*token.File),
+		srcs:  make(map[span.URI]*gopSourceFile),
+	}
+}
+
+// GopAutogenLocations translates the locations within gop_autogen*.go
+// files to the Go+ sources that produced them, in place.
+func GopAutogenLocations(ctx context.Context, fs FileSource, locs []protocol.Location) []protocol.Location {
+	var m *gopAutogenMapper
+	for i, loc := range locs {
+		if !isGopAutogen(loc.URI.SpanURI()) {
+			continue
+		}
+		if m == nil {
+			m = newGopAutogenMapper(ctx, fs)
+		}
+		locs[i] = m.Location(loc)
+	}
+	return locs
+}
+
+// gopAutogenReferences translates the locations of refs within
+// gop_autogen*.go files to the Go+ sources that produced them.
+func gopAutogenReferences(ctx context.Context, fs FileSource, refs []reference) {
+	var m *gopAutogenMapper
+	for i, ref := range refs {
+		if !isGopAutogen(ref.location.URI.SpanURI()) {
+			continue
+		}
+		if m == nil {
+			m = newGopAutogenMapper(ctx, fs)
+		}
+		refs[i].location = m.Location(ref.location)
+	}
+}
+
+// Location returns loc translated to the Go+ source, or loc itself if
+// it is not within generated code of a Go+ source.
+func (m *gopAutogenMapper) Location(loc protocol.Location) protocol.Location {
+	uri := loc.URI.SpanURI()
+	tf := m.file(uri)
+	if tf == nil {
+		return loc
+	}
+	fh, err := m.fs.ReadFile(m.ctx, uri)
+	if err != nil {
+		return loc
+	}
+	content, err := fh.Content()
+	if err != nil {
+		return loc
+	}
+	start, end, err := protocol.NewMapper(uri, content).RangeOffsets(loc.Range)
+	if err != nil {
+		return loc
+	}
+	srcURI, src, line, ok := m.source(uri, tf, int(loc.Range.Start.Line)+1)
+	if !ok {
+		return loc
+	}
+	text := src.lines[line-1]
+	lineStart := 0
+	for _, l := range src.lines[:line-1] {
+		lineStart += len(l) + 1
+	}
+	col, n := gopIdentIndex(text, content[start:end])
+	rng, err := src.mapper.OffsetRange(lineStart+col, lineStart+col+n)
+	if err != nil {
+		return loc
+	}
+	return protocol.Location{URI: protocol.URIFromSpanURI(srcURI), Range: rng}
+}
+
+// linePosition returns the position of the first non-blank character of
+// the Go+ line that produced the given (1-based) line of a generated
+// file.
+func (m *gopAutogenMapper) linePosition(uri span.URI, line int) (span.URI, protocol.Position, bool) {
+	tf := m.file(uri)
+	if tf == nil {
+		return "", protocol.Position{}, false
+	}
+	srcURI, src, line, ok := m.source(uri, tf, line)
+	if !ok {
+		return "", protocol.Position{}, false
+	}
+	text := src.lines[line-1]
+	indent := len(text) - len(bytes.TrimLeft(text, " \t"))
+	return srcURI, protocol.Position{Line: uint32(line - 1), Character: uint32(indent)}, true
+}
+
+// file returns the scanned generated file, whose line table records its
+// //line directives. The file is named by its base name so that the
+// relative names of the directives are kept as they are.
+func (m *gopAutogenMapper) file(uri span.URI) *token.File {
+	if tf, ok := m.files[uri]; ok {
+		return tf
+	}
+	var tf *token.File
+	if fh, err := m.fs.ReadFile(m.ctx, uri); err == nil {
+		if content, err := fh.Content(); err == nil {
+			tf = token.NewFileSet().AddFile(filepath.Base(uri.Filename()), -1, len(content))
+			var s scanner.Scanner
+			s.Init(tf, content, nil, 0)
+			for {
+				if _, tok, _ := s.Scan(); tok == token.EOF {
+					break
+				}
+			}
+		}
+	}
+	m.files[uri] = tf
+	return tf
+}
+
+// source returns the Go+ source file and line that produced the given
+// (1-based) line of the generated file uri, scanned as tf.
+func (m *gopAutogenMapper) source(genURI span.URI, tf *token.File, line int) (span.URI, *gopSourceFile, int, bool) {
+	if line < 1 || line > tf.LineCount() {
+		return "", nil, 0, false
+	}
+	posn := safetoken.AdjustedPosition(tf, tf.LineStart(line))
+	if !posn.IsValid() || posn.Filename == tf.Name() || filepath.Ext(posn.Filename) == ".go" {
+		return "", nil, 0, false
+	}
+	// Relative names are relative to the module root, which is the
+	// directory of the generated file or one of its parents.
+	var src *gopSourceFile
+	var uri span.URI
+	for dir := filepath.Dir(genURI.Filename()); ; {
+		filename := posn.Filename
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(dir, filename)
+		}
+		uri = span.URIFromPath(filename)
+		if src = m.sourceFile(uri); src != nil || filepath.IsAbs(posn.Filename) {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	if src == nil || posn.Line > len(src.lines) {
+		return "", nil, 0, false
+	}
+	return uri, src, posn.Line, true
+}
+
+// sourceFile returns the Go+ source file uri, or nil if it can't be read.
+func (m *gopAutogenMapper) sourceFile(uri span.URI) *gopSourceFile {
+	src, ok := m.srcs[uri]
+	if !ok {
+		if fh, err := m.fs.ReadFile(m.ctx, uri); err == nil {
+			if content, err := fh.Content(); err == nil {
+				src = &gopSourceFile{
+					mapper: protocol.NewMapper(uri, content),
+					lines:  bytes.Split(content, []byte("\n")),
+				}
+			}
+		}
+		m.srcs[uri] = src
+	}
+	return src
+}
+
+// gopIdentIndex returns the offset and length of the first occurrence
+// of the identifier name within line, or the offset of the first
+// non-blank character if there is none. The Go+ name of an overloaded
+// function member (such as add__0) is its overload name (add), and Go+
+// code may spell an exported name with a lower-case initial (Add__0 and
+// Println as add and println).
+func gopIdentIndex(line, name []byte) (int, int) {
+	names := [][]byte{name}
+	if i := bytes.LastIndex(name, []byte("__")); i > 0 {
+		names = append(names, name[:i])
+	}
+	for _, name := range names {
+		if r, size := utf8.DecodeRune(name); unicode.IsUpper(r) {
+			lower := utf8.AppendRune(nil, unicode.ToLower(r))
+			names = append(names, append(lower, name[size:]...))
+		}
+	}
+	for _, name := range names {
+		if len(name) == 0 {
+			continue
+		}
+		for off := 0; ; {
+			i := bytes.Index(line[off:], name)
+			if i < 0 {
+				break
+			}
+			i += off
+			j := i + len(name)
+			before, _ := utf8.DecodeLastRune(line[:i])
+			after, _ := utf8.DecodeRune(line[j:])
+			if !isIdentRune(before) && !isIdentRune(after) {
+				return i, len(name)
+			}
+			off = j
+		}
+	}
+	return len(line) - len(bytes.TrimLeft(line, " \t")), 0
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+const gopAutogenSrc = `package a
+
+func Add = (
+	func(a, b int) int {
+		return a + b
+	}
+	func(a, b string) string {
+		return a + b
+	}
+)
+
+func Twice(x int) int {
+	return add(x, x)
+}
+`
+
+// gopAutogenGen is the code generated for gopAutogenSrc, whose //line
+// directives are relative to the module root.
+const gopAutogenGen = `// Code generated by gop (Go+); DO NOT EDIT.
+
+package a
+
+const GopPackage = true
+const _ = true
+//line a/a.gop:4:1
+func Add__0(a int, b int) int {
+//line a/a.gop:5:1
+	return a + b
+}
+//line a/a.gop:7:1
+func Add__1(a string, b string) string {
+//line a/a.gop:8:1
+	return a + b
+}
+//line a/a.gop:12:1
+func Twice(x int) int {
+//line a/a.gop:13:1
+	return Add__0(x, x)
+}
+`
+
+func TestGopAutogenMapper(t *testing.T) {
+	root := t.TempDir()
+	genURI := span.URIFromPath(filepath.Join(root, "a", "gop_autogen.go"))
+	srcURI := span.URIFromPath(filepath.Join(root, "a", "a.gop"))
+	fs := testFileSource{
+		genURI: gopAutogenGen,
+		srcURI: gopAutogenSrc,
+		span.URIFromPath(filepath.Join(root, "go.mod")): "module mod.com\n",
+	}
+	m := newGopAutogenMapper(context.Background(), fs)
+
+	// location returns the location of the first match of re on the
+	// (1-based) line of the file with the given content.
+	location := func(uri span.URI, content, re string, line int) protocol.Location {
+		lines := bytes.SplitAfter([]byte(content), []byte("\n"))
+		start := len(bytes.Join(lines[:line-1], nil))
+		i := bytes.Index(lines[line-1], []byte(re))
+		if i < 0 {
+			t.Fatalf("no %q on line %d of %s", re, line, uri)
+		}
+		loc, err := protocol.NewMapper(uri, []byte(content)).OffsetLocation(start+i, start+i+len(re))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc
+	}
+
+	for _, test := range []struct {
+		re        string
+		line      int // of the generated file
+		wantRe    string
+		wantLine  int // of the Go+ file, or 0 if not translated
+		wantEmpty bool
+	}{
+		{"Add__0", 20, "add", 13, false},
+		{"Twice", 18, "Twice", 12, false},
+		{"Add__0", 8, "func", 4, true}, // the overload member has no name
+		{"GopPackage", 5, "", 0, false},
+	} {
+		got := m.Location(location(genURI, gopAutogenGen, test.re, test.line))
+		want := location(genURI, gopAutogenGen, test.re, test.line)
+		if test.wantLine > 0 {
+			want = location(srcURI, gopAutogenSrc, test.wantRe, test.wantLine)
+			if test.wantEmpty {
+				want.Range.End = want.Range.Start
+			}
+		}
+		if got != want {
+			t.Errorf("Location(%s at line %d) = %v, want %v", test.re, test.line, got, want)
+		}
+	}
+
+	uri, pos, ok := m.linePosition(genURI, 10)
+	if want := (protocol.Position{Line: 4, Character: 2}); !ok || uri != srcURI || pos != want {
+		t.Errorf("linePosition(10) = %s, %v, %t, want %s, %v", uri, pos, ok, srcURI, want)
+	}
+	if _, _, ok := m.linePosition(genURI, 3); ok {
+		t.Errorf("linePosition(3) succeeded for a line without a directive")
+	}
+}
+
+type testFileSource map[span.URI]string
+
+func (fs testFileSource) ReadFile(ctx context.Context, uri span.URI) (FileHandle, error) {
+	content, ok := fs[uri]
+	return testFileHandle{uri, content, ok}, nil
+}
+
+type testFileHandle struct {
+	uri     span.URI
+	content string
+	exists  bool
+}
+
+func (fh testFileHandle) URI() span.URI { return fh.uri }
+func (fh testFileHandle) FileIdentity() FileIdentity {
+	return FileIdentity{URI: fh.uri, Hash: HashOf([]byte(fh.content))}
+}
+func (fh testFileHandle) Saved() bool    { return true }
+func (fh testFileHandle) Version() int32 { return 0 }
+func (fh testFileHandle) Content() ([]byte, error) {
+	if !fh.exists {
+		return nil, fmt.Errorf("%s does not exist", fh.uri)
+	}
+	return []byte(fh.content), nil
+}
+
+func TestGopIdentIndex(t *testing.T) {
+	tests := []struct {
+		line, name string
+		off, n     int
+	}{
+		{"\tx := add(1, 2)", "add", 6, 3},
+		{"\tx := adder + add(1, 2)", "add", 14, 3},
+		{"\tx := add(1, 2)", "add__0", 6, 3},
+		{"\tx := add(1, 2)", "Add__0", 6, 3},
+		{"\tx := t.add(1, 2)", "Add", 8, 3},
+		{"\tprintln \"hi\"", "Println", 1, 7},
+		{"\tx := Add(1, 2)", "Add__1", 6, 3},
+		{"\tx := y", "Add__0", 1, 0},
+		{"  x := Adder(1)", "Add", 2, 0},
+	}
+	for _, test := range tests {
+		off, n := gopIdentIndex([]byte(test.line), []byte(test.name))
+		if off != test.off || n != test.n {
+			t.Errorf("gopIdentIndex(%q, %q) = %d, %d, want %d, %d", test.line, test.name, off, n, test.off, test.n)
+		}
+	}
+}
@@ -130,7 +130,7 @@ func Definition(ctx context.Context, snapshot Snapshot, fh FileHandle, position
 	if err != nil {
 		return nil, err
 	}
-	return []protocol.Location{loc}, nil
+	return GopAutogenLocations(ctx, snapshot, []protocol.Location{loc}), nil // goxls: Go+ sources
 }
 
 // referencedObject returns the identifier and object referenced at the
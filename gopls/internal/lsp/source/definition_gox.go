@@ -143,7 +143,7 @@ func GopDefinition(ctx context.Context, snapshot Snapshot, fh FileHandle, positi
 	if err != nil {
 		return nil, err
 	}
-	return []protocol.Location{loc}, nil
+	return GopAutogenLocations(ctx, snapshot, []protocol.Location{loc}), nil
 }
 
 // gopReferencedObject returns the identifier and object referenced at the
@@ -35,6 +35,7 @@ var gopSuggestedFixes = map[string]SuggestedFixFunc{
 	InvertIfCondition: gopSingleFile(gopInvertIfCondition),
 	StubMethods:       gopStubSuggestedFixFunc,
 	AddEmbedImport:    gopAddEmbedImport,
+	InlineCall:        gopInlineCall,
 }
 
 // gopSingleFile calls analyzers that expect inputs for a single file
@@ -0,0 +1,216 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"golang.org/x/tools/gopls/internal/bug"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+)
+
+// GopFoldingRange gets all of the folding range for a Go+ file.
+func GopFoldingRange(ctx context.Context, snapshot Snapshot, fh FileHandle, lineFoldingOnly bool) (ranges []*FoldingRangeInfo, err error) {
+	pgf, err := snapshot.ParseGop(ctx, fh, parserutil.ParseFull)
+	if err != nil {
+		return nil, err
+	}
+
+	// With parse errors, we wouldn't be able to produce accurate folding info.
+	// See FoldingRange.
+	if pgf.ParseErr != nil {
+		return nil, nil
+	}
+
+	// Get folding ranges for comments separately as they are not walked by ast.Inspect.
+	ranges = append(ranges, gopCommentsFoldingRange(pgf)...)
+
+	visit := func(n ast.Node) bool {
+		rng := gopFoldingRangeFunc(pgf, n, lineFoldingOnly)
+		if rng != nil {
+			ranges = append(ranges, rng)
+		}
+		return true
+	}
+	// Walk the ast and collect folding ranges.
+	ast.Inspect(pgf.File, visit)
+
+	sort.Slice(ranges, func(i, j int) bool {
+		irng := ranges[i].MappedRange.Range()
+		jrng := ranges[j].MappedRange.Range()
+		return protocol.CompareRange(irng, jrng) < 0
+	})
+
+	return ranges, nil
+}
+
+// gopFoldingRangeFunc calculates the line folding range for ast.Node n
+func gopFoldingRangeFunc(pgf *ParsedGopFile, n ast.Node, lineFoldingOnly bool) *FoldingRangeInfo {
+	var kind protocol.FoldingRangeKind
+	var start, end token.Pos
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		// The top-level statements of a script or classfile are gathered
+		// in a shadow entry without braces: fold them from the end of the
+		// first line to the end of the last statement.
+		if n.Shadow && n.Body != nil {
+			if num := len(n.Body.List); num != 0 {
+				first, last := n.Body.List[0], n.Body.List[num-1]
+				start, end = gopLineEnd(pgf, first.Pos()), last.End()
+				kind = protocol.Region
+			}
+		}
+	case *ast.BlockStmt:
+		if !n.Lbrace.IsValid() {
+			break // shadow entry, see above
+		}
+		// Fold between positions of or lines between "{" and "}".
+		var startList, endList token.Pos
+		if num := len(n.List); num != 0 {
+			startList, endList = n.List[0].Pos(), n.List[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lbrace, n.Rbrace, startList, endList, lineFoldingOnly)
+	case *ast.CaseClause:
+		// Fold from position of ":" to end.
+		start, end = n.Colon+1, n.End()
+	case *ast.CommClause:
+		// Fold from position of ":" to end.
+		start, end = n.Colon+1, n.End()
+	case *ast.CallExpr:
+		// Fold from position of "(" to position of ")".
+		// Command-style calls have no parentheses.
+		if n.Lparen.IsValid() && n.Rparen.IsValid() {
+			start, end = n.Lparen+1, n.Rparen
+		}
+	case *ast.FieldList:
+		// Fold between positions of or lines between opening parenthesis/brace and closing parenthesis/brace.
+		var startList, endList token.Pos
+		if num := len(n.List); num != 0 {
+			startList, endList = n.List[0].Pos(), n.List[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Opening, n.Closing, startList, endList, lineFoldingOnly)
+	case *ast.GenDecl:
+		// If this is an import declaration, set the kind to be protocol.Imports.
+		if n.Tok == token.IMPORT {
+			kind = protocol.Imports
+		}
+		// Fold between positions of or lines between "(" and ")".
+		var startSpecs, endSpecs token.Pos
+		if num := len(n.Specs); num != 0 {
+			startSpecs, endSpecs = n.Specs[0].Pos(), n.Specs[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lparen, n.Rparen, startSpecs, endSpecs, lineFoldingOnly)
+	case *ast.BasicLit:
+		// Fold raw string literals from position of "`" to position of "`".
+		if n.Kind == token.STRING && len(n.Value) >= 2 && n.Value[0] == '`' && n.Value[len(n.Value)-1] == '`' {
+			start, end = n.Pos(), n.End()
+		}
+	case *ast.CompositeLit:
+		// Fold between positions of or lines between "{" and "}".
+		var startElts, endElts token.Pos
+		if num := len(n.Elts); num != 0 {
+			startElts, endElts = n.Elts[0].Pos(), n.Elts[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lbrace, n.Rbrace, startElts, endElts, lineFoldingOnly)
+	case *ast.SliceLit:
+		// Fold between positions of or lines between "[" and "]".
+		var startElts, endElts token.Pos
+		if num := len(n.Elts); num != 0 {
+			startElts, endElts = n.Elts[0].Pos(), n.Elts[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lbrack, n.Rbrack, startElts, endElts, lineFoldingOnly)
+	case *ast.ComprehensionExpr:
+		// Fold between positions of or lines between "[" or "{" and "]" or "}".
+		startElts, endElts := token.NoPos, token.NoPos
+		if n.Elt != nil {
+			startElts = n.Elt.Pos()
+		} else if len(n.Fors) != 0 {
+			startElts = n.Fors[0].Pos()
+		}
+		if num := len(n.Fors); num != 0 {
+			endElts = n.Fors[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lpos, n.Rpos, startElts, endElts, lineFoldingOnly)
+	case *ast.LambdaExpr:
+		// Fold multi-line lambdas from "=>" to the end of the last expression;
+		// a lambda with a block body (LambdaExpr2) folds its BlockStmt.
+		if num := len(n.Rhs); num != 0 {
+			start, end = n.Rarrow+token.Pos(len("=>")), n.Rhs[num-1].End()
+		}
+	case *ast.OverloadFuncDecl:
+		// Fold between positions of or lines between "(" and ")".
+		var startFuncs, endFuncs token.Pos
+		if num := len(n.Funcs); num != 0 {
+			startFuncs, endFuncs = n.Funcs[0].Pos(), n.Funcs[num-1].End()
+		}
+		start, end = validLineFoldingRange(pgf.Tok, n.Lparen, n.Rparen, startFuncs, endFuncs, lineFoldingOnly)
+	}
+
+	// Check that folding positions are valid.
+	if !start.IsValid() || !end.IsValid() {
+		return nil
+	}
+	// in line folding mode, do not fold if the start and end lines are the same.
+	// Shadow entries and lambdas are only folded when they span several lines.
+	_, isLambda := n.(*ast.LambdaExpr)
+	if (lineFoldingOnly || isLambda || kind == protocol.Region) && safetoken.Line(pgf.Tok, start) == safetoken.Line(pgf.Tok, end) {
+		return nil
+	}
+	mrng, err := pgf.PosMappedRange(start, end)
+	if err != nil {
+		bug.Errorf("%w", err) // can't happen
+	}
+	return &FoldingRangeInfo{
+		MappedRange: mrng,
+		Kind:        kind,
+	}
+}
+
+// gopLineEnd returns the position of the end of the line containing pos.
+func gopLineEnd(pgf *ParsedGopFile, pos token.Pos) token.Pos {
+	line := safetoken.Line(pgf.Tok, pos)
+	if line >= pgf.Tok.LineCount() {
+		return pgf.Tok.Pos(pgf.Tok.Size())
+	}
+	return pgf.Tok.LineStart(line+1) - 1
+}
+
+// gopCommentsFoldingRange returns the folding ranges for all comment blocks in file.
+// See commentsFoldingRange.
+func gopCommentsFoldingRange(pgf *ParsedGopFile) (comments []*FoldingRangeInfo) {
+	tokFile := pgf.Tok
+	for _, commentGrp := range pgf.File.Comments {
+		startGrpLine, endGrpLine := safetoken.Line(tokFile, commentGrp.Pos()), safetoken.Line(tokFile, commentGrp.End())
+		if startGrpLine == endGrpLine {
+			// Don't fold single line comments.
+			continue
+		}
+
+		firstComment := commentGrp.List[0]
+		startPos, endLinePos := firstComment.Pos(), firstComment.End()
+		startCmmntLine, endCmmntLine := safetoken.Line(tokFile, startPos), safetoken.Line(tokFile, endLinePos)
+		if startCmmntLine != endCmmntLine {
+			// If the first comment spans multiple lines, then we want to have the
+			// folding range start at the end of the first line.
+			endLinePos = token.Pos(int(startPos) + len(strings.Split(firstComment.Text, "\n")[0]))
+		}
+		mrng, err := pgf.PosMappedRange(endLinePos, commentGrp.End())
+		if err != nil {
+			bug.Errorf("%w", err) // can't happen
+		}
+		comments = append(comments, &FoldingRangeInfo{
+			// Fold from the end of the first line comment to the end of the comment block.
+			MappedRange: mrng,
+			Kind:        protocol.Comment,
+		})
+	}
+	return comments
+}
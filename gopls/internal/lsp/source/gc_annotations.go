@@ -95,6 +95,12 @@ func GCOptimizationDetails(ctx context.Context, snapshot Snapshot, m *Metadata)
 			// outside the package can never be taken back.
 			continue
 		}
+		if isGopAutogen(fh.URI()) { // goxls: report on the Go+ files that produced the code
+			if err := gopGCDetails(ctx, snapshot, fh.URI(), diagnostics, reports); err != nil {
+				parseError = err
+			}
+			continue
+		}
 		reports[fh.URI()] = diagnostics
 	}
 	return reports, parseError
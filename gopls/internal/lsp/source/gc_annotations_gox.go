@@ -0,0 +1,52 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+// gopGCDetails translates the gc details reported on the generated Go
+// file genURI back onto the Go+ files that produced it, following the
+// //line directives of the generated file, and adds them to reports.
+//
+// The columns of the generated code are unrelated to those of the Go+
+// source, so translated details are placed at the start of the line.
+// Details on lines not covered by a directive are dropped.
+func gopGCDetails(ctx context.Context, snapshot Snapshot, genURI span.URI, diagnostics []*Diagnostic, reports map[span.URI][]*Diagnostic) error {
+	if _, err := snapshot.ReadFile(ctx, genURI); err != nil {
+		return err
+	}
+	m := newGopAutogenMapper(ctx, snapshot)
+	for _, d := range diagnostics {
+		uri, start, ok := m.linePosition(genURI, int(d.Range.Start.Line)+1)
+		if !ok {
+			continue
+		}
+		var related []protocol.DiagnosticRelatedInformation
+		for _, ri := range d.Related {
+			if span.URI(ri.Location.URI) == genURI {
+				riURI, riStart, ok := m.linePosition(genURI, int(ri.Location.Range.Start.Line)+1)
+				if !ok {
+					continue
+				}
+				ri.Location = protocol.Location{
+					URI:   protocol.URIFromSpanURI(riURI),
+					Range: protocol.Range{Start: riStart, End: riStart},
+				}
+			}
+			related = append(related, ri)
+		}
+		mapped := *d
+		mapped.URI = uri
+		mapped.Range = protocol.Range{Start: start, End: start}
+		mapped.Related = related
+		reports[uri] = append(reports[uri], &mapped)
+	}
+	return nil
+}
@@ -55,6 +55,7 @@ func Implementation(ctx context.Context, snapshot Snapshot, f FileHandle, pp pro
 	if err != nil {
 		return nil, err
 	}
+	locs = GopAutogenLocations(ctx, snapshot, locs) // goxls: Go+ sources
 
 	// Sort and de-duplicate locations.
 	sort.Slice(locs, func(i, j int) bool {
@@ -54,6 +54,7 @@ func GopImplementation(ctx context.Context, snapshot Snapshot, f FileHandle, pp
 	if err != nil {
 		return nil, err
 	}
+	locs = GopAutogenLocations(ctx, snapshot, locs)
 
 	// Sort and de-duplicate locations.
 	sort.Slice(locs, func(i, j int) bool {
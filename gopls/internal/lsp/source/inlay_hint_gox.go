@@ -0,0 +1,494 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"go/constant"
+	"go/types"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/internal/event"
+)
+
+// Go+ only inlay hints.
+const (
+	LambdaParameterTypes = "lambdaParameterTypes"
+	OverloadedFunctions  = "overloadedFunctions"
+	GopAliasNames        = "gopAliasNames"
+)
+
+type GopInlayHintFunc func(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint
+
+type GopHint struct {
+	Name string
+	Doc  string
+	Run  GopInlayHintFunc
+}
+
+// AllGopInlayHints holds the inlay hints available for Go+ files. Hints
+// shared with Go use the same names as in AllInlayHints, so that a single
+// setting enables them for both languages.
+var AllGopInlayHints = map[string]*GopHint{
+	AssignVariableTypes: {
+		Name: AssignVariableTypes,
+		Doc:  "Enable/disable inlay hints for variable types in assign statements:\n```go\n\ti/* int*/, j/* int*/ := 0, len(r)-1\n```",
+		Run:  gopAssignVariableTypes,
+	},
+	ParameterNames: {
+		Name: ParameterNames,
+		Doc:  "Enable/disable inlay hints for parameter names, including command-style calls:\n```go\n\tparseInt /* str: */ \"123\", /* radix: */ 8\n```",
+		Run:  gopParameterNames,
+	},
+	ConstantValues: {
+		Name: ConstantValues,
+		Doc:  "Enable/disable inlay hints for constant values:\n```go\n\tconst (\n\t\tKindNone   Kind = iota/* = 0*/\n\t\tKindPrint/*  = 1*/\n\t)\n```",
+		Run:  gopConstantValues,
+	},
+	RangeVariableTypes: {
+		Name: RangeVariableTypes,
+		Doc:  "Enable/disable inlay hints for variable types in range statements and for phrases:\n```go\n\tfor k/* int*/, v/* string*/ <- []string{} {\n\t\techo k, v\n\t}\n```",
+		Run:  gopRangeVariableTypes,
+	},
+	CompositeLiteralTypes: {
+		Name: CompositeLiteralTypes,
+		Doc:  "Enable/disable inlay hints for composite literal types:\n```go\n\t[]struct{ in string }{/*struct{ in string }*/{\"Hello, world\"}}\n```",
+		Run:  gopCompositeLiteralTypes,
+	},
+	CompositeLiteralFieldNames: {
+		Name: CompositeLiteralFieldNames,
+		Doc:  "Enable/disable inlay hints for composite literal field names:\n```go\n\t{/*in: */\"Hello, world\", /*want: */\"dlrow ,olleH\"}\n```",
+		Run:  gopCompositeLiteralFields,
+	},
+	FunctionTypeParameters: {
+		Name: FunctionTypeParameters,
+		Doc:  "Enable/disable inlay hints for implicit type parameters on generic functions:\n```go\n\tmyFoo/*[int, string]*/(1, \"hello\")\n```",
+		Run:  gopFuncTypeParams,
+	},
+	LambdaParameterTypes: {
+		Name: LambdaParameterTypes,
+		Doc:  "Enable/disable inlay hints for inferred types of lambda parameters:\n```go\n\tsort xs, (a/* int*/, b/* int*/) => a < b\n```",
+		Run:  gopLambdaParameterTypes,
+	},
+	OverloadedFunctions: {
+		Name: OverloadedFunctions,
+		Doc:  "Enable/disable inlay hints for the overload selected at a call of an overloaded function:\n```go\n\tadd/* Add__1*/ \"hello\"\n```",
+		Run:  gopOverloadedFunctions,
+	},
+	GopAliasNames: {
+		Name: GopAliasNames,
+		Doc:  "Enable/disable inlay hints for the Go name behind a lowercase Go+ alias:\n```go\n\tprintln/* fmt.Println*/ \"hello\"\n```",
+		Run:  gopAliasNames,
+	},
+}
+
+// GopInlayHint implements the "textDocument/inlayHint" RPC for Go+ files.
+func GopInlayHint(ctx context.Context, snapshot Snapshot, fh FileHandle, pRng protocol.Range) ([]protocol.InlayHint, error) {
+	ctx, done := event.Start(ctx, "source.GopInlayHint")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, fmt.Errorf("getting file for GopInlayHint: %w", err)
+	}
+
+	// Collect a list of the inlay hints that are enabled.
+	inlayHintOptions := snapshot.View().Options().InlayHintOptions
+	var enabledHints []GopInlayHintFunc
+	for hint, enabled := range inlayHintOptions.Hints {
+		if !enabled {
+			continue
+		}
+		if h, ok := AllGopInlayHints[hint]; ok {
+			enabledHints = append(enabledHints, h.Run)
+		}
+	}
+	if len(enabledHints) == 0 {
+		return nil, nil
+	}
+
+	info := pkg.GopTypesInfo()
+	q := GopQualifier(pgf.File, pkg.GetTypes(), info)
+
+	// Set the range to the full file if the range is not valid.
+	start, end := pgf.Tok.Pos(0), pgf.Tok.Pos(pgf.Tok.Size())
+	if pRng.Start.Line < pRng.End.Line || pRng.Start.Character < pRng.End.Character {
+		// Adjust start and end for the specified range.
+		var err error
+		start, end, err = pgf.RangePos(pRng)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hints []protocol.InlayHint
+	ast.Inspect(pgf.File, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+		// The shadow entry of a script or classfile has no source of its
+		// own, so only its statements are checked against the range.
+		if fn, ok := node.(*ast.FuncDecl); ok && fn.Shadow {
+			return true
+		}
+		// If not in range, we can stop looking.
+		if node.End() < start || node.Pos() > end {
+			return false
+		}
+		for _, fn := range enabledHints {
+			hints = append(hints, fn(node, pgf.Mapper, pgf.Tok, info, &q)...)
+		}
+		return true
+	})
+	return hints, nil
+}
+
+func gopParameterNames(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	callExpr, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	signature, ok := info.TypeOf(callExpr.Fun).(*types.Signature)
+	if !ok {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for i, v := range callExpr.Args {
+		start, err := m.PosPosition(tf, v.Pos())
+		if err != nil {
+			continue
+		}
+		params := signature.Params()
+		// When a function has variadic params, we skip args after
+		// params.Len().
+		if i > params.Len()-1 {
+			break
+		}
+		param := params.At(i)
+		// param.Name is empty for built-ins like append
+		if param.Name() == "" {
+			continue
+		}
+		// Skip the parameter name hint if the arg matches
+		// the parameter name.
+		if i, ok := v.(*ast.Ident); ok && i.Name == param.Name() {
+			continue
+		}
+		// A lambda argument already spells out its parameters.
+		switch v.(type) {
+		case *ast.LambdaExpr, *ast.LambdaExpr2:
+			continue
+		}
+
+		label := param.Name()
+		if signature.Variadic() && i == params.Len()-1 {
+			label = label + "..."
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:     start,
+			Label:        buildLabel(label + ":"),
+			Kind:         protocol.Parameter,
+			PaddingRight: true,
+		})
+	}
+	return hints
+}
+
+func gopFuncTypeParams(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	ce, ok := node.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	id, ok := ce.Fun.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	inst := info.Instances[id]
+	if inst.TypeArgs == nil {
+		return nil
+	}
+	start, err := m.PosPosition(tf, id.End())
+	if err != nil {
+		return nil
+	}
+	var args []string
+	for i := 0; i < inst.TypeArgs.Len(); i++ {
+		args = append(args, inst.TypeArgs.At(i).String())
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return []protocol.InlayHint{{
+		Position: start,
+		Label:    buildLabel("[" + strings.Join(args, ", ") + "]"),
+		Kind:     protocol.Type,
+	}}
+}
+
+func gopAssignVariableTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	stmt, ok := node.(*ast.AssignStmt)
+	if !ok || stmt.Tok != token.DEFINE {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for _, v := range stmt.Lhs {
+		if h := gopVariableType(v, m, tf, info, q); h != nil {
+			hints = append(hints, *h)
+		}
+	}
+	return hints
+}
+
+func gopRangeVariableTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	var key, value ast.Expr
+	switch n := node.(type) {
+	case *ast.RangeStmt:
+		key, value = n.Key, n.Value
+	case *ast.ForPhrase:
+		// for k, v <- X: used by ForPhraseStmt and list comprehensions.
+		if n.Key != nil {
+			key = n.Key
+		}
+		if n.Value != nil {
+			value = n.Value
+		}
+	default:
+		return nil
+	}
+	var hints []protocol.InlayHint
+	if h := gopVariableType(key, m, tf, info, q); h != nil {
+		hints = append(hints, *h)
+	}
+	if h := gopVariableType(value, m, tf, info, q); h != nil {
+		hints = append(hints, *h)
+	}
+	return hints
+}
+
+func gopLambdaParameterTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	var params []*ast.Ident
+	switch n := node.(type) {
+	case *ast.LambdaExpr:
+		params = n.Lhs
+	case *ast.LambdaExpr2:
+		params = n.Lhs
+	default:
+		return nil
+	}
+	var hints []protocol.InlayHint
+	for _, p := range params {
+		if h := gopVariableType(p, m, tf, info, q); h != nil {
+			hints = append(hints, *h)
+		}
+	}
+	return hints
+}
+
+func gopVariableType(e ast.Expr, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) *protocol.InlayHint {
+	if e == nil {
+		return nil
+	}
+	typ := info.TypeOf(e)
+	if typ == nil {
+		return nil
+	}
+	end, err := m.PosPosition(tf, e.End())
+	if err != nil {
+		return nil
+	}
+	return &protocol.InlayHint{
+		Position:    end,
+		Label:       buildLabel(types.TypeString(typ, *q)),
+		Kind:        protocol.Type,
+		PaddingLeft: true,
+	}
+}
+
+// gopOverloadedFunctions reports the member of an overload group that
+// the type checker selected for a call, e.g. Add__1 for `add "hello"`.
+func gopOverloadedFunctions(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	id, ok := node.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	if _, overloads := info.OverloadOf(id); overloads == nil {
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok {
+		return nil
+	}
+	return gopFuncNameHint(id, fn, m, tf, q)
+}
+
+// gopAliasNames reports the Go function or method behind a Go+ lowercase
+// alias, e.g. fmt.Println for `println` or Len for `s.len`.
+func gopAliasNames(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	id, ok := node.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Name() == id.Name {
+		return nil
+	}
+	if _, overloads := info.OverloadOf(id); overloads != nil {
+		return nil // reported by gopOverloadedFunctions
+	}
+	return gopFuncNameHint(id, fn, m, tf, q)
+}
+
+func gopFuncNameHint(id *ast.Ident, fn *types.Func, m *protocol.Mapper, tf *token.File, q *types.Qualifier) []protocol.InlayHint {
+	end, err := m.PosPosition(tf, id.End())
+	if err != nil {
+		return nil
+	}
+	name := fn.Name()
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() == nil && fn.Pkg() != nil {
+		if qual := (*q)(fn.Pkg()); qual != "" {
+			name = qual + "." + name
+		}
+	}
+	return []protocol.InlayHint{{
+		Position:    end,
+		Label:       buildLabel(name),
+		PaddingLeft: true,
+	}}
+}
+
+func gopConstantValues(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, _ *types.Qualifier) []protocol.InlayHint {
+	genDecl, ok := node.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.CONST {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	for _, v := range genDecl.Specs {
+		spec, ok := v.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		end, err := m.PosPosition(tf, v.End())
+		if err != nil {
+			continue
+		}
+		// Show hints when values are missing or at least one value is not
+		// a basic literal.
+		showHints := len(spec.Values) == 0
+		checkValues := len(spec.Names) == len(spec.Values)
+		var values []string
+		for i, w := range spec.Names {
+			obj, ok := info.ObjectOf(w).(*types.Const)
+			if !ok || obj.Val().Kind() == constant.Unknown {
+				return nil
+			}
+			if checkValues {
+				switch spec.Values[i].(type) {
+				case *ast.BadExpr:
+					return nil
+				case *ast.BasicLit:
+				default:
+					if obj.Val().Kind() != constant.Bool {
+						showHints = true
+					}
+				}
+			}
+			values = append(values, fmt.Sprintf("%v", obj.Val()))
+		}
+		if !showHints || len(values) == 0 {
+			continue
+		}
+		hints = append(hints, protocol.InlayHint{
+			Position:    end,
+			Label:       buildLabel("= " + strings.Join(values, ", ")),
+			PaddingLeft: true,
+		})
+	}
+	return hints
+}
+
+func gopCompositeLiteralFields(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	compLit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	typ := info.TypeOf(compLit)
+	if typ == nil {
+		return nil
+	}
+	if t, ok := typ.(*types.Pointer); ok {
+		typ = t.Elem()
+	}
+	strct, ok := typ.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+
+	var hints []protocol.InlayHint
+	var allEdits []protocol.TextEdit
+	for i, v := range compLit.Elts {
+		if _, ok := v.(*ast.KeyValueExpr); !ok {
+			start, err := m.PosPosition(tf, v.Pos())
+			if err != nil {
+				continue
+			}
+			if i > strct.NumFields()-1 {
+				break
+			}
+			hints = append(hints, protocol.InlayHint{
+				Position:     start,
+				Label:        buildLabel(strct.Field(i).Name() + ":"),
+				Kind:         protocol.Parameter,
+				PaddingRight: true,
+			})
+			allEdits = append(allEdits, protocol.TextEdit{
+				Range:   protocol.Range{Start: start, End: start},
+				NewText: strct.Field(i).Name() + ": ",
+			})
+		}
+	}
+	// It is not allowed to have a mix of keyed and unkeyed fields, so
+	// have the text edits add keys to all fields.
+	for i := range hints {
+		hints[i].TextEdits = allEdits
+	}
+	return hints
+}
+
+func gopCompositeLiteralTypes(node ast.Node, m *protocol.Mapper, tf *token.File, info *typesutil.Info, q *types.Qualifier) []protocol.InlayHint {
+	compLit, ok := node.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	typ := info.TypeOf(compLit)
+	if typ == nil {
+		return nil
+	}
+	if compLit.Type != nil {
+		return nil
+	}
+	prefix := ""
+	if t, ok := typ.(*types.Pointer); ok {
+		typ = t.Elem()
+		prefix = "&"
+	}
+	// The type for this composite literal is implicit, add an inlay hint.
+	start, err := m.PosPosition(tf, compLit.Lbrace)
+	if err != nil {
+		return nil
+	}
+	return []protocol.InlayHint{{
+		Position: start,
+		Label:    buildLabel(fmt.Sprintf("%s%s", prefix, types.TypeString(typ, *q))),
+		Kind:     protocol.Type,
+	}}
+}
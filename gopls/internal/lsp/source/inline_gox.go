@@ -0,0 +1,793 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package source
+
+// This file defines the refactor.inline code action for Go+ files.
+//
+// The inliner of internal/refactor/inline only understands Go syntax,
+// so calls within Go+ files are inlined by a more conservative textual
+// strategy: the callee must be a package-level function whose body is
+// a single return statement or a single call statement, and its
+// parameters are replaced by the argument expressions of the call.
+
+import (
+	"context"
+	"fmt"
+	goast "go/ast"
+	gotoken "go/token"
+	"go/types"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/gop/x/typesutil"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/gop/ast/astutil"
+	"golang.org/x/tools/gopls/internal/bug"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/safetoken"
+	"golang.org/x/tools/gopls/internal/span"
+	"golang.org/x/tools/internal/event"
+)
+
+// GopEnclosingStaticCall returns the innermost function call enclosing
+// the selected range of a Go+ file, along with the callee.
+// See EnclosingStaticCall.
+func GopEnclosingStaticCall(pkg Package, pgf *ParsedGopFile, rng protocol.Range) (*ast.CallExpr, *types.Func, error) {
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, start, end)
+
+	var call *ast.CallExpr
+loop:
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncLit, *ast.LambdaExpr, *ast.LambdaExpr2:
+			break loop
+		case *ast.CallExpr:
+			call = n
+			break loop
+		}
+	}
+	if call == nil {
+		return nil, nil, fmt.Errorf("no enclosing call")
+	}
+	// Command-style calls (e.g. `echo x`) have no parentheses.
+	lparen := call.Lparen
+	if !lparen.IsValid() {
+		lparen = call.Fun.End()
+	}
+	if safetoken.Line(pgf.Tok, lparen) != safetoken.Line(pgf.Tok, start) {
+		return nil, nil, fmt.Errorf("enclosing call is not on this line")
+	}
+	fn := gopStaticCallee(pkg.GopTypesInfo(), call)
+	if fn == nil {
+		return nil, nil, fmt.Errorf("not a static call to a Go or Go+ function")
+	}
+	return call, fn, nil
+}
+
+// gopStaticCallee returns the target (function) of a static call, if
+// any. For a call of an overloaded function it is the member selected
+// by the type checker. See typeutil.StaticCallee.
+func gopStaticCallee(info *typesutil.Info, call *ast.CallExpr) *types.Func {
+	var id *ast.Ident
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[fun]; ok && sel.Kind() != types.MethodVal {
+			return nil // method expression or field
+		}
+		id = fun.Sel
+	default:
+		return nil
+	}
+	fn, _ := info.Uses[id].(*types.Func)
+	if fn == nil {
+		return nil
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+		return nil // dynamic call
+	}
+	return fn
+}
+
+func gopInlineCall(ctx context.Context, snapshot Snapshot, fh FileHandle, rng protocol.Range) (_ *token.FileSet, _ *analysis.SuggestedFix, err error) {
+	// Find enclosing static call.
+	callerPkg, callerPGF, err := NarrowestPackageForGopFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, nil, err
+	}
+	call, fn, err := GopEnclosingStaticCall(callerPkg, callerPGF, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The inliner assumes that input is well-typed,
+	// but that is frequently not the case within gopls.
+	// Report panics as errors to avoid crashing the server.
+	defer func() {
+		if x := recover(); x != nil {
+			err = bug.Errorf("inlining failed unexpectedly: %v\nstack: %v",
+				x, debug.Stack())
+		}
+	}()
+
+	// Users can consult the gopls event log to see
+	// why a particular call could not be inlined.
+	logf := func(format string, args ...any) {
+		event.Log(ctx, "inliner: "+fmt.Sprintf(format, args...))
+	}
+
+	// Locate callee by file/line and analyze it.
+	callee, err := gopAnalyzeCallee(ctx, snapshot, callerPkg.FileSet(), fn)
+	if err != nil {
+		logf("cannot analyze %v: %v", fn, err)
+		return nil, nil, err
+	}
+
+	// Inline the call.
+	got, err := gopInline(callerPkg, callerPGF, call, callee)
+	if err != nil {
+		logf("cannot inline call of %v: %v", fn, err)
+		return nil, nil, err
+	}
+
+	// Suggest the fix.
+	return callerPkg.FileSet(), &analysis.SuggestedFix{
+		Message: fmt.Sprintf("inline call of %v", fn),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			NewText: []byte(got),
+		}},
+	}, nil
+}
+
+// A gopCallee summarizes a function whose calls can be inlined into
+// Go+ code. Its body consists of a single return statement with one
+// result, or of a single call statement.
+type gopCallee struct {
+	fn        *types.Func
+	stmt      bool            // body is a call statement rather than a return statement
+	body      string          // source of the returned or called expression
+	bodyType  types.Type      // type of the returned expression
+	isCall    bool            // body is a call expression
+	primary   bool            // body needs no parentheses as an operand
+	unordered bool            // body evaluates calls other than the outermost one
+	refs      []gopCalleeRef  // free references within body, in order
+	locals    map[string]bool // names declared within body, as lambda parameters
+}
+
+// A gopCalleeRef is a reference within the body of a callee to one of
+// its parameters, to a package-level or universe object, or to an
+// imported package.
+type gopCalleeRef struct {
+	start, end int          // offsets within body
+	param      int          // index of the referenced parameter, or -1
+	obj        types.Object // the referenced object, if not a parameter
+	builtin    bool         // obj is a Go+ builtin (such as println) used unqualified
+}
+
+// gopAnalyzeCallee locates the declaration of fn, which may be in a Go
+// or a Go+ file, and analyzes it.
+func gopAnalyzeCallee(ctx context.Context, snapshot Snapshot, fset *token.FileSet, fn *types.Func) (*gopCallee, error) {
+	if fn.Pkg() == nil {
+		return nil, fmt.Errorf("cannot inline built-in function %s", fn.Name())
+	}
+	sig := fn.Type().(*types.Signature)
+	switch {
+	case sig.Recv() != nil:
+		return nil, fmt.Errorf("cannot inline method %s", fn.Name())
+	case sig.TypeParams() != nil:
+		return nil, fmt.Errorf("cannot inline generic function %s", fn.Name())
+	case sig.Variadic():
+		return nil, fmt.Errorf("cannot inline variadic function %s", fn.Name())
+	}
+
+	calleePosn := safetoken.StartPosition(fset, fn.Pos())
+	if !calleePosn.IsValid() {
+		return nil, fmt.Errorf("can't find callee %s", fn.Name())
+	}
+	if strings.HasPrefix(filepath.Base(calleePosn.Filename), "gop_autogen") {
+		return nil, fmt.Errorf("callee %s is declared in generated code", fn.Name())
+	}
+	uri := span.URIFromPath(calleePosn.Filename)
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.View().FileKind(fh) == Gop {
+		calleePkg, calleePGF, err := NarrowestPackageForGopFile(ctx, snapshot, uri)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range calleePGF.File.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && !decl.Shadow {
+				posn := safetoken.StartPosition(calleePkg.FileSet(), decl.Name.Pos())
+				if posn.Line == calleePosn.Line && posn.Column == calleePosn.Column {
+					return gopAnalyzeGopCallee(calleePkg.GopTypesInfo(), calleePGF, fn, decl)
+				}
+			}
+		}
+	} else {
+		calleePkg, calleePGF, err := NarrowestPackageForFile(ctx, snapshot, uri)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range calleePGF.File.Decls {
+			if decl, ok := decl.(*goast.FuncDecl); ok {
+				posn := safetoken.StartPosition(calleePkg.FileSet(), decl.Name.Pos())
+				if posn.Line == calleePosn.Line && posn.Column == calleePosn.Column {
+					return gopAnalyzeGoCallee(calleePkg.GetTypesInfo(), calleePGF, fn, decl)
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("can't find callee %s", fn.Name())
+}
+
+// gopAnalyzeGopCallee analyzes a callee declared in a Go+ file.
+func gopAnalyzeGopCallee(info *typesutil.Info, pgf *ParsedGopFile, fn *types.Func, decl *ast.FuncDecl) (*gopCallee, error) {
+	if decl.Body == nil || len(decl.Body.List) != 1 {
+		return nil, fmt.Errorf("body of %s is not a single statement", fn.Name())
+	}
+	callee := &gopCallee{fn: fn}
+	var expr ast.Expr
+	switch stmt := decl.Body.List[0].(type) {
+	case *ast.ReturnStmt:
+		if len(stmt.Results) != 1 {
+			return nil, fmt.Errorf("%s does not return a single expression", fn.Name())
+		}
+		expr = stmt.Results[0]
+		callee.bodyType = info.TypeOf(expr)
+	case *ast.ExprStmt:
+		if _, ok := stmt.X.(*ast.CallExpr); !ok {
+			return nil, fmt.Errorf("body of %s is not a call statement", fn.Name())
+		}
+		expr, callee.stmt = stmt.X, true
+	default:
+		return nil, fmt.Errorf("body of %s is not a return or call statement", fn.Name())
+	}
+	start, end, err := safetoken.Offsets(pgf.Tok, expr.Pos(), expr.End())
+	if err != nil {
+		return nil, err
+	}
+	callee.body = string(pgf.Src[start:end])
+	_, callee.isCall = expr.(*ast.CallExpr)
+	callee.primary = gopIsPrimary(expr)
+
+	params := gopCalleeParams(fn)
+	// The objects declared within the body may have no position, as the
+	// variables of comprehensions, or be used before their declaration.
+	defs := gopDefs(info, expr)
+	for obj := range defs {
+		callee.addLocal(obj.Name())
+	}
+	selected := make(map[*ast.Ident]bool)
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			selected[n.Sel] = true // qualified identifier, field or method
+		case *ast.CallExpr:
+			if n != expr && !info.Types[n.Fun].IsType() {
+				callee.unordered = true
+			}
+		case *ast.UnaryExpr:
+			if n.Op == token.ARROW {
+				callee.unordered = true
+			}
+		case *ast.Ident:
+			if selected[n] {
+				break
+			}
+			obj := info.Uses[n]
+			if obj == nil || defs[obj] {
+				break
+			}
+			ref := gopCalleeRef{param: -1}
+			ref.start, ref.end = int(n.Pos()-expr.Pos()), int(n.End()-expr.Pos())
+			ref.param, ref.obj, ref.builtin, err = gopClassifyRef(fn, params, obj, expr.Pos(), expr.End(), true)
+			if ref.param >= 0 || ref.obj != nil {
+				callee.refs = append(callee.refs, ref)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return callee, nil
+}
+
+// gopAnalyzeGoCallee analyzes a callee declared in a Go file.
+func gopAnalyzeGoCallee(info *types.Info, pgf *ParsedGoFile, fn *types.Func, decl *goast.FuncDecl) (*gopCallee, error) {
+	if decl.Body == nil || len(decl.Body.List) != 1 {
+		return nil, fmt.Errorf("body of %s is not a single statement", fn.Name())
+	}
+	callee := &gopCallee{fn: fn}
+	var expr goast.Expr
+	switch stmt := decl.Body.List[0].(type) {
+	case *goast.ReturnStmt:
+		if len(stmt.Results) != 1 {
+			return nil, fmt.Errorf("%s does not return a single expression", fn.Name())
+		}
+		expr = stmt.Results[0]
+		callee.bodyType = info.TypeOf(expr)
+	case *goast.ExprStmt:
+		if _, ok := stmt.X.(*goast.CallExpr); !ok {
+			return nil, fmt.Errorf("body of %s is not a call statement", fn.Name())
+		}
+		expr, callee.stmt = stmt.X, true
+	default:
+		return nil, fmt.Errorf("body of %s is not a return or call statement", fn.Name())
+	}
+	start, end, err := safetoken.Offsets(pgf.Tok, expr.Pos(), expr.End())
+	if err != nil {
+		return nil, err
+	}
+	callee.body = string(pgf.Src[start:end])
+	_, callee.isCall = expr.(*goast.CallExpr)
+	switch expr.(type) {
+	case *goast.BinaryExpr, *goast.UnaryExpr, *goast.StarExpr, *goast.KeyValueExpr:
+	default:
+		callee.primary = true
+	}
+
+	params := gopCalleeParams(fn)
+	selected := make(map[*goast.Ident]bool)
+	goast.Inspect(expr, func(n goast.Node) bool {
+		if err != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *goast.SelectorExpr:
+			selected[n.Sel] = true // qualified identifier, field or method
+		case *goast.CallExpr:
+			if n != expr && !info.Types[n.Fun].IsType() {
+				callee.unordered = true
+			}
+		case *goast.UnaryExpr:
+			if n.Op == gotoken.ARROW {
+				callee.unordered = true
+			}
+		case *goast.Ident:
+			if selected[n] {
+				break
+			}
+			if info.Defs[n] != nil {
+				callee.addLocal(n.Name)
+				break
+			}
+			obj := info.Uses[n]
+			if obj == nil {
+				break
+			}
+			ref := gopCalleeRef{param: -1}
+			ref.start, ref.end = int(n.Pos()-expr.Pos()), int(n.End()-expr.Pos())
+			ref.param, ref.obj, ref.builtin, err = gopClassifyRef(fn, params, obj, expr.Pos(), expr.End(), false)
+			if ref.param >= 0 || ref.obj != nil {
+				callee.refs = append(callee.refs, ref)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return callee, nil
+}
+
+// addLocal records a name declared within the body of the callee, which
+// would capture the same name in an argument.
+func (p *gopCallee) addLocal(name string) {
+	if p.locals == nil {
+		p.locals = make(map[string]bool)
+	}
+	p.locals[name] = true
+}
+
+// gopCalleeParams maps the parameters of fn to their indices.
+func gopCalleeParams(fn *types.Func) map[types.Object]int {
+	params := fn.Type().(*types.Signature).Params()
+	ret := make(map[types.Object]int, params.Len())
+	for i := 0; i < params.Len(); i++ {
+		ret[params.At(i)] = i
+	}
+	return ret
+}
+
+// gopClassifyRef classifies a use of obj within the body [start, end)
+// of fn. It returns the index of the parameter obj denotes, or the
+// object itself if it must be resolved again at the call site; neither
+// is returned for objects local to the body, fields and methods.
+func gopClassifyRef(fn *types.Func, params map[types.Object]int, obj types.Object, start, end token.Pos, gop bool) (param int, ref types.Object, builtin bool, err error) {
+	if i, ok := params[obj]; ok {
+		return i, nil, false, nil
+	}
+	switch {
+	case gopIsPkgName(obj), obj.Parent() == types.Universe:
+		return -1, obj, false, nil
+	case obj.Pkg() == nil:
+		return -1, nil, false, nil
+	case obj.Parent() == obj.Pkg().Scope():
+		if obj.Pkg() != fn.Pkg() {
+			if !gop {
+				return -1, nil, false, fmt.Errorf("unexpected reference to %s", obj.Name())
+			}
+			return -1, obj, true, nil // e.g. println
+		}
+		return -1, obj, false, nil
+	case start <= obj.Pos() && obj.Pos() < end:
+		return -1, nil, false, nil // declared within the body
+	}
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		return -1, nil, false, nil
+	}
+	if _, ok := obj.(*types.Func); ok {
+		return -1, nil, false, nil // method
+	}
+	return -1, nil, false, fmt.Errorf("body of %s refers to local %s", fn.Name(), obj.Name())
+}
+
+// gopDefs returns the objects declared within node.
+func gopDefs(info *typesutil.Info, node ast.Node) map[types.Object]bool {
+	defs := make(map[types.Object]bool)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.Defs[id] != nil {
+			defs[info.Defs[id]] = true
+		}
+		return true
+	})
+	return defs
+}
+
+// gopCapturedName returns the first name that arg refers to, outside of
+// it, which is in locals, or "" if there is none.
+func gopCapturedName(info *typesutil.Info, arg ast.Expr, locals map[string]bool) (name string) {
+	if len(locals) == 0 {
+		return ""
+	}
+	defs := gopDefs(info, arg)
+	selected := make(map[*ast.Ident]bool)
+	ast.Inspect(arg, func(n ast.Node) bool {
+		if name != "" {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			selected[n.Sel] = true
+		case *ast.Ident:
+			obj := info.Uses[n]
+			if selected[n] || obj == nil || defs[obj] {
+				break
+			}
+			if locals[n.Name] {
+				name = n.Name
+			}
+		}
+		return true
+	})
+	return
+}
+
+func gopIsPkgName(obj types.Object) bool {
+	_, ok := obj.(*types.PkgName)
+	return ok
+}
+
+// gopInline returns the text that replaces call, which calls callee.
+func gopInline(callerPkg Package, pgf *ParsedGopFile, call *ast.CallExpr, callee *gopCallee) (string, error) {
+	info := callerPkg.GopTypesInfo()
+	sig := callee.fn.Type().(*types.Signature)
+	if call.Ellipsis.IsValid() {
+		return "", fmt.Errorf("call has ... argument")
+	}
+	if len(call.Args) != sig.Params().Len() {
+		return "", fmt.Errorf("call has %d arguments, %s has %d parameters", len(call.Args), callee.fn.Name(), sig.Params().Len())
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, call.Pos(), call.End())
+	if len(path) < 2 || path[0] != call {
+		return "", fmt.Errorf("can't find call")
+	}
+	parent := path[1]
+	_, isStmt := parent.(*ast.ExprStmt)
+	if callee.stmt && !isStmt {
+		return "", fmt.Errorf("call of %s is not a statement", callee.fn.Name())
+	}
+	if isStmt && !callee.isCall {
+		return "", fmt.Errorf("result of %s would be discarded", callee.fn.Name())
+	}
+
+	// Innermost scope of the call, used to check that names
+	// referenced by the callee denote the same objects.
+	scope := callerPkg.GetTypes().Scope()
+	for _, n := range path {
+		// The scope of a function body is recorded for its type.
+		switch f := n.(type) {
+		case *ast.FuncDecl:
+			n = f.Type
+		case *ast.FuncLit:
+			n = f.Type
+		}
+		if s := info.Scopes[n]; s != nil {
+			scope = s
+			break
+		}
+	}
+	lookup := func(name string) types.Object {
+		_, obj := scope.LookupParent(name, call.Pos())
+		return obj
+	}
+
+	qualify, err := gopCallerQualifier(callerPkg, pgf, lookup)
+	if err != nil {
+		return "", err
+	}
+
+	// Prepare the arguments.
+	type argument struct {
+		text      string
+		primary   bool
+		effects   bool // may have effects, so must be evaluated exactly once
+		uses      int
+		firstUse  int
+		converted bool
+	}
+	args := make([]*argument, len(call.Args))
+	for i, arg := range call.Args {
+		switch arg.(type) {
+		case *ast.LambdaExpr, *ast.LambdaExpr2:
+			return "", fmt.Errorf("cannot inline call with lambda argument")
+		}
+		start, end, err := safetoken.Offsets(pgf.Tok, arg.Pos(), arg.End())
+		if err != nil {
+			return "", err
+		}
+		a := &argument{
+			text:     string(pgf.Src[start:end]),
+			primary:  gopIsPrimary(arg),
+			effects:  !gopIsDuplicable(arg),
+			firstUse: -1,
+		}
+		args[i] = a
+	}
+	for i, ref := range callee.refs {
+		if ref.param >= 0 {
+			a := args[ref.param]
+			if a.uses == 0 {
+				a.firstUse = i
+			}
+			a.uses++
+		}
+	}
+	for i, a := range args {
+		if a.uses == 0 {
+			continue
+		}
+		// The argument is substituted within the scopes of the body: a
+		// name it refers to must not be declared there too, as by the
+		// parameters of a lambda or the variables of a comprehension.
+		if name := gopCapturedName(info, call.Args[i], callee.locals); name != "" {
+			return "", fmt.Errorf("%s in argument for parameter %s would refer to %s declared in the body of %s",
+				name, sig.Params().At(i).Name(), name, callee.fn.Name())
+		}
+		text, ok, err := gopConvert(a.text, info.TypeOf(call.Args[i]), sig.Params().At(i).Type(), qualify)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			a.text, a.converted = text, true
+		}
+	}
+	lastUse := -1
+	for i, a := range args {
+		if !a.effects {
+			continue
+		}
+		name := sig.Params().At(i).Name()
+		switch {
+		case a.uses == 0:
+			return "", fmt.Errorf("argument for unused parameter %s may have effects", name)
+		case a.uses > 1:
+			return "", fmt.Errorf("argument for parameter %s may have effects and would be evaluated %d times", name, a.uses)
+		case callee.unordered, a.firstUse < lastUse:
+			return "", fmt.Errorf("inlining would change the order of evaluation of argument for parameter %s", name)
+		}
+		lastUse = a.firstUse
+	}
+
+	// Substitute the references of the body.
+	var buf strings.Builder
+	last := 0
+	for _, ref := range callee.refs {
+		buf.WriteString(callee.body[last:ref.start])
+		last = ref.end
+		if ref.param >= 0 {
+			a := args[ref.param]
+			if !a.primary && !a.converted && len(callee.body) != ref.end-ref.start {
+				buf.WriteString("(" + a.text + ")")
+			} else {
+				buf.WriteString(a.text)
+			}
+			continue
+		}
+		obj := ref.obj
+		switch {
+		case gopIsPkgName(obj):
+			name, err := qualify(obj.(*types.PkgName).Imported())
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(name)
+		case ref.builtin || obj.Parent() == types.Universe:
+			// Go+ builtins may be spelled differently from their
+			// objects (println for fmt.Println).
+			name := callee.body[ref.start:ref.end]
+			if found := lookup(name); found != nil && found != obj && found.Parent() != types.Universe {
+				return "", fmt.Errorf("%s is shadowed at the call site", name)
+			}
+			buf.WriteString(name)
+		case obj.Pkg().Path() == callerPkg.GetTypes().Path():
+			if found := lookup(obj.Name()); found == nil || found.Parent() != callerPkg.GetTypes().Scope() {
+				return "", fmt.Errorf("%s is shadowed at the call site", obj.Name())
+			}
+			buf.WriteString(obj.Name())
+		default:
+			if !obj.Exported() {
+				return "", fmt.Errorf("%s refers to unexported %s.%s", callee.fn.Name(), obj.Pkg().Name(), obj.Name())
+			}
+			name, err := qualify(obj.Pkg())
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(name + "." + obj.Name())
+		}
+	}
+	buf.WriteString(callee.body[last:])
+	text := buf.String()
+	if callee.stmt {
+		return text, nil
+	}
+
+	// Preserve the type of the result.
+	if sig.Results().Len() != 1 {
+		return "", fmt.Errorf("%s does not return a single result", callee.fn.Name())
+	}
+	converted, ok, err := gopConvert(text, callee.bodyType, sig.Results().At(0).Type(), qualify)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return converted, nil
+	}
+	if !callee.primary && gopNeedsParens(parent, call) {
+		text = "(" + text + ")"
+	}
+	return text, nil
+}
+
+// gopCallerQualifier returns a function that reports the name by which
+// the Go+ file pgf refers to a package.
+func gopCallerQualifier(pkg Package, pgf *ParsedGopFile, lookup func(string) types.Object) (func(*types.Package) (string, error), error) {
+	names := make(map[string]string) // import path -> local name
+	for _, imp := range pgf.File.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		if imp.Name != nil {
+			names[path] = imp.Name.Name
+		}
+	}
+	return func(p *types.Package) (string, error) {
+		if p == nil || p.Path() == pkg.GetTypes().Path() {
+			return "", nil
+		}
+		name, ok := names[p.Path()]
+		if !ok {
+			found := false
+			for _, imp := range pgf.File.Imports {
+				if path, _ := strconv.Unquote(imp.Path.Value); path == p.Path() {
+					found = true
+				}
+			}
+			if !found {
+				return "", fmt.Errorf("%q is not imported by %s", p.Path(), filepath.Base(pgf.URI.Filename()))
+			}
+			name = p.Name()
+		}
+		if name == "_" || name == "." {
+			return "", fmt.Errorf("%q is not imported by name", p.Path())
+		}
+		if obj := lookup(name); obj != nil && !gopIsPkgName(obj) {
+			return "", fmt.Errorf("import %s is shadowed at the call site", name)
+		}
+		return name, nil
+	}, nil
+}
+
+// gopConvert returns the conversion of the expression text of type from
+// to type to, and whether a conversion is needed to preserve the type
+// of the expression.
+func gopConvert(text string, from, to types.Type, qualify func(*types.Package) (string, error)) (string, bool, error) {
+	if from == nil || to == nil || types.Identical(from, to) {
+		return text, false, nil
+	}
+	if basic, ok := from.(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 && types.Identical(types.Default(from), to) {
+		return text, false, nil
+	}
+	var qerr error
+	typ := types.TypeString(to, func(p *types.Package) string {
+		name, err := qualify(p)
+		if err != nil && qerr == nil {
+			qerr = err
+		}
+		return name
+	})
+	if qerr != nil {
+		return "", false, qerr
+	}
+	switch to.(type) {
+	case *types.Pointer, *types.Signature, *types.Chan:
+		typ = "(" + typ + ")"
+	}
+	return typ + "(" + text + ")", true, nil
+}
+
+// gopIsPrimary reports whether e needs no parentheses as an operand.
+func gopIsPrimary(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.StarExpr, *ast.KeyValueExpr,
+		*ast.LambdaExpr, *ast.LambdaExpr2, *ast.ErrWrapExpr, *ast.RangeExpr:
+		return false
+	case *ast.CallExpr:
+		return e.Lparen.IsValid() // command-style calls have no parentheses
+	}
+	return true
+}
+
+// gopIsDuplicable reports whether e may be evaluated any number of
+// times, in any order, without changing the meaning of the program.
+func gopIsDuplicable(e ast.Expr) bool {
+	switch e := e.(type) {
+	case *ast.Ident:
+		return true
+	case *ast.BasicLit:
+		return e.Extra == nil // string interpolation evaluates expressions
+	case *ast.ParenExpr:
+		return gopIsDuplicable(e.X)
+	case *ast.SelectorExpr:
+		return gopIsDuplicable(e.X)
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.ADD, token.SUB, token.NOT, token.XOR:
+			return gopIsDuplicable(e.X)
+		}
+	}
+	return false
+}
+
+// gopNeedsParens reports whether an operand replacing call within its
+// parent must be parenthesized.
+func gopNeedsParens(parent ast.Node, call *ast.CallExpr) bool {
+	switch parent := parent.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.StarExpr, *ast.SelectorExpr,
+		*ast.IndexExpr, *ast.IndexListExpr, *ast.SliceExpr, *ast.TypeAssertExpr, *ast.ErrWrapExpr:
+		return true
+	case *ast.CallExpr:
+		return parent.Fun == call
+	}
+	return false
+}
@@ -16,6 +16,11 @@ import (
 	"time"
 
 	goxanalysis "golang.org/x/tools/gop/analysis"
+	gopsimplifycompositelit "golang.org/x/tools/gop/analysis/passes/simplifycompositelit"
+	gopsimplifylambda "golang.org/x/tools/gop/analysis/passes/simplifylambda"
+	gopsimplifyrange "golang.org/x/tools/gop/analysis/passes/simplifyrange"
+	gopsimplifyslice "golang.org/x/tools/gop/analysis/passes/simplifyslice"
+	gopunusedwrite "golang.org/x/tools/gop/analysis/passes/unusedwrite"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/passes/asmdecl"
@@ -113,14 +118,16 @@ func DefaultOptions() *Options {
 						protocol.QuickFix:              true,
 						protocol.RefactorRewrite:       true,
 						protocol.RefactorExtract:       true,
+						protocol.RefactorInline:        true,
 					},
 					Mod: {
 						protocol.SourceOrganizeImports: true,
 						protocol.QuickFix:              true,
 					},
-					Work: {},
-					Sum:  {},
-					Tmpl: {},
+					Work:   {},
+					Sum:    {},
+					Tmpl:   {},
+					GopMod: {}, // goxls: gop.mod
 				},
 				SupportedCommands: commands,
 			},
@@ -947,6 +954,9 @@ func (o *Options) enableAllExperimentMaps() {
 	if _, ok := o.Analyses[unusedparams.Analyzer.Name]; !ok {
 		o.Analyses[unusedparams.Analyzer.Name] = true
 	}
+	if _, ok := o.Analyses[unusedparams.GopAnalyzer.Name]; !ok { // goxls: use Go+ Analyzer
+		o.Analyses[unusedparams.GopAnalyzer.Name] = true
+	}
 	if _, ok := o.Analyses[unusedvariable.Analyzer.Name]; !ok {
 		o.Analyses[unusedvariable.Analyzer.Name] = true
 	}
@@ -1509,19 +1519,36 @@ func typeErrorAnalyzers() map[string]*Analyzer {
 			Analyzer: nonewvars.Analyzer,
 			Enabled:  true,
 		},
+		nonewvars.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: nonewvars.GopAnalyzer,
+			Enabled:  true,
+		},
 		noresultvalues.Analyzer.Name: {
 			Analyzer: noresultvalues.Analyzer,
 			Enabled:  true,
 		},
+		noresultvalues.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: noresultvalues.GopAnalyzer,
+			Enabled:  true,
+		},
 		undeclaredname.Analyzer.Name: {
 			Analyzer: undeclaredname.Analyzer,
 			Fix:      UndeclaredName,
 			Enabled:  true,
 		},
+		undeclaredname.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: undeclaredname.GopAnalyzer,
+			Fix:      UndeclaredName,
+			Enabled:  true,
+		},
 		unusedvariable.Analyzer.Name: {
 			Analyzer: unusedvariable.Analyzer,
 			Enabled:  false,
 		},
+		unusedvariable.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: unusedvariable.GopAnalyzer,
+			Enabled:  false,
+		},
 	}
 }
 
@@ -1540,6 +1567,11 @@ func convenienceAnalyzers() map[string]*Analyzer {
 			Fix:      StubMethods,
 			Enabled:  true,
 		},
+		stubmethods.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: stubmethods.GopAnalyzer,
+			Fix:      StubMethods,
+			Enabled:  true,
+		},
 		infertypeargs.Analyzer.Name: {
 			Analyzer:   infertypeargs.Analyzer,
 			Enabled:    true,
@@ -1596,6 +1628,20 @@ func defaultAnalyzers() map[string]*Analyzer {
 			Fix:             AddEmbedImport,
 			fixesDiagnostic: fixedByImportingEmbed,
 		},
+		deprecated.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: deprecated.GopAnalyzer,
+			Enabled:  true,
+			Severity: protocol.SeverityHint,
+			Tag:      []protocol.DiagnosticTag{protocol.Deprecated},
+		},
+		unusedparams.GopAnalyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: unusedparams.GopAnalyzer,
+			Enabled:  false,
+		},
+		gopunusedwrite.Analyzer.Name: { // goxls: use Go+ Analyzer
+			Analyzer: gopunusedwrite.Analyzer,
+			Enabled:  false,
+		},
 
 		// gofmt -s suite:
 		simplifycompositelit.Analyzer.Name: {
@@ -1613,6 +1659,28 @@ func defaultAnalyzers() map[string]*Analyzer {
 			Enabled:    true,
 			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
 		},
+
+		// goxls: Go+ simplifications
+		gopsimplifycompositelit.Analyzer.Name: {
+			Analyzer:   gopsimplifycompositelit.Analyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
+		},
+		gopsimplifyrange.Analyzer.Name: {
+			Analyzer:   gopsimplifyrange.Analyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
+		},
+		gopsimplifyslice.Analyzer.Name: {
+			Analyzer:   gopsimplifyslice.Analyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.SourceFixAll, protocol.QuickFix},
+		},
+		gopsimplifylambda.Analyzer.Name: {
+			Analyzer:   gopsimplifylambda.Analyzer,
+			Enabled:    true,
+			ActionKind: []protocol.CodeActionKind{protocol.QuickFix},
+		},
 	}
 }
 
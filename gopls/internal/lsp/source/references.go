@@ -80,6 +80,7 @@ func references(ctx context.Context, snapshot Snapshot, f FileHandle, pp protoco
 	if err != nil {
 		return nil, err
 	}
+	gopAutogenReferences(ctx, snapshot, refs) // goxls: Go+ sources
 
 	sort.Slice(refs, func(i, j int) bool {
 		x, y := refs[i], refs[j]
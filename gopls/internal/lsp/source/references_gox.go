@@ -65,6 +65,7 @@ func gopReferences(ctx context.Context, snapshot Snapshot, f FileHandle, pp prot
 	if err != nil {
 		return nil, err
 	}
+	gopAutogenReferences(ctx, snapshot, refs)
 
 	sort.Slice(refs, func(i, j int) bool {
 		x, y := refs[i], refs[j]
@@ -53,5 +53,5 @@ func TypeDefinition(ctx context.Context, snapshot Snapshot, fh FileHandle, posit
 	if err != nil {
 		return nil, err
 	}
-	return []protocol.Location{loc}, nil
+	return GopAutogenLocations(ctx, snapshot, []protocol.Location{loc}), nil // goxls: Go+ sources
 }
@@ -52,5 +52,5 @@ func GopTypeDefinition(ctx context.Context, snapshot Snapshot, fh FileHandle, po
 	if err != nil {
 		return nil, err
 	}
-	return []protocol.Location{loc}, nil
+	return GopAutogenLocations(ctx, snapshot, []protocol.Location{loc}), nil
 }
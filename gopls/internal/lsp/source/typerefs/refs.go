@@ -25,7 +25,7 @@ import (
 // It returns a serializable index of this information.
 // Use Decode to expand the result.
 func Encode(files []*source.ParsedGoFile, id source.PackageID, imports map[source.ImportPath]*source.Metadata) []byte {
-	return index(files, id, imports)
+	return index(files, nil, id, imports) // goxls: Go+ files
 }
 
 // Decode decodes a serializable index of symbol
@@ -161,20 +161,25 @@ func classKey(set symbolSet) string {
 	return s.String()
 }
 
+// goxls: add Go+ files
 // index builds the reference graph and encodes the index.
-func index(pgfs []*source.ParsedGoFile, id source.PackageID, imports map[source.ImportPath]*source.Metadata) []byte {
+func index(pgfs []*source.ParsedGoFile, gfs []*gopFile, id source.PackageID, imports map[source.ImportPath]*source.Metadata) []byte {
 	// First pass: gather package-level names and create a declNode for each.
 	//
 	// In ill-typed code, there may be multiple declarations of the
 	// same name; a single declInfo node will represent them all.
 	decls := make(map[string]*declNode)
-	addDecl := func(id *ast.Ident) {
-		if name := id.Name; name != "_" && decls[name] == nil {
+	// goxls: add Go+ names
+	addName := func(name string) {
+		if name != "_" && decls[name] == nil {
 			node := &declNode{name: name, extRefsClass: -1}
 			node.rep = node
 			decls[name] = node
 		}
 	}
+	addDecl := func(id *ast.Ident) {
+		addName(id.Name)
+	}
 	for _, pgf := range pgfs {
 		for _, d := range pgf.File.Decls {
 			switch d := d.(type) {
@@ -202,11 +207,21 @@ func index(pgfs []*source.ParsedGoFile, id source.PackageID, imports map[source.
 		}
 	}
 
+	// goxls: Go+ files
+	gopAddDecls(gfs, addName)
+	if len(gfs) > 0 {
+		gopAddOverloads(decls, addName)
+	}
+
 	// Second pass: process files to collect referring identifiers.
 	st := &state{classIndex: make(map[string]int)}
 	for _, pgf := range pgfs {
 		visitFile(pgf.File, imports, decls)
 	}
+	// goxls: Go+ files
+	for _, gf := range gfs {
+		gopVisitFile(gf, imports, decls)
+	}
 
 	// Find the strong components of the declNode graph
 	// using Tarjan's algorithm, and coalesce each component.
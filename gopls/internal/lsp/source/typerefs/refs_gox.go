@@ -0,0 +1,638 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typerefs
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/goplus/gop/ast"
+	"github.com/goplus/gop/token"
+	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+)
+
+// EncodeGop is like Encode, but it also analyzes the Go+ syntax trees
+// of the package. The module mod, which may be nil, describes the
+// classfiles known to the package.
+func EncodeGop(files []*source.ParsedGoFile, gopFiles []*source.ParsedGopFile, mod *gopmod.Module, id source.PackageID, imports map[source.ImportPath]*source.Metadata) []byte {
+	var gfs []*gopFile
+	if len(gopFiles) > 0 {
+		gfs = newGopFiles(gopFiles, mod)
+	}
+	return index(files, gfs, id, imports)
+}
+
+// A gopFile holds a Go+ syntax tree along with the classfile
+// information that the syntax alone does not provide.
+type gopFile struct {
+	file *ast.File
+
+	classType string           // name of the implicit class type; or ""
+	fields    *ast.GenDecl     // fields of the class type; or nil
+	proj      *modfile.Project // classfile project; or nil
+	ext       string           // classfile extension
+	isProj    bool             // is a project classfile
+	projTypes map[string]bool  // class types of the project classfiles of the package
+}
+
+func newGopFiles(pgfs []*source.ParsedGopFile, mod *gopmod.Module) []*gopFile {
+	gfs := make([]*gopFile, 0, len(pgfs))
+	projTypes := make(map[string]bool)
+	for _, pgf := range pgfs {
+		f := pgf.File
+		gf := &gopFile{file: f, projTypes: projTypes}
+		filename := pgf.URI.Filename()
+		if classType, ok := parserutil.GetClassType(f, filename); ok {
+			if !f.IsNormalGox && mod != nil {
+				ext := modfile.ClassExt(filename)
+				if proj, ok := mod.LookupClass(ext); ok {
+					gf.proj, gf.ext, gf.isProj = proj, ext, f.IsProj
+					if f.IsProj && classType == "main" {
+						classType = proj.Class
+					}
+				}
+			}
+			if f.IsNormalGox && classType == "main" {
+				classType = "_main"
+			}
+			gf.classType = classType
+			for _, d := range f.Decls {
+				if d, ok := d.(*ast.GenDecl); ok && d.Tok == token.VAR {
+					gf.fields = d
+					break
+				}
+			}
+			if gf.isProj {
+				projTypes[classType] = true
+			}
+		}
+		gfs = append(gfs, gf)
+	}
+	return gfs
+}
+
+// isMethod reports whether d is a method, possibly of the implicit
+// class type of the file.
+func (gf *gopFile) isMethod(d *ast.FuncDecl) bool {
+	return d.Recv.NumFields() > 0 || d.IsClass || gf.classType != ""
+}
+
+// recvName returns the identifier of the receiver type of method d,
+// or nil if it is invalid.
+func (gf *gopFile) recvName(d *ast.FuncDecl) *ast.Ident {
+	if d.Recv.NumFields() == 0 || d.IsClass {
+		// The class type is implicit: the type checker may have set
+		// the receiver of the declaration, but it does not appear in
+		// the source.
+		if gf.classType == "" {
+			return nil
+		}
+		return &ast.Ident{NamePos: d.Pos(), Name: gf.classType}
+	}
+	return gopUnpackRecv(d.Recv.List[0].Type)
+}
+
+// gopAddDecls creates a declNode for each package-level name declared
+// by the Go+ files.
+func gopAddDecls(gfs []*gopFile, addDecl func(name string)) {
+	for _, gf := range gfs {
+		if gf.classType != "" {
+			addDecl(gf.classType)
+		}
+		for _, d := range gf.file.Decls {
+			switch d := d.(type) {
+			case *ast.GenDecl:
+				switch d.Tok {
+				case token.TYPE:
+					for _, spec := range d.Specs {
+						addDecl(spec.(*ast.TypeSpec).Name.Name)
+					}
+
+				case token.VAR, token.CONST:
+					if d == gf.fields {
+						continue // fields of the class type
+					}
+					for _, spec := range d.Specs {
+						for _, ident := range spec.(*ast.ValueSpec).Names {
+							addDecl(ident.Name)
+						}
+					}
+				}
+
+			case *ast.FuncDecl:
+				if d.Static {
+					if recv := gf.recvName(d); recv != nil {
+						addDecl(gopStaticMethod(recv.Name, d.Name.Name))
+					}
+				} else if !gf.isMethod(d) {
+					addDecl(d.Name.Name)
+				}
+
+			case *ast.OverloadFuncDecl:
+				if d.Recv.NumFields() == 0 && !d.IsClass {
+					addDecl(d.Name.Name)
+					for i, fn := range d.Funcs {
+						if _, ok := fn.(*ast.FuncLit); ok {
+							addDecl(gopOverloadFuncName(d.Name.Name, i))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// gopAddOverloads records, for each function f__N of an overload set
+// that is declared directly (in the Go style), an edge from its
+// overload name f, by which Go+ code refers to it.
+func gopAddOverloads(decls map[string]*declNode, addDecl func(name string)) {
+	for name := range decls {
+		if base, ok := gopOverloadBase(name); ok {
+			addDecl(base)
+		}
+	}
+	for name, decl := range decls {
+		if base, ok := gopOverloadBase(name); ok {
+			from := decls[base]
+			if from.intRefs == nil {
+				from.intRefs = make(map[*declNode]bool)
+			}
+			from.intRefs[decl] = true
+		}
+	}
+}
+
+// gopVisitFile inspects the Go+ file syntax for referring identifiers,
+// and populates the internal and external references of decls.
+func gopVisitFile(gf *gopFile, imports map[source.ImportPath]*source.Metadata, decls map[string]*declNode) {
+	fileImports := make(map[string][]source.PackageID)
+
+	// importEdge records a reference from decl to an imported symbol
+	// (pkgname.name). The package name may be ".".
+	//
+	// Go+ code may refer to an exported Go symbol by its name with a
+	// lowercase initial (as in strings.toUpper), so both forms are
+	// considered.
+	importEdge := func(decl *declNode, pkgname, name string) {
+		if !token.IsExported(name) {
+			name = gopExportedName(name)
+			if name == "" {
+				return
+			}
+		}
+		for _, depID := range fileImports[pkgname] {
+			if decl.extRefs == nil {
+				decl.extRefs = make(symbolSet)
+			}
+			decl.extRefs[symbol{depID, name}] = true
+		}
+	}
+
+	// intEdge records a reference from decl to a package-level
+	// declaration, if any.
+	intEdge := func(from *declNode, name string) bool {
+		to, ok := decls[name]
+		if ok {
+			if from.intRefs == nil {
+				from.intRefs = make(map[*declNode]bool)
+			}
+			from.intRefs[to] = true
+		}
+		return ok
+	}
+
+	// visit finds refs within node and builds edges from the decl
+	// named fromName.
+	visit := func(fromName string, node ast.Node, tparams map[string]bool) {
+		if fromName == "_" {
+			return
+		}
+		from := decls[fromName]
+		if from == nil {
+			return
+		}
+		gopVisitDeclOrSpec(node, func(name, sel string) {
+			if tparams[name] {
+				return
+			}
+			if !intEdge(from, name) {
+				importEdge(from, ".", name)
+			}
+			if sel != "" {
+				importEdge(from, name, sel)
+			}
+		})
+	}
+
+	// The packages of a classfile framework are implicitly imported,
+	// and their members may be used without qualification.
+	if proj := gf.proj; proj != nil {
+		for _, imp := range proj.Import {
+			if dep := imports[source.ImportPath(imp.Path)]; dep != nil {
+				name := imp.Name
+				if name == "" {
+					name = string(dep.Name)
+				}
+				fileImports[name] = append(fileImports[name], dep.ID)
+			}
+		}
+		for _, pkgPath := range proj.PkgPaths {
+			if dep := imports[source.ImportPath(pkgPath)]; dep != nil {
+				fileImports["."] = append(fileImports["."], dep.ID)
+				fileImports[string(dep.Name)] = append(fileImports[string(dep.Name)], dep.ID)
+			}
+		}
+	}
+
+	for _, spec := range gf.file.Imports {
+		path := source.GopUnquoteImportPath(spec)
+		if path == "" {
+			continue
+		}
+		dep := imports[path]
+		if dep == nil {
+			continue
+		}
+		name := string(dep.Name)
+		if spec.Name != nil {
+			if spec.Name.Name == "_" {
+				continue
+			}
+			name = spec.Name.Name // possibly "."
+		}
+		fileImports[name] = append(fileImports[name], dep.ID)
+	}
+
+	// The implicit class type embeds the base class of its
+	// framework, and a work class embeds the project class types.
+	if classType := gf.classType; classType != "" {
+		if from := decls[classType]; from != nil {
+			if proj := gf.proj; proj != nil && len(proj.PkgPaths) > 0 {
+				if dep := imports[source.ImportPath(proj.PkgPaths[0])]; dep != nil {
+					base := proj.Class
+					if !gf.isProj {
+						base = gf.workClass()
+					}
+					if base != "" {
+						if from.extRefs == nil {
+							from.extRefs = make(symbolSet)
+						}
+						from.extRefs[symbol{dep.ID, strings.TrimPrefix(base, "*")}] = true
+					}
+				}
+				if !gf.isProj {
+					for name := range gf.projTypes {
+						intEdge(from, name)
+					}
+				}
+			}
+			if gf.fields != nil {
+				for _, spec := range gf.fields.Specs {
+					visit(classType, spec, nil)
+				}
+			}
+		}
+	}
+
+	for _, d := range gf.file.Decls {
+		switch d := d.(type) {
+		case *ast.GenDecl:
+			switch d.Tok {
+			case token.TYPE:
+				for _, spec := range d.Specs {
+					spec := spec.(*ast.TypeSpec)
+					visit(spec.Name.Name, spec, gopTparamsMap(spec.TypeParams))
+				}
+
+			case token.VAR, token.CONST:
+				if d == gf.fields {
+					continue
+				}
+				for _, spec := range d.Specs {
+					spec := spec.(*ast.ValueSpec)
+					for _, name := range spec.Names {
+						visit(name.Name, spec, nil)
+					}
+				}
+			}
+
+		case *ast.FuncDecl:
+			if gf.isMethod(d) {
+				// Method. Associate it with the receiver, and static
+				// methods with the function that implements them.
+				id := gf.recvName(d)
+				if id == nil {
+					continue
+				}
+				if d.Static {
+					visit(gopStaticMethod(id.Name, d.Name.Name), d, nil)
+				}
+				visit(id.Name, d, nil)
+			} else {
+				visit(d.Name.Name, d, gopTparamsMap(d.Type.TypeParams))
+			}
+
+		case *ast.OverloadFuncDecl:
+			from := d.Name.Name
+			isMethod := d.Recv.NumFields() > 0 || d.IsClass
+			if isMethod {
+				// Overloaded method. Associate it with the receiver.
+				from = gf.classType
+				if d.Recv.NumFields() > 0 {
+					if id := gopUnpackRecv(d.Recv.List[0].Type); id != nil {
+						from = id.Name
+					}
+				}
+			}
+			for i, fn := range d.Funcs {
+				visit(from, fn, nil)
+				if _, ok := fn.(*ast.FuncLit); ok && !isMethod {
+					visit(gopOverloadFuncName(from, i), fn, nil)
+				}
+			}
+		}
+	}
+}
+
+// workClass returns the base class of the work classfile gf, or "".
+func (gf *gopFile) workClass() string {
+	for _, w := range gf.proj.Works {
+		if w.Ext == gf.ext {
+			return w.Class
+		}
+	}
+	return ""
+}
+
+// gopTparamsMap is the Go+ analogue of tparamsMap.
+func gopTparamsMap(tparams *ast.FieldList) map[string]bool {
+	if tparams == nil || len(tparams.List) == 0 {
+		return nil
+	}
+	m := make(map[string]bool)
+	for _, f := range tparams.List {
+		for _, name := range f.Names {
+			if name.Name != "_" {
+				m[name.Name] = true
+			}
+		}
+	}
+	return m
+}
+
+// gopUnpackRecv returns the identifier of the receiver type expression
+// rtyp, or nil if it is invalid.
+func gopUnpackRecv(rtyp ast.Expr) *ast.Ident {
+	for {
+		switch t := rtyp.(type) {
+		case *ast.ParenExpr:
+			rtyp = t.X
+		case *ast.StarExpr:
+			rtyp = t.X
+		case *ast.IndexExpr:
+			rtyp = t.X
+		case *ast.IndexListExpr:
+			rtyp = t.X
+		case *ast.Ident:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+// gopVisitDeclOrSpec is the Go+ analogue of visitDeclOrSpec.
+func gopVisitDeclOrSpec(node ast.Node, f refVisitor) {
+	switch n := node.(type) {
+	case *ast.ValueSpec:
+		if n.Type != nil {
+			gopVisitExpr(n.Type, f)
+		} else {
+			gopVisitExprList(n.Values, f)
+		}
+
+	case *ast.TypeSpec:
+		if n.TypeParams != nil {
+			gopVisitFieldList(n.TypeParams, f)
+		}
+		gopVisitExpr(n.Type, f)
+
+	case *ast.FuncDecl:
+		// Skip Body, which does not affect the type.
+		gopVisitExpr(n.Type, f)
+
+	case *ast.BadDecl:
+		// nothing to do
+
+	case ast.Expr:
+		// A member of an overload set.
+		gopVisitExpr(n, f)
+
+	default:
+		panic(fmt.Sprintf("unexpected node type %T", node))
+	}
+}
+
+// gopVisitExpr is the Go+ analogue of visitExpr.
+func gopVisitExpr(expr ast.Expr, f refVisitor) {
+	switch n := expr.(type) {
+	case *ast.Ident:
+		f(n.Name, "")
+
+	case *ast.BasicLit:
+		// The parts of an interpolated string do not affect its type.
+
+	case *ast.SelectorExpr:
+		if ident, ok := n.X.(*ast.Ident); ok {
+			f(ident.Name, n.Sel.Name)
+		} else {
+			gopVisitExpr(n.X, f)
+		}
+
+	case *ast.CallExpr:
+		gopVisitExpr(n.Fun, f)
+		gopVisitExprList(n.Args, f)
+
+	case *ast.Ellipsis:
+		if n.Elt != nil {
+			gopVisitExpr(n.Elt, f)
+		}
+
+	case *ast.FuncLit:
+		gopVisitExpr(n.Type, f)
+
+	case *ast.CompositeLit:
+		if n.Type != nil {
+			gopVisitExpr(n.Type, f)
+		} else {
+			// The type of a Go+ map literal {k: v} is that of
+			// its elements.
+			gopVisitExprList(n.Elts, f)
+		}
+
+	case *ast.KeyValueExpr:
+		gopVisitExpr(n.Key, f)
+		gopVisitExpr(n.Value, f)
+
+	case *ast.ParenExpr:
+		gopVisitExpr(n.X, f)
+
+	case *ast.IndexExpr:
+		gopVisitExpr(n.X, f)
+		gopVisitExpr(n.Index, f)
+
+	case *ast.IndexListExpr:
+		gopVisitExpr(n.X, f)
+		gopVisitExprList(n.Indices, f)
+
+	case *ast.SliceExpr:
+		gopVisitExpr(n.X, f)
+
+	case *ast.TypeAssertExpr:
+		if n.Type != nil {
+			gopVisitExpr(n.Type, f)
+		}
+
+	case *ast.StarExpr:
+		gopVisitExpr(n.X, f)
+
+	case *ast.UnaryExpr:
+		gopVisitExpr(n.X, f)
+
+	case *ast.BinaryExpr:
+		gopVisitExpr(n.X, f)
+		gopVisitExpr(n.Y, f)
+
+	case *ast.ArrayType:
+		if n.Len != nil {
+			gopVisitExpr(n.Len, f)
+		}
+		gopVisitExpr(n.Elt, f)
+
+	case *ast.StructType:
+		gopVisitFieldList(n.Fields, f)
+
+	case *ast.FuncType:
+		if n.TypeParams != nil {
+			gopVisitFieldList(n.TypeParams, f)
+		}
+		if n.Params != nil {
+			gopVisitFieldList(n.Params, f)
+		}
+		if n.Results != nil {
+			gopVisitFieldList(n.Results, f)
+		}
+
+	case *ast.InterfaceType:
+		gopVisitFieldList(n.Methods, f)
+
+	case *ast.MapType:
+		gopVisitExpr(n.Key, f)
+		gopVisitExpr(n.Value, f)
+
+	case *ast.ChanType:
+		gopVisitExpr(n.Value, f)
+
+	// Go+ expressions
+	case *ast.SliceLit:
+		// The type of a slice literal is that of its elements.
+		gopVisitExprList(n.Elts, f)
+
+	case *ast.MatrixLit:
+		for _, row := range n.Elts {
+			gopVisitExprList(row, f)
+		}
+
+	case *ast.ElemEllipsis:
+		gopVisitExpr(n.Elt, f)
+
+	case *ast.ErrWrapExpr:
+		gopVisitExpr(n.X, f)
+		if n.Default != nil {
+			gopVisitExpr(n.Default, f)
+		}
+
+	case *ast.ComprehensionExpr:
+		// The element type may depend on the iteration variables,
+		// and thus on the ranged values.
+		if n.Elt != nil {
+			gopVisitExpr(n.Elt, f)
+		}
+		for _, fp := range n.Fors {
+			gopVisitExpr(fp.X, f)
+		}
+
+	case *ast.RangeExpr:
+		if n.First != nil {
+			gopVisitExpr(n.First, f)
+		}
+		if n.Last != nil {
+			gopVisitExpr(n.Last, f)
+		}
+
+	case *ast.EnvExpr, *ast.LambdaExpr, *ast.LambdaExpr2, *ast.BadExpr:
+		// nothing to do: the type of a lambda is that of its context.
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+}
+
+func gopVisitExprList(list []ast.Expr, f refVisitor) {
+	for _, x := range list {
+		gopVisitExpr(x, f)
+	}
+}
+
+func gopVisitFieldList(n *ast.FieldList, f refVisitor) {
+	for _, field := range n.List {
+		gopVisitExpr(field.Type, f)
+	}
+}
+
+// gopExportedName returns the exported Go name that the Go+ name
+// refers to by its lowercase initial, or "" if there is none.
+func gopExportedName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	if !unicode.IsLower(r) {
+		return ""
+	}
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+const gopIndexTable = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// gopOverloadFuncName returns the name of the function that implements
+// the i'th member of the overload set name.
+func gopOverloadFuncName(name string, i int) string {
+	if i >= len(gopIndexTable) {
+		return name + "__?"
+	}
+	return name + "__" + gopIndexTable[i:i+1]
+}
+
+// gopOverloadBase returns the overload name f of the function f__N.
+func gopOverloadBase(name string) (string, bool) {
+	i := strings.LastIndex(name, "__")
+	if i <= 0 || len(name) != i+3 || !strings.Contains(gopIndexTable, name[i+2:]) {
+		return "", false
+	}
+	return name[:i], true
+}
+
+// gopStaticMethod returns the name of the function that implements the
+// static method name of the type tname.
+func gopStaticMethod(tname, name string) string {
+	sep := "_"
+	if strings.ContainsRune(name, '_') || strings.ContainsRune(tname, '_') {
+		sep = "__"
+	}
+	return "Gops" + sep + tname + sep + name
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package typerefs_test
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	goptoken "github.com/goplus/gop/token"
+	"github.com/goplus/mod/gopmod"
+	"golang.org/x/tools/gopls/internal/goxls/parserutil"
+	"golang.org/x/tools/gopls/internal/lsp/cache"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	"golang.org/x/tools/gopls/internal/lsp/source/typerefs"
+	"golang.org/x/tools/gopls/internal/span"
+)
+
+// TestGopOverloadRefs checks that the references to the overload name
+// of a function declared in the Go style (as Add__0 and Add__1) reach
+// the dependencies of its members, both within the declaring package
+// and from a package that imports it.
+func TestGopOverloadRefs(t *testing.T) {
+	ctx := context.Background()
+	index := typerefs.NewPackageIndex()
+
+	// encode returns the references of each declaration of package id.
+	encode := func(id string, goSrc, gopSrc string, imports map[source.ImportPath]*source.Metadata) map[string][]string {
+		var pgfs []*source.ParsedGoFile
+		if goSrc != "" {
+			uri := span.URI(fmt.Sprintf("file:///%s/a.go", id))
+			pgf, _ := cache.ParseGoSrc(ctx, token.NewFileSet(), uri, []byte(goSrc), source.ParseFull, false)
+			if pgf.ParseErr != nil {
+				t.Fatalf("ParseGoSrc(...) returned parse errors: %v", pgf.ParseErr)
+			}
+			pgfs = append(pgfs, pgf)
+		}
+		uri := span.URI(fmt.Sprintf("file:///%s/b.gop", id))
+		gpf, _ := cache.ParseGopSrc(ctx, gopmod.Default, goptoken.NewFileSet(), uri, []byte(gopSrc), parserutil.ParseFull, false)
+		if gpf.ParseErr != nil {
+			t.Fatalf("ParseGopSrc(...) returned parse errors: %v", gpf.ParseErr)
+		}
+		data := typerefs.EncodeGop(pgfs, []*source.ParsedGopFile{gpf}, gopmod.Default, source.PackageID(id), imports)
+
+		got := make(map[string][]string)
+		for _, class := range typerefs.Decode(index, source.PackageID(id), data) {
+			for _, name := range class.Decls {
+				var syms []string
+				for _, sym := range class.Refs {
+					syms = append(syms, fmt.Sprintf("%s.%s", index.DeclaringPackage(sym), sym.Name))
+				}
+				sort.Strings(syms)
+				got[name] = syms
+			}
+		}
+		return got
+	}
+
+	// Package q declares the members of the overload set Add in Go and
+	// uses it in Go+.
+	got := encode("q", `package q
+
+import "ext"
+
+func Add__0(a ext.A) ext.A { return a }
+func Add__1(b ext.B) ext.B { return b }
+`, `package q
+
+var V = Add(nil)
+`, map[source.ImportPath]*source.Metadata{
+		"ext": {ID: "ext", Name: "ext"},
+	})
+	want := map[string][]string{
+		"Add":    {"ext.A", "ext.B"},
+		"Add__0": {"ext.A"},
+		"Add__1": {"ext.B"},
+		"V":      {"ext.A", "ext.B"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Refs(q) returned unexpected refs (-want +got):\n%s", diff)
+	}
+
+	// Package p refers to the overload set of q by its Go+ name.
+	got = encode("p", "", `package p
+
+import "q"
+
+var W = q.add(nil)
+`, map[source.ImportPath]*source.Metadata{
+		"q": {ID: "q", Name: "q"},
+	})
+	want = map[string][]string{
+		"W": {"q.Add"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Refs(p) returned unexpected refs (-want +got):\n%s", diff)
+	}
+}
@@ -103,6 +103,8 @@ func FileKindForLang(langID string) FileKind {
 		return Work
 	case "gop": // goxls: Support Go+
 		return Gop
+	case "gop.mod": // goxls: Support gop.mod
+		return GopMod
 	default:
 		return UnknownKind
 	}
@@ -262,6 +262,9 @@ type Snapshot interface {
 	// GopModForFile returns gop module for gop file by uri.
 	// It returns an error if the context was cancelled.
 	GopModForFile(ctx context.Context, uri span.URI) (*gopmod.Module, error)
+
+	// ParseGopMod is used to parse gop.mod files.
+	ParseGopMod(ctx context.Context, fh FileHandle) (*ParsedGopModule, error)
 }
 
 // NarrowestMetadataForFile returns metadata for the narrowest package
@@ -867,6 +870,8 @@ const (
 	Work
 	// goxls: Gop is a Go+ file.
 	Gop
+	// goxls: GopMod is a gop.mod file.
+	GopMod
 )
 
 func (k FileKind) String() string {
@@ -883,6 +888,8 @@ func (k FileKind) String() string {
 		return "go.work"
 	case Gop: // goxls: Gop is a Go+ file
 		return "gop"
+	case GopMod: // goxls: GopMod is a gop.mod file
+		return "gop.mod"
 	default:
 		return fmt.Sprintf("internal error: unknown file kind %d", k)
 	}
@@ -1069,6 +1076,7 @@ const (
 	TemplateError            DiagnosticSource = "template"
 	WorkFileError            DiagnosticSource = "go.work file"
 	ConsistencyInfo          DiagnosticSource = "consistency"
+	GopCommandError          DiagnosticSource = "gop command" // goxls: Go+
 )
 
 func AnalyzerErrorKind(name string) DiagnosticSource {
@@ -17,6 +17,7 @@ import (
 	"github.com/goplus/gop/token"
 	"github.com/goplus/gop/x/gopenv"
 	"github.com/goplus/mod/gopmod"
+	"github.com/goplus/mod/modfile"
 	"golang.org/x/tools/gop/packages"
 	"golang.org/x/tools/gopls/internal/lsp/protocol"
 	"golang.org/x/tools/gopls/internal/lsp/safetoken"
@@ -124,11 +125,25 @@ func (pgf *ParsedGopFile) PosRange(start, end token.Pos) (protocol.Range, error)
 	return pgf.Mapper.PosRange(pgf.Tok, start, end)
 }
 
+// PosMappedRange returns a MappedRange for the token.Pos interval in this file.
+// A MappedRange can be converted to any other form.
+func (pgf *ParsedGopFile) PosMappedRange(start, end token.Pos) (protocol.MappedRange, error) {
+	return pgf.Mapper.PosMappedRange(pgf.Tok, start, end)
+}
+
 // PosLocation returns a protocol Location for the token.Pos interval in this file.
 func (pgf *ParsedGopFile) PosLocation(start, end token.Pos) (protocol.Location, error) {
 	return pgf.Mapper.PosLocation(pgf.Tok, start, end)
 }
 
+// A ParsedGopModule contains the results of parsing a gop.mod file.
+type ParsedGopModule struct {
+	URI         span.URI
+	File        *modfile.File
+	Mapper      *protocol.Mapper
+	ParseErrors []*Diagnostic
+}
+
 func (m *Metadata) LoadGopMod() {
 	m.gopMod_, _ = gop.LoadMod(m.LoadDir)
 }
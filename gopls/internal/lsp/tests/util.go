@@ -475,4 +475,7 @@ func EnableAllInlayHints(opts *source.Options) {
 	for name := range source.AllInlayHints {
 		opts.Hints[name] = true
 	}
+	for name := range source.AllGopInlayHints { // goxls: Go+
+		opts.Hints[name] = true
+	}
 }
@@ -0,0 +1,56 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"os/exec"
+	"testing"
+
+	"golang.org/x/tools/gop/langserver"
+	"golang.org/x/tools/gopls/internal/lsp/command"
+	"golang.org/x/tools/gopls/internal/lsp/protocol"
+	"golang.org/x/tools/gopls/internal/lsp/source"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+func TestRunGopCommand(t *testing.T) {
+	if _, err := exec.LookPath(langserver.LookupCmd("gop")); err != nil {
+		t.Skip("gop is not installed")
+	}
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- main.gop --
+println "hello"
+-- bad/bad.gop --
+println undefinedName
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		runGop := func(dir, verb string) {
+			cmd, err := command.NewRunGopCommandCommand("", command.RunGopCommandArgs{
+				URI:     env.Sandbox.Workdir.URI(dir),
+				Command: verb,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			env.ExecuteCommand(&protocol.ExecuteCommandParams{
+				Command:   cmd.Command,
+				Arguments: cmd.Arguments,
+			}, nil)
+		}
+		runGop(".", "run")
+		env.Await(ShownMessage("gop run completed"))
+
+		// The gop command reports files relative to the module root.
+		runGop("bad", "build")
+		env.Await(
+			ShownMessage("gop build failed"),
+			Diagnostics(env.AtRegexp("bad/bad.gop", "undefinedName"), FromSource(string(source.GopCommandError))),
+		)
+	})
+}
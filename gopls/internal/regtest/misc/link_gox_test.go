@@ -0,0 +1,82 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+func TestGopDocumentLink(t *testing.T) {
+	// The Go code of the Go+ files is generated by the gop command, which
+	// doesn't use the module proxy of the test: the imported packages and
+	// the classfile framework are in the module itself.
+	const program = `
+-- go.mod --
+module mod.test
+
+go 1.18
+-- gop.mod --
+gop 1.2
+
+project .gmx Game mod.test/game fmt
+
+class .spx Sprite
+
+import "strings"
+-- pkg/const.go --
+package pkg
+
+const Hello = "Hello"
+-- game/game.go --
+package game
+
+type Game struct{}
+
+func (p *Game) Main() {}
+
+type Sprite struct{}
+-- demo/main.gop --
+import "mod.test/pkg"
+
+// See https://goplus.org/docs.
+println pkg.Hello, "https://github.com/goplus/gop"
+-- mygame/main.gmx --
+println "https://goplus.org/game"
+-- mygame/Kai.spx --
+import "mod.test/pkg"
+
+// Kai says hello, see https://goplus.org/spx.
+func onMsg() {
+	println pkg.Hello
+}
+`
+	Run(t, program, func(t *testing.T, env *Env) {
+		pkgLink := "https://pkg.go.dev/mod.test/pkg"
+		for _, test := range []struct {
+			file string
+			want []string
+		}{
+			{"demo/main.gop", []string{pkgLink, "https://goplus.org/docs", "https://github.com/goplus/gop"}},
+			{"mygame/main.gmx", []string{"https://goplus.org/game"}},
+			{"mygame/Kai.spx", []string{pkgLink, "https://goplus.org/spx"}},
+			{"gop.mod", []string{"https://pkg.go.dev/mod.test/game", "https://pkg.go.dev/fmt", "https://pkg.go.dev/strings"}},
+		} {
+			env.OpenFile(test.file)
+			var got []string
+			for _, link := range env.DocumentLink(test.file) {
+				got = append(got, *link.Target)
+			}
+			sort.Strings(got)
+			sort.Strings(test.want)
+			if diff := cmp.Diff(test.want, got); diff != "" {
+				t.Errorf("documentLink: unexpected links for %s (-want +got):\n%s", test.file, diff)
+			}
+		}
+	})
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 The GoPlus Authors (goplus.org). All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package modfile
+
+import (
+	"strings"
+	"testing"
+
+	. "golang.org/x/tools/gopls/internal/lsp/regtest"
+)
+
+func TestGopModParseErrors(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- gop.mod --
+gop 1.2
+
+project .gmx Game fmt
+
+class .spx
+
+bogus directive
+-- main.gop --
+println "hello"
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("gop.mod")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("gop.mod", "class .spx"), WithMessage("usage: class")),
+			Diagnostics(env.AtRegexp("gop.mod", "bogus"), WithMessage("unknown directive")),
+		)
+
+		// The valid directives of a gop.mod file with errors still work.
+		content, _ := env.Hover(env.RegexpSearch("gop.mod", "Game"))
+		if content == nil || !strings.Contains(content.Value, "Framework: fmt") {
+			t.Errorf("hover over project directive: got %v, want framework fmt", content)
+		}
+
+		// Fixing the file clears its diagnostics.
+		env.RegexpReplace("gop.mod", "class .spx\n", "class .spx Sprite\n")
+		env.RegexpReplace("gop.mod", "bogus directive\n", "")
+		env.AfterChange(
+			NoDiagnostics(ForFile("gop.mod")),
+		)
+	})
+}
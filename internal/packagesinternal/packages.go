@@ -11,6 +11,7 @@ import (
 
 var GetForTest = func(p interface{}) string { return "" }
 var GetDepsErrors = func(p interface{}) []*PackageError { return nil }
+var GetDir = func(p interface{}) string { return "" } // goxls: package directory
 
 type PackageError struct {
 	ImportStack []string // shortest path from package named on command line to this one